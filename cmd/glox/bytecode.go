@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/compiler"
+	"github.com/matt-hoiland/glox/internal/constants/exit"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/vm"
+)
+
+// disassemble prints the bytecode of a script, compiling it first unless it's already compiled.
+func disassemble(w io.Writer, filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("could not read file '%s': %w", filename, err)
+	}
+
+	var fn *bytecode.Function
+	if bytecode.IsCompiled(data) {
+		fn, err = decode(filename, data)
+	} else {
+		fn, err = compileSource(filename, data)
+	}
+	if err != nil {
+		return err
+	}
+	bytecode.Disassemble(w, fn)
+	return nil
+}
+
+// compileFile compiles a script for the virtual machine.
+func compileFile(filename string) (*bytecode.Function, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file '%s': %w", filename, err)
+	}
+	if bytecode.IsCompiled(data) {
+		return nil, fmt.Errorf("'%s' is already compiled", filename)
+	}
+	return compileSource(filename, data)
+}
+
+// compileSource compiles the source of a script, which was read from the named file.
+func compileSource(filename string, data []byte) (*bytecode.Function, error) {
+	tokens, scanErr := scanner.New(string(data)).ScanTokens()
+	stmts, parseErr := parser.New(tokens).Parse()
+	if err := errors.Join(scanErr, parseErr); err != nil {
+		return nil, ierrors.WithSource(err, filename, string(data))
+	}
+
+	fn, err := compiler.Compile(stmts)
+	if err != nil {
+		return nil, ierrors.WithSource(err, filename, string(data))
+	}
+	return fn, nil
+}
+
+// decode decodes a compiled script, which was read from the named file.
+func decode(filename string, data []byte) (*bytecode.Function, error) {
+	f, err := bytecode.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not load '%s': %w", filename, err)
+	}
+	return f.Script, nil
+}
+
+// compile implements "glox compile script [-o output]", writing the compiled script next to it by default.
+func compile(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ExitOnError)
+	output := flags.String("o", "", "write the compiled script to this file instead of the script's name with .loxc")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stdout, usage)
+	}
+
+	// Accept the output flag on either side of the script.
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(exit.Usage)
+	}
+	filename := flags.Arg(0)
+	_ = flags.Parse(flags.Args()[1:])
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(exit.Usage)
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".loxc"
+	}
+
+	fn, err := compileFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = bytecode.Encode(&buf, &bytecode.File{Source: filepath.Base(filename), Script: fn}); err != nil {
+		return fmt.Errorf("could not encode '%s': %w", filename, err)
+	}
+	if err = os.WriteFile(*output, buf.Bytes(), 0o644); err != nil { //nolint:gosec // Compiled scripts aren't secret.
+		return fmt.Errorf("could not write file '%s': %w", *output, err)
+	}
+	return nil
+}
+
+// runCompiled runs a compiled script without its source, so errors are reported without quoting it.
+func runCompiled(machine *vm.VM, filename string, data []byte) error {
+	f, err := bytecode.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("could not load '%s': %w", filename, err)
+	}
+	if err = machine.Interpret(f.Script); err != nil {
+		return ierrors.WithSource(err, f.Source, "")
+	}
+	return nil
+}
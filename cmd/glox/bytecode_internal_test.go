@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisassemble(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.lox")
+	compiled := filepath.Join(dir, "script.loxc")
+	require.NoError(t, os.WriteFile(script, []byte("print 1 + 2;\n"), 0o600))
+	require.NoError(t, compile([]string{script, "-o", compiled}))
+
+	expected := "== <script> ==\n" +
+		"0000    1 OpConstant          0 '1'\n" +
+		"0003    | OpConstant          1 '2'\n" +
+		"0006    | OpAdd\n" +
+		"0007    | OpPrint\n" +
+		"0008    | OpNil\n" +
+		"0009    | OpReturn\n"
+
+	for _, filename := range []string{script, compiled} {
+		var bob strings.Builder
+		require.NoError(t, disassemble(&bob, filename))
+		assert.Equal(t, expected, bob.String(), filename)
+	}
+}
+
+func TestCompile_compiled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.lox")
+	compiled := filepath.Join(dir, "script.loxc")
+	require.NoError(t, os.WriteFile(script, []byte("print 1;\n"), 0o600))
+	require.NoError(t, compile([]string{script}))
+
+	err := compile([]string{compiled, "-o", filepath.Join(dir, "again.loxc")})
+	require.ErrorContains(t, err, "is already compiled")
+}
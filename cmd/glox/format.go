@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/matt-hoiland/glox/internal/constants/exit"
+	"github.com/matt-hoiland/glox/internal/diff"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/format"
+)
+
+// formatScripts implements "glox fmt [-w] [-d] [script ...]", formatting standard input when given no scripts.
+// Every script is formatted even when some fail to parse, so that all their errors are reported at once.
+func formatScripts(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write the formatted script back to its file instead of to standard output")
+	showDiff := flags.Bool("d", false, "print the changes formatting would make instead of the formatted script")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stdout, usage)
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		if *write {
+			flags.Usage()
+			os.Exit(exit.Usage)
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("could not read standard input: %w", err)
+		}
+		return formatScript("<standard input>", string(data), *showDiff, nil)
+	}
+
+	var errs []error
+	for _, filename := range flags.Args() {
+		errs = append(errs, formatFile(filename, *write, *showDiff))
+	}
+	return errors.Join(errs...)
+}
+
+func formatFile(filename string, write, showDiff bool) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("could not read file '%s': %w", filename, err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("could not read file '%s': %w", filename, err)
+	}
+
+	var save func(string) error
+	if write {
+		save = func(formatted string) error {
+			if err := os.WriteFile(filename, []byte(formatted), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("could not write file '%s': %w", filename, err)
+			}
+			return nil
+		}
+	}
+	return formatScript(filename, string(data), showDiff, save)
+}
+
+// formatScript formats a script, then prints the changes to it if showDiff is set, and saves it if it changed
+// and there's somewhere to save it. It prints the formatted script when doing neither.
+func formatScript(filename, source string, showDiff bool, save func(string) error) error {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return ierrors.WithSource(err, filename, source)
+	}
+	if showDiff {
+		fmt.Fprint(os.Stdout, diff.Unified(filename+".orig", filename, source, formatted))
+	}
+	if save != nil {
+		if formatted == source {
+			return nil
+		}
+		return save(formatted)
+	}
+	if !showDiff {
+		fmt.Fprint(os.Stdout, formatted)
+	}
+	return nil
+}
@@ -1,40 +1,136 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 
-	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/bytecode"
 	"github.com/matt-hoiland/glox/internal/constants/exit"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/interpreter"
-	"github.com/matt-hoiland/glox/internal/loxtype"
-	"github.com/matt-hoiland/glox/internal/parser"
-	"github.com/matt-hoiland/glox/internal/scanner"
-	"github.com/matt-hoiland/glox/internal/token"
+	"github.com/matt-hoiland/glox/internal/lineedit"
+	"github.com/matt-hoiland/glox/internal/lsp"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/repl"
+	"github.com/matt-hoiland/glox/internal/vm"
 )
 
+// runner runs whole scripts on one of the backends.
+type runner interface {
+	Run(code string) error
+}
+
+// historyFile is the name of the file in the user's home directory that keeps the REPL's history.
+const historyFile = ".glox_history"
+
+const usage = `Usage: glox [-backend tree|vm] [-trace-execution] [-path dirs] [script]
+       glox compile script [-o output]
+       glox disasm script
+       glox fmt [-w] [-d] [script ...]
+       glox lsp
+
+Compiled scripts always run on the virtual machine.
+The fmt command prints scripts, or standard input, in the canonical style; -w rewrites the scripts in place
+and -d prints the changes it would make as a diff.
+The lsp command serves the Language Server Protocol over the standard streams, for editors.`
+
 func main() {
-	args := os.Args[1:]
+	backend := flag.String("backend", "tree", "run scripts on the tree-walking interpreter (tree) "+
+		"or the bytecode virtual machine (vm); the REPL always uses the interpreter")
+	trace := flag.Bool("trace-execution", false, "print the stack and each instruction as the virtual machine "+
+		"runs it; implies -backend vm")
+	path := flag.String("path", "", "directories to search for imported modules, separated by '"+
+		string(filepath.ListSeparator)+"'")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stdout, usage)
+	}
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "compile":
+			exitOnError(compile(args[1:]))
+			return
+		case "disasm":
+			if len(args) != 2 {
+				flag.Usage()
+				os.Exit(exit.Usage)
+			}
+			exitOnError(disassemble(os.Stdout, args[1]))
+			return
+		case "fmt":
+			exitOnError(formatScripts(args[1:]))
+			return
+		case "lsp":
+			if len(args) != 1 {
+				flag.Usage()
+				os.Exit(exit.Usage)
+			}
+			exitOnError(lsp.NewServer(os.Stdin, os.Stdout).Serve())
+			return
+		}
+	}
+
+	// Scripts run from the command line are trusted with everything the user running them can do.
+	opts := []interpreter.Option{
+		interpreter.WithCapabilities(native.AllCapabilities),
+		interpreter.WithSearchPath(filepath.SplitList(*path)...),
+	}
+	vmOpts := []vm.Option{
+		vm.WithCapabilities(native.AllCapabilities),
+		vm.WithSearchPath(filepath.SplitList(*path)...),
+	}
+	if len(args) == 1 {
+		opts = append(opts, interpreter.WithScriptPath(args[0]))
+		vmOpts = append(vmOpts, vm.WithScriptPath(args[0]))
+	}
+	var r runner
+	switch {
+	case *trace:
+		r = vm.New(os.Stdout, append(vmOpts, vm.WithTraceExecution(os.Stderr))...)
+	case *backend == "tree":
+		r = interpreter.New(os.Stdout, opts...)
+	case *backend == "vm":
+		r = vm.New(os.Stdout, vmOpts...)
+	default:
+		flag.Usage()
+		os.Exit(exit.Usage)
+	}
 
-	var err error
 	switch {
 	case len(args) > 1:
-		fmt.Fprintln(os.Stdout, "Usage: glox [script]")
+		flag.Usage()
 		os.Exit(exit.Usage)
 	case len(args) == 1:
-		err = runFile(args[0])
+		exitOnError(runFile(r, args[0], vmOpts...))
 	default:
-		runPrompt()
+		exitOnError(runPrompt(opts...))
 	}
+}
+
+// exitOnError exits with the code a program exited with, or reports any other error.
+func exitOnError(err error) {
+	exitOnExit(err)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
 		os.Exit(exit.DataErr)
 	}
 }
 
-func runFile(filename string) error {
+// exitOnExit exits with the code a program exited with, if it did.
+func exitOnExit(err error) {
+	if exitErr := (*ierrors.ExitError)(nil); errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+}
+
+// runFile runs a script, or a compiled program on a virtual machine configured with vmOpts if r isn't one.
+func runFile(r runner, filename string, vmOpts ...vm.Option) error {
 	var (
 		data []byte
 		err  error
@@ -42,57 +138,25 @@ func runFile(filename string) error {
 	if data, err = os.ReadFile(filename); err != nil {
 		return fmt.Errorf("could not read file '%s': %w", filename, err)
 	}
-	return interpreter.New(os.Stdout).Run(string(data))
-}
-
-func runPrompt() {
-	var (
-		w          io.Writer = os.Stdout
-		i                    = interpreter.New(w)
-		reader               = bufio.NewScanner(os.Stdin)
-		lineNumber           = 0
-		err        error
-	)
-	for {
-		lineNumber++
-		fmt.Fprintf(os.Stdin, "#%3d > ", lineNumber)
-		if !reader.Scan() {
-			break
-		}
-		line := reader.Text()
-
-		var (
-			tokens []*token.Token
-			s      = scanner.New(line, scanner.WithStartingLine(lineNumber))
-		)
-		if tokens, err = s.ScanTokens(); err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			continue
-		}
-
-		var (
-			stmts []ast.Stmt
-			p     = parser.New(tokens, parser.InREPLMode())
-		)
-		if stmts, err = p.Parse(); err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			continue
-		}
-
-		if len(stmts) == 1 {
-			if exprStmt, ok := stmts[0].(*ast.ExpressionStmt); ok {
-				var value loxtype.Type
-				if value, err = i.Evaluate(exprStmt.Expression); err != nil {
-					fmt.Fprintln(os.Stderr, err.Error())
-					continue
-				}
-				fmt.Fprintln(os.Stdout, value.String())
-				continue
-			}
+	if bytecode.IsCompiled(data) {
+		machine, ok := r.(*vm.VM)
+		if !ok {
+			machine = vm.New(os.Stdout, vmOpts...)
 		}
+		return runCompiled(machine, filename, data)
+	}
+	if err = r.Run(string(data)); err != nil {
+		return ierrors.WithSource(err, filename, string(data))
+	}
+	return nil
+}
 
-		if err = i.Interpret(stmts); err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-		}
+// runPrompt runs the REPL on the interpreter, keeping the history of what's typed in the user's home directory.
+func runPrompt(opts ...interpreter.Option) error {
+	var editorOpts []lineedit.Option
+	if home, err := os.UserHomeDir(); err == nil {
+		editorOpts = append(editorOpts, lineedit.WithHistoryFile(filepath.Join(home, historyFile)))
 	}
+	r := repl.New(lineedit.New(os.Stdin, os.Stdout, editorOpts...), os.Stdout, os.Stderr, opts...)
+	return r.Run(context.Background())
 }
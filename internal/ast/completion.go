@@ -0,0 +1,33 @@
+package ast
+
+import "github.com/matt-hoiland/glox/internal/loxtype"
+
+// CompletionKind says how a statement finished executing.
+type CompletionKind uint8
+
+const (
+	// CompletionNormal means execution carries on with the next statement.
+	CompletionNormal CompletionKind = iota
+	// CompletionReturn means the enclosing function returns the completion's value.
+	CompletionReturn
+	// CompletionBreak means the enclosing loop stops.
+	CompletionBreak
+	// CompletionContinue means the enclosing loop moves on to its next iteration.
+	CompletionContinue
+	// CompletionThrow means the program threw the completion's value, an error object, which unwinds to the
+	// innermost try statement.
+	CompletionThrow
+)
+
+// Completion records how executing a statement ended, so that control flow which skips the rest of a block
+// is passed up explicitly until the statement that handles it. Runtime errors aren't completions: they unwind
+// through expressions as well as statements, so they remain errors. A throw completion becomes its Err likewise
+// once it leaves the statements of a function or script.
+//
+// Visitors that don't execute statements return the zero Completion, or carry what they produce in its Value.
+type Completion struct {
+	Kind  CompletionKind
+	Value loxtype.Type
+	// Err is the error a throw completion fails the program with if nothing catches it.
+	Err error
+}
@@ -15,9 +15,17 @@ type ExprVisitor interface {
 	VisitAssignExpr(*environment.Environment, *AssignExpr) (loxtype.Type, error)
 	VisitBinaryExpr(*environment.Environment, *BinaryExpr) (loxtype.Type, error)
 	VisitCallExpr(*environment.Environment, *CallExpr) (loxtype.Type, error)
+	VisitGetExpr(*environment.Environment, *GetExpr) (loxtype.Type, error)
 	VisitGroupingExpr(*environment.Environment, *GroupingExpr) (loxtype.Type, error)
+	VisitIndexExpr(*environment.Environment, *IndexExpr) (loxtype.Type, error)
+	VisitListExpr(*environment.Environment, *ListExpr) (loxtype.Type, error)
 	VisitLiteralExpr(*environment.Environment, *LiteralExpr) (loxtype.Type, error)
 	VisitLogicalExpr(*environment.Environment, *LogicalExpr) (loxtype.Type, error)
+	VisitMapExpr(*environment.Environment, *MapExpr) (loxtype.Type, error)
+	VisitSetExpr(*environment.Environment, *SetExpr) (loxtype.Type, error)
+	VisitSetIndexExpr(*environment.Environment, *SetIndexExpr) (loxtype.Type, error)
+	VisitSuperExpr(*environment.Environment, *SuperExpr) (loxtype.Type, error)
+	VisitThisExpr(*environment.Environment, *ThisExpr) (loxtype.Type, error)
 	VisitUnaryExpr(*environment.Environment, *UnaryExpr) (loxtype.Type, error)
 	VisitVariableExpr(*environment.Environment, *VariableExpr) (loxtype.Type, error)
 }
@@ -62,16 +70,16 @@ func (e *BinaryExpr) Accept(env *environment.Environment, visitor ExprVisitor) (
 
 type CallExpr struct {
 	Callee    Expr
-	paren     *token.Token
+	Paren     *token.Token
 	Arguments []Expr
 }
 
 var _ Expr = (*CallExpr)(nil)
 
-func NewCallExpr(Callee Expr, paren *token.Token, Arguments []Expr) *CallExpr {
+func NewCallExpr(Callee Expr, Paren *token.Token, Arguments []Expr) *CallExpr {
 	return &CallExpr{
 		Callee:    Callee,
-		paren:     paren,
+		Paren:     Paren,
 		Arguments: Arguments,
 	}
 }
@@ -80,6 +88,24 @@ func (e *CallExpr) Accept(env *environment.Environment, visitor ExprVisitor) (lo
 	return visitor.VisitCallExpr(env, e)
 }
 
+type GetExpr struct {
+	Object Expr
+	Name   *token.Token
+}
+
+var _ Expr = (*GetExpr)(nil)
+
+func NewGetExpr(Object Expr, Name *token.Token) *GetExpr {
+	return &GetExpr{
+		Object: Object,
+		Name:   Name,
+	}
+}
+
+func (e *GetExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitGetExpr(env, e)
+}
+
 type GroupingExpr struct {
 	Expression Expr
 }
@@ -96,15 +122,55 @@ func (e *GroupingExpr) Accept(env *environment.Environment, visitor ExprVisitor)
 	return visitor.VisitGroupingExpr(env, e)
 }
 
+type IndexExpr struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+}
+
+var _ Expr = (*IndexExpr)(nil)
+
+func NewIndexExpr(Object Expr, Bracket *token.Token, Index Expr) *IndexExpr {
+	return &IndexExpr{
+		Object:  Object,
+		Bracket: Bracket,
+		Index:   Index,
+	}
+}
+
+func (e *IndexExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitIndexExpr(env, e)
+}
+
+type ListExpr struct {
+	Bracket  *token.Token
+	Elements []Expr
+}
+
+var _ Expr = (*ListExpr)(nil)
+
+func NewListExpr(Bracket *token.Token, Elements []Expr) *ListExpr {
+	return &ListExpr{
+		Bracket:  Bracket,
+		Elements: Elements,
+	}
+}
+
+func (e *ListExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitListExpr(env, e)
+}
+
 type LiteralExpr struct {
 	Value loxtype.Type
+	Token *token.Token
 }
 
 var _ Expr = (*LiteralExpr)(nil)
 
-func NewLiteralExpr(Value loxtype.Type) *LiteralExpr {
+func NewLiteralExpr(Value loxtype.Type, Token *token.Token) *LiteralExpr {
 	return &LiteralExpr{
 		Value: Value,
+		Token: Token,
 	}
 }
 
@@ -132,6 +198,102 @@ func (e *LogicalExpr) Accept(env *environment.Environment, visitor ExprVisitor)
 	return visitor.VisitLogicalExpr(env, e)
 }
 
+type MapExpr struct {
+	Brace  *token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+var _ Expr = (*MapExpr)(nil)
+
+func NewMapExpr(Brace *token.Token, Keys []Expr, Values []Expr) *MapExpr {
+	return &MapExpr{
+		Brace:  Brace,
+		Keys:   Keys,
+		Values: Values,
+	}
+}
+
+func (e *MapExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitMapExpr(env, e)
+}
+
+type SetExpr struct {
+	Object Expr
+	Name   *token.Token
+	Value  Expr
+}
+
+var _ Expr = (*SetExpr)(nil)
+
+func NewSetExpr(Object Expr, Name *token.Token, Value Expr) *SetExpr {
+	return &SetExpr{
+		Object: Object,
+		Name:   Name,
+		Value:  Value,
+	}
+}
+
+func (e *SetExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitSetExpr(env, e)
+}
+
+type SetIndexExpr struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+	Value   Expr
+}
+
+var _ Expr = (*SetIndexExpr)(nil)
+
+func NewSetIndexExpr(Object Expr, Bracket *token.Token, Index Expr, Value Expr) *SetIndexExpr {
+	return &SetIndexExpr{
+		Object:  Object,
+		Bracket: Bracket,
+		Index:   Index,
+		Value:   Value,
+	}
+}
+
+func (e *SetIndexExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitSetIndexExpr(env, e)
+}
+
+type SuperExpr struct {
+	Keyword *token.Token
+	Method  *token.Token
+}
+
+var _ Expr = (*SuperExpr)(nil)
+
+func NewSuperExpr(Keyword *token.Token, Method *token.Token) *SuperExpr {
+	return &SuperExpr{
+		Keyword: Keyword,
+		Method:  Method,
+	}
+}
+
+func (e *SuperExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitSuperExpr(env, e)
+}
+
+type ThisExpr struct {
+	Keyword *token.Token
+}
+
+var _ Expr = (*ThisExpr)(nil)
+
+func NewThisExpr(Keyword *token.Token) *ThisExpr {
+	return &ThisExpr{
+		Keyword: Keyword,
+	}
+}
+
+func (e *ThisExpr) Accept(env *environment.Environment, visitor ExprVisitor) (loxtype.Type, error) {
+	return visitor.VisitThisExpr(env, e)
+}
+
 type UnaryExpr struct {
 	Operator *token.Token
 	Right    Expr
@@ -12,13 +12,16 @@ func Print(e Stmt) string {
 	return s.String()
 }
 
+// Printer shows the structure of statements, writing expressions as parenthesized prefix notation so that
+// their grouping is explicit, and statements much as they were written, with their bodies indented.
 type Printer struct{}
 
 var _ ExprVisitor = Printer{}
 var _ StmtVisitor = Printer{}
 
 func (ap Printer) Print(e Stmt) (loxtype.Type, error) {
-	return e.Accept(nil, ap)
+	c, err := e.Accept(nil, ap)
+	return c.Value, err
 }
 
 func (ap Printer) parenthesize(env *environment.Environment, name string, expressions ...Expr) (loxtype.Type, error) {
@@ -34,71 +37,204 @@ func (ap Printer) parenthesize(env *environment.Environment, name string, expres
 	return loxtype.String(builder.String()), nil
 }
 
-func (ap Printer) VisitAssignExpr(*environment.Environment, *AssignExpr) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) expr(e Expr) string {
+	s, _ := e.Accept(nil, ap)
+	return s.String()
+}
+
+func (ap Printer) stmt(s Stmt) string {
+	c, _ := s.Accept(nil, ap)
+	return c.Value.String()
+}
+
+// block writes statements between braces, one per line and indented.
+func (ap Printer) block(stmts []Stmt) string {
+	if len(stmts) == 0 {
+		return "{}"
+	}
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for _, s := range stmts {
+		builder.WriteString("  " + strings.ReplaceAll(ap.stmt(s), "\n", "\n  ") + "\n")
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+// function writes a function's name, parameters, and body, as a method is declared.
+func (ap Printer) function(s *FunctionStmt) string {
+	params := make([]string, len(s.Params))
+	for k, p := range s.Params {
+		params[k] = p.Lexeme
+	}
+	return s.Name.Lexeme + "(" + strings.Join(params, ", ") + ") " + ap.block(s.Body)
+}
+
+func (ap Printer) VisitAssignExpr(env *environment.Environment, e *AssignExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, "= "+e.Name.Lexeme, e.Value)
 }
 
 func (ap Printer) VisitBinaryExpr(env *environment.Environment, e *BinaryExpr) (loxtype.Type, error) {
 	return ap.parenthesize(env, e.Operator.Lexeme, e.Left, e.Right)
 }
 
+func (ap Printer) VisitGetExpr(env *environment.Environment, e *GetExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, ". "+e.Name.Lexeme, e.Object)
+}
+
 func (ap Printer) VisitGroupingExpr(env *environment.Environment, e *GroupingExpr) (loxtype.Type, error) {
 	return ap.parenthesize(env, "group", e.Expression)
 }
 
+func (ap Printer) VisitIndexExpr(env *environment.Environment, e *IndexExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, "index", e.Object, e.Index)
+}
+
+func (ap Printer) VisitListExpr(env *environment.Environment, e *ListExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, "list", e.Elements...)
+}
+
 func (ap Printer) VisitLiteralExpr(_ *environment.Environment, e *LiteralExpr) (loxtype.Type, error) {
-	if e.Value == nil {
+	switch value := e.Value.(type) {
+	case nil:
 		return loxtype.Nil{}, nil
+	case loxtype.String:
+		return loxtype.String(`"` + value + `"`), nil
+	default:
+		return e.Value, nil
 	}
-	return e.Value, nil
 }
 
-func (Printer) VisitLogicalExpr(*environment.Environment, *LogicalExpr) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitLogicalExpr(env *environment.Environment, e *LogicalExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, e.Operator.Lexeme, e.Left, e.Right)
+}
+
+func (ap Printer) VisitMapExpr(env *environment.Environment, e *MapExpr) (loxtype.Type, error) {
+	entries := make([]Expr, 0, 2*len(e.Keys))
+	for k := range e.Keys {
+		entries = append(entries, e.Keys[k], e.Values[k])
+	}
+	return ap.parenthesize(env, "map", entries...)
+}
+
+func (ap Printer) VisitSetExpr(env *environment.Environment, e *SetExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, ".= "+e.Name.Lexeme, e.Object, e.Value)
+}
+
+func (ap Printer) VisitSetIndexExpr(env *environment.Environment, e *SetIndexExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, "index=", e.Object, e.Index, e.Value)
+}
+
+func (Printer) VisitSuperExpr(_ *environment.Environment, e *SuperExpr) (loxtype.Type, error) {
+	return loxtype.String("(super " + e.Method.Lexeme + ")"), nil
+}
+
+func (Printer) VisitThisExpr(*environment.Environment, *ThisExpr) (loxtype.Type, error) {
+	return loxtype.String("this"), nil
 }
 
 func (ap Printer) VisitUnaryExpr(env *environment.Environment, e *UnaryExpr) (loxtype.Type, error) {
 	return ap.parenthesize(env, e.Operator.Lexeme, e.Right)
 }
 
-func (Printer) VisitCallExpr(*environment.Environment, *CallExpr) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitCallExpr(env *environment.Environment, e *CallExpr) (loxtype.Type, error) {
+	return ap.parenthesize(env, "call", append([]Expr{e.Callee}, e.Arguments...)...)
 }
 
-func (ap Printer) VisitVariableExpr(*environment.Environment, *VariableExpr) (loxtype.Type, error) {
-	panic("unimplemented")
+func (Printer) VisitVariableExpr(_ *environment.Environment, e *VariableExpr) (loxtype.Type, error) {
+	return loxtype.String(e.Name.Lexeme), nil
+}
+
+func (ap Printer) VisitBlockStmt(_ *environment.Environment, s *BlockStmt) (Completion, error) {
+	return Completion{Value: loxtype.String(ap.block(s.Statements))}, nil
+}
+
+func (Printer) VisitBreakStmt(*environment.Environment, *BreakStmt) (Completion, error) {
+	return Completion{Value: loxtype.String("break;")}, nil
+}
+
+func (ap Printer) VisitClassStmt(_ *environment.Environment, s *ClassStmt) (Completion, error) {
+	var builder strings.Builder
+	builder.WriteString("class " + s.Name.Lexeme)
+	if s.Superclass != nil {
+		builder.WriteString(" < " + s.Superclass.Name.Lexeme)
+	}
+	if len(s.Methods) == 0 {
+		builder.WriteString(" {}")
+		return Completion{Value: loxtype.String(builder.String())}, nil
+	}
+	builder.WriteString(" {\n")
+	for _, m := range s.Methods {
+		builder.WriteString("  " + strings.ReplaceAll(ap.function(m), "\n", "\n  ") + "\n")
+	}
+	builder.WriteString("}")
+	return Completion{Value: loxtype.String(builder.String())}, nil
 }
 
-func (ap Printer) VisitBlockStmt(*environment.Environment, *BlockStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (Printer) VisitContinueStmt(*environment.Environment, *ContinueStmt) (Completion, error) {
+	return Completion{Value: loxtype.String("continue;")}, nil
 }
 
-func (ap Printer) VisitExpressionStmt(env *environment.Environment, s *ExpressionStmt) (loxtype.Type, error) {
+func (ap Printer) VisitExpressionStmt(env *environment.Environment, s *ExpressionStmt) (Completion, error) {
 	value, _ := s.Expression.Accept(env, ap)
-	return loxtype.String(value.String() + ";"), nil
+	return Completion{Value: loxtype.String(value.String() + ";")}, nil
 }
 
-func (Printer) VisitFunctionStmt(*environment.Environment, *FunctionStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitFunctionStmt(_ *environment.Environment, s *FunctionStmt) (Completion, error) {
+	return Completion{Value: loxtype.String("fun " + ap.function(s))}, nil
 }
 
-func (ap Printer) VisitIfStmt(*environment.Environment, *IfStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitIfStmt(_ *environment.Environment, s *IfStmt) (Completion, error) {
+	text := "if (" + ap.expr(s.Condition) + ") " + ap.stmt(s.ThenBranch)
+	if s.ElseBranch != nil {
+		text += " else " + ap.stmt(s.ElseBranch)
+	}
+	return Completion{Value: loxtype.String(text)}, nil
 }
 
-func (ap Printer) VisitPrintStmt(env *environment.Environment, s *PrintStmt) (loxtype.Type, error) {
+func (ap Printer) VisitPrintStmt(env *environment.Environment, s *PrintStmt) (Completion, error) {
 	value, _ := s.Expression.Accept(env, ap)
-	return loxtype.String("print " + value.String() + ";"), nil
+	return Completion{Value: loxtype.String("print " + value.String() + ";")}, nil
 }
 
-func (Printer) VisitReturnStmt(*environment.Environment, *ReturnStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (Printer) VisitImportStmt(_ *environment.Environment, s *ImportStmt) (Completion, error) {
+	return Completion{Value: loxtype.String("import " + s.Path.Lexeme + " as " + s.Name.Lexeme + ";")}, nil
 }
 
-func (ap Printer) VisitVarStmt(*environment.Environment, *VarStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitReturnStmt(_ *environment.Environment, s *ReturnStmt) (Completion, error) {
+	if s.Value == nil {
+		return Completion{Value: loxtype.String("return;")}, nil
+	}
+	return Completion{Value: loxtype.String("return " + ap.expr(s.Value) + ";")}, nil
 }
 
-func (Printer) VisitWhileStmt(*environment.Environment, *WhileStmt) (loxtype.Type, error) {
-	panic("unimplemented")
+func (ap Printer) VisitThrowStmt(_ *environment.Environment, s *ThrowStmt) (Completion, error) {
+	return Completion{Value: loxtype.String("throw " + ap.expr(s.Value) + ";")}, nil
+}
+
+func (ap Printer) VisitTryStmt(_ *environment.Environment, s *TryStmt) (Completion, error) {
+	text := "try " + ap.block(s.Body)
+	if s.CatchName != nil {
+		text += " catch (" + s.CatchName.Lexeme + ") " + ap.block(s.CatchBody)
+	}
+	if s.FinallyBody != nil {
+		text += " finally " + ap.block(s.FinallyBody)
+	}
+	return Completion{Value: loxtype.String(text)}, nil
+}
+
+func (ap Printer) VisitVarStmt(_ *environment.Environment, s *VarStmt) (Completion, error) {
+	if s.Initializer == nil {
+		return Completion{Value: loxtype.String("var " + s.Name.Lexeme + ";")}, nil
+	}
+	return Completion{Value: loxtype.String("var " + s.Name.Lexeme + " = " + ap.expr(s.Initializer) + ";")}, nil
+}
+
+// VisitWhileStmt writes loops desugared from for loops with their increment, which they keep apart from the body.
+func (ap Printer) VisitWhileStmt(_ *environment.Environment, s *WhileStmt) (Completion, error) {
+	if s.Increment != nil {
+		text := "for (; " + ap.expr(s.Condition) + "; " + ap.expr(s.Increment) + ") " + ap.stmt(s.Body)
+		return Completion{Value: loxtype.String(text)}, nil
+	}
+	return Completion{Value: loxtype.String("while (" + ap.expr(s.Condition) + ") " + ap.stmt(s.Body))}, nil
 }
@@ -2,9 +2,15 @@ package ast_test
 
 import (
 	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/matt-hoiland/glox/internal/ast"
 	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
 	"github.com/matt-hoiland/glox/internal/token"
 )
 
@@ -13,11 +19,11 @@ func ExamplePrinter() {
 		ast.NewBinaryExpr(
 			ast.NewUnaryExpr(
 				token.NewToken(token.TypeMinus, "-", nil, 1),
-				ast.NewLiteralExpr(loxtype.Number(123)),
+				ast.NewLiteralExpr(loxtype.Number(123), nil),
 			),
 			token.NewToken(token.TypeStar, "*", nil, 1),
 			ast.NewGroupingExpr(
-				ast.NewLiteralExpr(nil),
+				ast.NewLiteralExpr(nil, nil),
 			),
 		),
 	)
@@ -27,3 +33,68 @@ func ExamplePrinter() {
 	fmt.Println(s)
 	// Output: (* (- 123) (group nil));
 }
+
+func TestPrinter_Print(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name   string
+		Code   string
+		Output string
+	}
+
+	tests := []Test{
+		{Name: "literals", Code: `print [1, "a", true, nil];`, Output: `print (list 1 "a" true nil);`},
+		{Name: "map", Code: `var m = {"a": 1};`, Output: `var m = (map "a" 1);`},
+		{Name: "var_without_initializer", Code: `var a;`, Output: `var a;`},
+		{Name: "assignment", Code: `a = b or c and !d;`, Output: `(= a (or b (and c (! d))));`},
+		{Name: "properties", Code: `a.b = c.d(1, e[2]);`, Output: `(.= b a (call (. d c) 1 (index e 2)));`},
+		{Name: "index_assignment", Code: `a[0] = 1;`, Output: `(index= a 0 1);`},
+		{
+			Name:   "function",
+			Code:   `fun add(a, b) { return a + b; }`,
+			Output: "fun add(a, b) {\n  return (+ a b);\n}",
+		},
+		{Name: "empty_function", Code: `fun f() { return; }`, Output: "fun f() {\n  return;\n}"},
+		{
+			Name:   "class",
+			Code:   `class B < A { init() { this.x = super.y(); } }`,
+			Output: "class B < A {\n  init() {\n    (.= x this (call (super y)));\n  }\n}",
+		},
+		{Name: "empty_class", Code: `class A {}`, Output: "class A {}"},
+		{
+			Name:   "if_else",
+			Code:   `if (a) print 1; else { print 2; }`,
+			Output: "if (a) print 1; else {\n  print 2;\n}",
+		},
+		{
+			Name:   "while",
+			Code:   `while (a < 1) { a = a + 1; continue; }`,
+			Output: "while ((< a 1)) {\n  (= a (+ a 1));\n  continue;\n}",
+		},
+		{
+			Name:   "for",
+			Code:   `for (var i = 0; i < 3; i = i + 1) break;`,
+			Output: "{\n  var i = 0;\n  for (; (< i 3); (= i (+ i 1))) break;\n}",
+		},
+		{
+			Name:   "try",
+			Code:   `try { throw "x"; } catch (e) {} finally { print e; }`,
+			Output: "try {\n  throw \"x\";\n} catch (e) {} finally {\n  print e;\n}",
+		},
+		{Name: "import", Code: `import "a.lox" as a;`, Output: `import "a.lox" as a;`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			tokens, err := scanner.New(test.Code).ScanTokens()
+			require.NoError(t, err)
+			stmts, err := parser.New(tokens).Parse()
+			require.NoError(t, err)
+			require.Len(t, stmts, 1)
+
+			assert.Equal(t, test.Output, ast.Print(stmts[0]))
+		})
+	}
+}
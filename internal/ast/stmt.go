@@ -3,23 +3,28 @@ package ast
 
 import (
 	"github.com/matt-hoiland/glox/internal/environment"
-	"github.com/matt-hoiland/glox/internal/loxtype"
 	"github.com/matt-hoiland/glox/internal/token"
 )
 
 type Stmt interface {
-	Accept(*environment.Environment, StmtVisitor) (loxtype.Type, error)
+	Accept(*environment.Environment, StmtVisitor) (Completion, error)
 }
 
 type StmtVisitor interface {
-	VisitBlockStmt(*environment.Environment, *BlockStmt) (loxtype.Type, error)
-	VisitExpressionStmt(*environment.Environment, *ExpressionStmt) (loxtype.Type, error)
-	VisitFunctionStmt(*environment.Environment, *FunctionStmt) (loxtype.Type, error)
-	VisitIfStmt(*environment.Environment, *IfStmt) (loxtype.Type, error)
-	VisitPrintStmt(*environment.Environment, *PrintStmt) (loxtype.Type, error)
-	VisitReturnStmt(*environment.Environment, *ReturnStmt) (loxtype.Type, error)
-	VisitVarStmt(*environment.Environment, *VarStmt) (loxtype.Type, error)
-	VisitWhileStmt(*environment.Environment, *WhileStmt) (loxtype.Type, error)
+	VisitBlockStmt(*environment.Environment, *BlockStmt) (Completion, error)
+	VisitBreakStmt(*environment.Environment, *BreakStmt) (Completion, error)
+	VisitClassStmt(*environment.Environment, *ClassStmt) (Completion, error)
+	VisitContinueStmt(*environment.Environment, *ContinueStmt) (Completion, error)
+	VisitExpressionStmt(*environment.Environment, *ExpressionStmt) (Completion, error)
+	VisitFunctionStmt(*environment.Environment, *FunctionStmt) (Completion, error)
+	VisitIfStmt(*environment.Environment, *IfStmt) (Completion, error)
+	VisitImportStmt(*environment.Environment, *ImportStmt) (Completion, error)
+	VisitPrintStmt(*environment.Environment, *PrintStmt) (Completion, error)
+	VisitReturnStmt(*environment.Environment, *ReturnStmt) (Completion, error)
+	VisitThrowStmt(*environment.Environment, *ThrowStmt) (Completion, error)
+	VisitTryStmt(*environment.Environment, *TryStmt) (Completion, error)
+	VisitVarStmt(*environment.Environment, *VarStmt) (Completion, error)
+	VisitWhileStmt(*environment.Environment, *WhileStmt) (Completion, error)
 }
 
 type BlockStmt struct {
@@ -34,10 +39,62 @@ func NewBlockStmt(Statements []Stmt) *BlockStmt {
 	}
 }
 
-func (e *BlockStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *BlockStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitBlockStmt(env, e)
 }
 
+type BreakStmt struct {
+	Keyword *token.Token
+}
+
+var _ Stmt = (*BreakStmt)(nil)
+
+func NewBreakStmt(Keyword *token.Token) *BreakStmt {
+	return &BreakStmt{
+		Keyword: Keyword,
+	}
+}
+
+func (e *BreakStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitBreakStmt(env, e)
+}
+
+type ClassStmt struct {
+	Name       *token.Token
+	Superclass *VariableExpr
+	Methods    []*FunctionStmt
+}
+
+var _ Stmt = (*ClassStmt)(nil)
+
+func NewClassStmt(Name *token.Token, Superclass *VariableExpr, Methods []*FunctionStmt) *ClassStmt {
+	return &ClassStmt{
+		Name:       Name,
+		Superclass: Superclass,
+		Methods:    Methods,
+	}
+}
+
+func (e *ClassStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitClassStmt(env, e)
+}
+
+type ContinueStmt struct {
+	Keyword *token.Token
+}
+
+var _ Stmt = (*ContinueStmt)(nil)
+
+func NewContinueStmt(Keyword *token.Token) *ContinueStmt {
+	return &ContinueStmt{
+		Keyword: Keyword,
+	}
+}
+
+func (e *ContinueStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitContinueStmt(env, e)
+}
+
 type ExpressionStmt struct {
 	Expression Expr
 }
@@ -50,7 +107,7 @@ func NewExpressionStmt(Expression Expr) *ExpressionStmt {
 	}
 }
 
-func (e *ExpressionStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *ExpressionStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitExpressionStmt(env, e)
 }
 
@@ -70,7 +127,7 @@ func NewFunctionStmt(Name *token.Token, Params []*token.Token, Body []Stmt) *Fun
 	}
 }
 
-func (e *FunctionStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *FunctionStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitFunctionStmt(env, e)
 }
 
@@ -90,10 +147,30 @@ func NewIfStmt(Condition Expr, ThenBranch Stmt, ElseBranch Stmt) *IfStmt {
 	}
 }
 
-func (e *IfStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *IfStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitIfStmt(env, e)
 }
 
+type ImportStmt struct {
+	Keyword *token.Token
+	Path    *token.Token
+	Name    *token.Token
+}
+
+var _ Stmt = (*ImportStmt)(nil)
+
+func NewImportStmt(Keyword *token.Token, Path *token.Token, Name *token.Token) *ImportStmt {
+	return &ImportStmt{
+		Keyword: Keyword,
+		Path:    Path,
+		Name:    Name,
+	}
+}
+
+func (e *ImportStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitImportStmt(env, e)
+}
+
 type PrintStmt struct {
 	Expression Expr
 }
@@ -106,7 +183,7 @@ func NewPrintStmt(Expression Expr) *PrintStmt {
 	}
 }
 
-func (e *PrintStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *PrintStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitPrintStmt(env, e)
 }
 
@@ -124,10 +201,52 @@ func NewReturnStmt(Keyword *token.Token, Value Expr) *ReturnStmt {
 	}
 }
 
-func (e *ReturnStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *ReturnStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitReturnStmt(env, e)
 }
 
+type ThrowStmt struct {
+	Keyword *token.Token
+	Value   Expr
+}
+
+var _ Stmt = (*ThrowStmt)(nil)
+
+func NewThrowStmt(Keyword *token.Token, Value Expr) *ThrowStmt {
+	return &ThrowStmt{
+		Keyword: Keyword,
+		Value:   Value,
+	}
+}
+
+func (e *ThrowStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitThrowStmt(env, e)
+}
+
+type TryStmt struct {
+	Keyword     *token.Token
+	Body        []Stmt
+	CatchName   *token.Token
+	CatchBody   []Stmt
+	FinallyBody []Stmt
+}
+
+var _ Stmt = (*TryStmt)(nil)
+
+func NewTryStmt(Keyword *token.Token, Body []Stmt, CatchName *token.Token, CatchBody []Stmt, FinallyBody []Stmt) *TryStmt {
+	return &TryStmt{
+		Keyword:     Keyword,
+		Body:        Body,
+		CatchName:   CatchName,
+		CatchBody:   CatchBody,
+		FinallyBody: FinallyBody,
+	}
+}
+
+func (e *TryStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
+	return visitor.VisitTryStmt(env, e)
+}
+
 type VarStmt struct {
 	Name        *token.Token
 	Initializer Expr
@@ -142,24 +261,26 @@ func NewVarStmt(Name *token.Token, Initializer Expr) *VarStmt {
 	}
 }
 
-func (e *VarStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *VarStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitVarStmt(env, e)
 }
 
 type WhileStmt struct {
 	Condition Expr
 	Body      Stmt
+	Increment Expr
 }
 
 var _ Stmt = (*WhileStmt)(nil)
 
-func NewWhileStmt(Condition Expr, Body Stmt) *WhileStmt {
+func NewWhileStmt(Condition Expr, Body Stmt, Increment Expr) *WhileStmt {
 	return &WhileStmt{
 		Condition: Condition,
 		Body:      Body,
+		Increment: Increment,
 	}
 }
 
-func (e *WhileStmt) Accept(env *environment.Environment, visitor StmtVisitor) (loxtype.Type, error) {
+func (e *WhileStmt) Accept(env *environment.Environment, visitor StmtVisitor) (Completion, error) {
 	return visitor.VisitWhileStmt(env, e)
 }
@@ -0,0 +1,75 @@
+// Package bytecode defines the instructions run by the virtual machine and the chunks of code that hold them.
+package bytecode
+
+import (
+	"fmt"
+
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+// Position locates the source text an instruction was compiled from, see [token.Token].
+type Position struct {
+	Line   int
+	Column int
+	Offset int
+	Length int
+	// Lexeme is the text of the token, which errors quote.
+	Lexeme string
+}
+
+// PositionOf returns the position of a token.
+func PositionOf(tok *token.Token) Position {
+	return Position{
+		Line:   tok.Line,
+		Column: tok.Column,
+		Offset: tok.Offset,
+		Length: tok.Length,
+		Lexeme: tok.Lexeme,
+	}
+}
+
+// Chunk is a sequence of instructions along with the constants they refer to.
+type Chunk struct {
+	Code []byte
+	// Positions holds the position of every byte of code, operands included.
+	Positions []Position
+	Constants []loxtype.Type
+}
+
+// Write appends a byte of code compiled from the given position.
+func (c *Chunk) Write(b byte, pos Position) {
+	c.Code = append(c.Code, b)
+	c.Positions = append(c.Positions, pos)
+}
+
+// AddConstant appends a value to the constant pool and returns its index.
+func (c *Chunk) AddConstant(value loxtype.Type) int {
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+// Function is a compiled function: its code along with what the virtual machine needs to call it.
+type Function struct {
+	// Name is empty for the top-level code of a script.
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        Chunk
+}
+
+var _ loxtype.Type = (*Function)(nil)
+
+func (f *Function) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*Function)
+	return loxtype.Boolean(ok && o == f)
+}
+
+func (*Function) IsTruthy() loxtype.Boolean { return true }
+
+func (f *Function) String() string {
+	if f.Name == "" {
+		return "<script>"
+	}
+	return fmt.Sprintf("<fn: %s>", f.Name)
+}
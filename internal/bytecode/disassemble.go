@@ -0,0 +1,114 @@
+package bytecode
+
+import (
+	"fmt"
+	"io"
+)
+
+// Disassemble writes every instruction of the function's chunk, followed by the chunks of the functions
+// it declares, in the order they appear among its constants.
+func Disassemble(w io.Writer, fn *Function) {
+	fmt.Fprintf(w, "== %s ==\n", fn)
+	for offset := 0; offset < len(fn.Chunk.Code); {
+		offset = fn.Chunk.DisassembleInstruction(w, offset)
+	}
+
+	for _, constant := range fn.Chunk.Constants {
+		if inner, ok := constant.(*Function); ok {
+			fmt.Fprintln(w)
+			Disassemble(w, inner)
+		}
+	}
+}
+
+// DisassembleInstruction writes the instruction at offset and returns the offset of the next instruction.
+// Each line shows the offset, the source line, the opcode, and its operands:
+//
+//	0003    2 OpGetGlobal         0 'count'
+func (c *Chunk) DisassembleInstruction(w io.Writer, offset int) int {
+	fmt.Fprintf(w, "%04d ", offset)
+	if offset > 0 && c.Positions[offset].Line == c.Positions[offset-1].Line {
+		fmt.Fprint(w, "   | ")
+	} else {
+		fmt.Fprintf(w, "%4d ", c.Positions[offset].Line)
+	}
+
+	switch op := OpCode(c.Code[offset]); op {
+	case OpConstant, OpGetGlobal, OpDefineGlobal, OpSetGlobal,
+		OpGetProperty, OpSetProperty, OpGetSuper, OpClass, OpMethod, OpImport:
+		return c.constantInstruction(w, op, offset)
+	case OpGetLocal, OpSetLocal, OpGetUpvalue, OpSetUpvalue, OpCall:
+		return c.byteInstruction(w, op, offset)
+	case OpList, OpMap:
+		return c.shortInstruction(w, op, offset)
+	case OpJump, OpJumpIfFalse, OpTry:
+		return c.jumpInstruction(w, op, 1, offset)
+	case OpLoop:
+		return c.jumpInstruction(w, op, -1, offset)
+	case OpInvoke, OpSuperInvoke:
+		return c.invokeInstruction(w, op, offset)
+	case OpClosure:
+		return c.closureInstruction(w, offset)
+	case OpNil, OpTrue, OpFalse, OpPop, OpGetIndex, OpSetIndex, OpEqual, OpGreater, OpGreaterEqual, OpLess,
+		OpLessEqual, OpAdd, OpSubtract, OpMultiply, OpDivide, OpNot, OpNegate, OpPrint, OpEndTry, OpThrow,
+		OpCloseUpvalue, OpReturn, OpInherit:
+		fmt.Fprintln(w, op)
+		return offset + 1
+	default:
+		fmt.Fprintf(w, "Unknown opcode %d\n", op)
+		return offset + 1
+	}
+}
+
+func (c *Chunk) readUint16(offset int) int {
+	return int(c.Code[offset])<<8 | int(c.Code[offset+1])
+}
+
+func (c *Chunk) constantInstruction(w io.Writer, op OpCode, offset int) int {
+	constant := c.readUint16(offset + 1)
+	fmt.Fprintf(w, "%-16s %4d '%s'\n", op, constant, c.Constants[constant])
+	return offset + 3
+}
+
+func (c *Chunk) byteInstruction(w io.Writer, op OpCode, offset int) int {
+	fmt.Fprintf(w, "%-16s %4d\n", op, c.Code[offset+1])
+	return offset + 2
+}
+
+func (c *Chunk) shortInstruction(w io.Writer, op OpCode, offset int) int {
+	fmt.Fprintf(w, "%-16s %4d\n", op, c.readUint16(offset+1))
+	return offset + 3
+}
+
+func (c *Chunk) jumpInstruction(w io.Writer, op OpCode, sign, offset int) int {
+	jump := c.readUint16(offset + 1)
+	fmt.Fprintf(w, "%-16s %4d -> %d\n", op, offset, offset+3+sign*jump)
+	return offset + 3
+}
+
+func (c *Chunk) invokeInstruction(w io.Writer, op OpCode, offset int) int {
+	constant := c.readUint16(offset + 1)
+	argc := c.Code[offset+3]
+	fmt.Fprintf(w, "%-16s (%d args) %4d '%s'\n", op, argc, constant, c.Constants[constant])
+	return offset + 4
+}
+
+func (c *Chunk) closureInstruction(w io.Writer, offset int) int {
+	constant := c.readUint16(offset + 1)
+	fmt.Fprintf(w, "%-16s %4d %s\n", OpClosure, constant, c.Constants[constant])
+	offset += 3
+
+	fn, ok := c.Constants[constant].(*Function)
+	if !ok {
+		return offset
+	}
+	for range fn.UpvalueCount {
+		kind := "upvalue"
+		if c.Code[offset] == 1 {
+			kind = "local"
+		}
+		fmt.Fprintf(w, "%04d    |                     %s %d\n", offset, kind, c.Code[offset+1])
+		offset += 2
+	}
+	return offset
+}
@@ -0,0 +1,99 @@
+package bytecode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/compiler"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+func TestDisassemble(t *testing.T) {
+	t.Parallel()
+
+	source := loxtest.Dedent(`
+		fun counter() {
+			var i = 0;
+			fun count() { i = i + 1; return i; }
+			return count;
+		}
+		var c = counter();
+		while (c() < 3) print "again";
+		class A < B { m() { super.m(1); } }
+	`)
+	tokens, err := scanner.New(source).ScanTokens()
+	require.NoError(t, err)
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+	fn, err := compiler.Compile(stmts)
+	require.NoError(t, err)
+
+	var bob strings.Builder
+	bytecode.Disassemble(&bob, fn)
+	assert.Equal(t, loxtest.Dedent(`
+		== <script> ==
+		0000    1 OpClosure           1 <fn: counter>
+		0003    | OpDefineGlobal      0 'counter'
+		0006    6 OpGetGlobal         0 'counter'
+		0009    | OpCall              0
+		0011    | OpDefineGlobal      2 'c'
+		0014    7 OpGetGlobal         2 'c'
+		0017    | OpCall              0
+		0019    | OpConstant          3 '3'
+		0022    | OpLess
+		0023    | OpJumpIfFalse      23 -> 34
+		0026    | OpPop
+		0027    | OpConstant          4 'again'
+		0030    | OpPrint
+		0031    | OpLoop             31 -> 14
+		0034    | OpPop
+		0035    8 OpClass             5 'A'
+		0038    | OpDefineGlobal      5 'A'
+		0041    | OpGetGlobal         6 'B'
+		0044    | OpGetGlobal         5 'A'
+		0047    | OpInherit
+		0048    | OpGetGlobal         5 'A'
+		0051    | OpClosure           8 <fn: m>
+		0054    |                     local 1
+		0056    | OpMethod            7 'm'
+		0059    | OpPop
+		0060    | OpCloseUpvalue
+		0061    | OpNil
+		0062    | OpReturn
+
+		== <fn: counter> ==
+		0000    2 OpConstant          0 '0'
+		0003    3 OpClosure           1 <fn: count>
+		0006    |                     local 1
+		0008    4 OpGetLocal          2
+		0010    | OpReturn
+		0011    | OpNil
+		0012    | OpReturn
+
+		== <fn: count> ==
+		0000    3 OpGetUpvalue        0
+		0002    | OpConstant          0 '1'
+		0005    | OpAdd
+		0006    | OpSetUpvalue        0
+		0008    | OpPop
+		0009    | OpGetUpvalue        0
+		0011    | OpReturn
+		0012    | OpNil
+		0013    | OpReturn
+
+		== <fn: m> ==
+		0000    8 OpGetLocal          0
+		0002    | OpConstant          1 '1'
+		0005    | OpGetUpvalue        0
+		0007    | OpSuperInvoke    (1 args)    0 'm'
+		0011    | OpPop
+		0012    | OpNil
+		0013    | OpReturn
+	`), bob.String())
+}
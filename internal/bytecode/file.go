@@ -0,0 +1,514 @@
+package bytecode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// A compiled program is stored as the magic header and the format version, followed by the name of the source file
+// and then the top-level function. Integers are unsigned varints unless noted,
+// and strings are prefixed by their length.
+//
+//	function  -> name arity upvalueCount code positions constants
+//	code      -> length byte*
+//	positions -> runCount ( runLength line column offset length lexeme )*
+//	constants -> count ( tag payload )*
+//
+// Positions are run-length encoded, since every byte of an instruction shares the position of its opcode.
+// Numbers are stored as the big-endian bits of a float64, and functions are nested in the constants that hold them.
+const (
+	Magic   = "\x7fLOXC"
+	Version = 7
+)
+
+var (
+	ErrNotCompiled         = errors.New("not a compiled lox program")
+	ErrUnsupportedVersion  = errors.New("unsupported compiled program version")
+	ErrMalformed           = errors.New("malformed compiled program")
+	ErrUnsupportedConstant = errors.New("constant can't be stored in a compiled program")
+)
+
+const (
+	tagNumber byte = iota + 1
+	tagString
+	tagFunction
+)
+
+// File is a compiled program.
+type File struct {
+	// Source names the file the program was compiled from, for reporting errors.
+	Source string
+	Script *Function
+}
+
+// IsCompiled reports whether data begins like a compiled program.
+func IsCompiled(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Magic))
+}
+
+// Encode writes a compiled program to w.
+func Encode(w io.Writer, f *File) error {
+	e := &encoder{buf: []byte(Magic)}
+	e.buf = binary.BigEndian.AppendUint16(e.buf, Version)
+	e.string(f.Source)
+	if err := e.function(f.Script); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uint(n int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(n))
+}
+
+func (e *encoder) string(s string) {
+	e.uint(len(s))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) function(fn *Function) error {
+	e.string(fn.Name)
+	e.uint(fn.Arity)
+	e.uint(fn.UpvalueCount)
+
+	e.uint(len(fn.Chunk.Code))
+	e.buf = append(e.buf, fn.Chunk.Code...)
+
+	var runs [][2]int // start and length of each run of equal positions
+	for i, pos := range fn.Chunk.Positions {
+		if i > 0 && pos == fn.Chunk.Positions[i-1] {
+			runs[len(runs)-1][1]++
+			continue
+		}
+		runs = append(runs, [2]int{i, 1})
+	}
+	e.uint(len(runs))
+	for _, run := range runs {
+		pos := fn.Chunk.Positions[run[0]]
+		e.uint(run[1])
+		e.uint(pos.Line)
+		e.uint(pos.Column)
+		e.uint(pos.Offset)
+		e.uint(pos.Length)
+		e.string(pos.Lexeme)
+	}
+
+	e.uint(len(fn.Chunk.Constants))
+	for _, constant := range fn.Chunk.Constants {
+		switch c := constant.(type) {
+		case loxtype.Number:
+			e.buf = append(e.buf, tagNumber)
+			e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(float64(c)))
+		case loxtype.String:
+			e.buf = append(e.buf, tagString)
+			e.string(string(c))
+		case *Function:
+			e.buf = append(e.buf, tagFunction)
+			if err := e.function(c); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedConstant, constant)
+		}
+	}
+	return nil
+}
+
+// Decode reads a compiled program from r.
+// The program's code is checked well enough that the virtual machine can run it without crashing.
+func Decode(r io.Reader) (*File, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+
+	header := make([]byte, len(Magic)+2)
+	if _, err := io.ReadFull(d.r, header); err != nil || !IsCompiled(header) {
+		return nil, ErrNotCompiled
+	}
+	if version := binary.BigEndian.Uint16(header[len(Magic):]); version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	f := &File{}
+	var err error
+	if f.Source, err = d.string(); err != nil {
+		return nil, err
+	}
+	if f.Script, err = d.function(); err != nil {
+		return nil, err
+	}
+	if f.Script.Arity != 0 || f.Script.UpvalueCount != 0 {
+		return nil, fmt.Errorf("%w: the script takes arguments or upvalues", ErrMalformed)
+	}
+	if _, err = d.r.ReadByte(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: trailing data", ErrMalformed)
+	}
+	return f, nil
+}
+
+type decoder struct {
+	r *bufio.Reader
+}
+
+func (d *decoder) malformed(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %w", ErrMalformed, err)
+}
+
+func (d *decoder) uint() (int, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, d.malformed(err)
+	}
+	if n > math.MaxInt32 {
+		return 0, d.malformed(fmt.Errorf("integer %d out of range", n))
+	}
+	return int(n), nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.uint()
+	if err != nil {
+		return nil, err
+	}
+	// Read in pieces so that a corrupt length can't demand a huge allocation up front.
+	var buf bytes.Buffer
+	if _, err = io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, d.malformed(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *decoder) string() (string, error) {
+	b, err := d.bytes()
+	return string(b), err
+}
+
+func (d *decoder) function() (*Function, error) {
+	fn := &Function{}
+	var err error
+	if fn.Name, err = d.string(); err != nil {
+		return nil, err
+	}
+	if fn.Arity, err = d.uint(); err != nil {
+		return nil, err
+	}
+	if fn.UpvalueCount, err = d.uint(); err != nil {
+		return nil, err
+	}
+	if fn.Chunk.Code, err = d.bytes(); err != nil {
+		return nil, err
+	}
+	if err = d.positions(&fn.Chunk); err != nil {
+		return nil, err
+	}
+	if err = d.constants(&fn.Chunk); err != nil {
+		return nil, err
+	}
+	if err = verify(fn); err != nil {
+		return nil, d.malformed(fmt.Errorf("%s: %w", fn, err))
+	}
+	return fn, nil
+}
+
+func (d *decoder) positions(c *Chunk) error {
+	runs, err := d.uint()
+	if err != nil {
+		return err
+	}
+	for range runs {
+		var fields [5]int
+		for i := range fields {
+			if fields[i], err = d.uint(); err != nil {
+				return err
+			}
+		}
+		lexeme, err := d.string()
+		if err != nil {
+			return err
+		}
+		if len(c.Positions)+fields[0] > len(c.Code) {
+			return d.malformed(errors.New("more positions than code"))
+		}
+		pos := Position{Line: fields[1], Column: fields[2], Offset: fields[3], Length: fields[4], Lexeme: lexeme}
+		for range fields[0] {
+			c.Positions = append(c.Positions, pos)
+		}
+	}
+	if len(c.Positions) != len(c.Code) {
+		return d.malformed(errors.New("fewer positions than code"))
+	}
+	return nil
+}
+
+func (d *decoder) constants(c *Chunk) error {
+	count, err := d.uint()
+	if err != nil {
+		return err
+	}
+	for range count {
+		tag, err := d.r.ReadByte()
+		if err != nil {
+			return d.malformed(err)
+		}
+
+		switch tag {
+		case tagNumber:
+			var bits [8]byte
+			if _, err = io.ReadFull(d.r, bits[:]); err != nil {
+				return d.malformed(err)
+			}
+			c.AddConstant(loxtype.Number(math.Float64frombits(binary.BigEndian.Uint64(bits[:]))))
+		case tagString:
+			s, err := d.string()
+			if err != nil {
+				return err
+			}
+			c.AddConstant(loxtype.String(s))
+		case tagFunction:
+			fn, err := d.function()
+			if err != nil {
+				return err
+			}
+			c.AddConstant(fn)
+		default:
+			return d.malformed(fmt.Errorf("unknown constant tag %d", tag))
+		}
+	}
+	return nil
+}
+
+// verify checks that every instruction of the function is whole, that its operands refer to constants
+// of the right kind and to upvalues that exist, that its jumps land on instructions, and that it ends by returning.
+// Then it checks how the instructions use the stack, see [verifyStack].
+//
+//nolint:gocyclo,cyclop,funlen // One case per kind of operand.
+func verify(fn *Function) error {
+	c := &fn.Chunk
+	constant := func(offset int) (loxtype.Type, error) {
+		index := c.readUint16(offset)
+		if index >= len(c.Constants) {
+			return nil, fmt.Errorf("constant %d out of range at %04d", index, offset)
+		}
+		return c.Constants[index], nil
+	}
+
+	// sizes holds the size of the instruction at each offset where one starts, and jumps the offsets of jumps.
+	sizes := make([]int, len(c.Code))
+	var jumps []int
+	var last OpCode
+	for offset := 0; offset < len(c.Code); {
+		op := OpCode(c.Code[offset])
+		if op > OpImport {
+			return fmt.Errorf("unknown opcode %d at %04d", op, offset)
+		}
+		last = op
+
+		width := 0
+		switch op {
+		case OpGetLocal, OpSetLocal, OpGetUpvalue, OpSetUpvalue, OpCall:
+			width = 1
+		case OpConstant, OpGetGlobal, OpDefineGlobal, OpSetGlobal, OpGetProperty, OpSetProperty, OpGetSuper,
+			OpList, OpMap, OpJump, OpJumpIfFalse, OpLoop, OpTry, OpClosure, OpClass, OpMethod, OpImport:
+			width = 2
+		case OpInvoke, OpSuperInvoke:
+			width = 3
+		default:
+		}
+		if offset+width >= len(c.Code) {
+			return fmt.Errorf("%s at %04d is cut short", op, offset)
+		}
+
+		switch op {
+		case OpConstant:
+			if _, err := constant(offset + 1); err != nil {
+				return err
+			}
+		case OpGetGlobal, OpDefineGlobal, OpSetGlobal, OpGetProperty, OpSetProperty, OpGetSuper, OpClass, OpMethod,
+			OpInvoke, OpSuperInvoke, OpImport:
+			value, err := constant(offset + 1)
+			if err != nil {
+				return err
+			}
+			if _, ok := value.(loxtype.String); !ok {
+				return fmt.Errorf("%s at %04d names a %T", op, offset, value)
+			}
+		case OpGetUpvalue, OpSetUpvalue:
+			if int(c.Code[offset+1]) >= fn.UpvalueCount {
+				return fmt.Errorf("upvalue %d out of range at %04d", c.Code[offset+1], offset)
+			}
+		case OpJump, OpJumpIfFalse, OpLoop, OpTry:
+			target := jumpTarget(c, offset)
+			if target < 0 || target >= len(c.Code) {
+				return fmt.Errorf("%s at %04d jumps out of the code", op, offset)
+			}
+			jumps = append(jumps, offset)
+		case OpClosure:
+			value, err := constant(offset + 1)
+			if err != nil {
+				return err
+			}
+			inner, ok := value.(*Function)
+			if !ok {
+				return fmt.Errorf("%s at %04d closes over a %T", op, offset, value)
+			}
+			if offset+width+2*inner.UpvalueCount >= len(c.Code) {
+				return fmt.Errorf("%s at %04d is cut short", op, offset)
+			}
+			for i := range inner.UpvalueCount {
+				isLocal, index := c.Code[offset+3+2*i], c.Code[offset+4+2*i]
+				if isLocal > 1 || (isLocal == 0 && int(index) >= fn.UpvalueCount) {
+					return fmt.Errorf("%s at %04d captures a bad upvalue", op, offset)
+				}
+			}
+			width += 2 * inner.UpvalueCount
+		default:
+		}
+
+		sizes[offset] = 1 + width
+		offset += 1 + width
+	}
+
+	if last != OpReturn {
+		return errors.New("code doesn't end by returning")
+	}
+	for _, offset := range jumps {
+		if sizes[jumpTarget(c, offset)] == 0 {
+			return fmt.Errorf("%s at %04d jumps into the middle of an instruction", OpCode(c.Code[offset]), offset)
+		}
+	}
+	return verifyStack(fn, sizes)
+}
+
+// stackState is what's known about the stack where an instruction starts: how many values the function has on it,
+// counting the callee and its arguments, and how many handlers it has installed.
+type stackState struct {
+	depth    int
+	handlers int
+}
+
+// verifyStack follows every path through the function's code, given the sizes of its instructions, to check that
+// no instruction takes more values from the stack than the function has put there or addresses a slot it hasn't,
+// that handlers are only removed once installed, and that every path to an instruction leaves the stack alike.
+//
+//nolint:gocyclo,cyclop,funlen // One case per kind of instruction.
+func verifyStack(fn *Function, sizes []int) error {
+	c := &fn.Chunk
+	states := make([]*stackState, len(c.Code))
+	pending := []int{0}
+	states[0] = &stackState{depth: 1 + fn.Arity}
+
+	// reach records the state the stack is in when the code reaches an instruction, to be followed from there.
+	reach := func(offset int, s stackState) error {
+		switch known := states[offset]; {
+		case known == nil:
+			states[offset] = &s
+			pending = append(pending, offset)
+		case *known != s:
+			return fmt.Errorf("paths to %04d leave the stack differently", offset)
+		}
+		return nil
+	}
+
+	for len(pending) > 0 {
+		offset := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		s := *states[offset]
+		op := OpCode(c.Code[offset])
+		next := offset + sizes[offset]
+
+		// pops and pushes are how many values the instruction takes from the stack and leaves on it.
+		pops, pushes := 0, 0
+		slot := -1
+		switch op {
+		case OpConstant, OpNil, OpTrue, OpFalse, OpGetGlobal, OpGetUpvalue, OpClass, OpImport:
+			pushes = 1
+		case OpPop, OpDefineGlobal, OpPrint, OpCloseUpvalue:
+			pops = 1
+		case OpGetLocal:
+			slot, pushes = int(c.Code[offset+1]), 1
+		case OpSetLocal:
+			slot, pops, pushes = int(c.Code[offset+1]), 1, 1
+		case OpSetGlobal, OpSetUpvalue, OpGetProperty, OpNot, OpNegate, OpJumpIfFalse:
+			pops, pushes = 1, 1
+		case OpSetProperty, OpGetSuper, OpGetIndex, OpEqual, OpGreater, OpGreaterEqual, OpLess, OpLessEqual,
+			OpAdd, OpSubtract, OpMultiply, OpDivide, OpInherit, OpMethod:
+			pops, pushes = 2, 1
+		case OpSetIndex:
+			pops, pushes = 3, 1
+		case OpList:
+			pops, pushes = c.readUint16(offset+1), 1
+		case OpMap:
+			pops, pushes = 2*c.readUint16(offset+1), 1
+		case OpCall:
+			pops, pushes = int(c.Code[offset+1])+1, 1
+		case OpInvoke:
+			pops, pushes = int(c.Code[offset+3])+1, 1
+		case OpSuperInvoke:
+			pops, pushes = int(c.Code[offset+3])+2, 1
+		case OpClosure:
+			pushes = 1
+			for k := offset + 3; k < next; k += 2 {
+				if c.Code[k] == 1 && int(c.Code[k+1]) >= s.depth {
+					return fmt.Errorf("%s at %04d captures slot %d of %d", op, offset, c.Code[k+1], s.depth)
+				}
+			}
+		case OpThrow, OpReturn:
+			pops = 1
+		case OpEndTry:
+			if s.handlers == 0 {
+				return fmt.Errorf("%s at %04d has no handler to remove", op, offset)
+			}
+			s.handlers--
+		default:
+		}
+		if pops > s.depth {
+			return fmt.Errorf("%s at %04d takes %d values from a stack of %d", op, offset, pops, s.depth)
+		}
+		if slot >= s.depth {
+			return fmt.Errorf("%s at %04d addresses slot %d of %d", op, offset, slot, s.depth)
+		}
+		s.depth += pushes - pops
+
+		var err error
+		switch op {
+		case OpThrow, OpReturn:
+			continue
+		case OpJump, OpLoop:
+			err = reach(jumpTarget(c, offset), s)
+		case OpJumpIfFalse:
+			err = errors.Join(reach(jumpTarget(c, offset), s), reach(next, s))
+		case OpTry:
+			// A handler starts with the error pushed where the stack was when it was installed.
+			handled := stackState{depth: s.depth + 1, handlers: s.handlers}
+			s.handlers++
+			err = errors.Join(reach(jumpTarget(c, offset), handled), reach(next, s))
+		default:
+			err = reach(next, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jumpTarget returns where the jump at offset lands.
+func jumpTarget(c *Chunk, offset int) int {
+	if OpCode(c.Code[offset]) == OpLoop {
+		return offset + 3 - c.readUint16(offset+1)
+	}
+	return offset + 3 + c.readUint16(offset+1)
+}
@@ -0,0 +1,202 @@
+package bytecode_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/compiler"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/vm"
+)
+
+func compile(t *testing.T, source string) *bytecode.Function {
+	t.Helper()
+	tokens, err := scanner.New(source).ScanTokens()
+	require.NoError(t, err)
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+	fn, err := compiler.Compile(stmts)
+	require.NoError(t, err)
+	return fn
+}
+
+func encode(t *testing.T, fn *bytecode.Function) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, bytecode.Encode(&buf, &bytecode.File{Source: "test.lox", Script: fn}))
+	return buf.Bytes()
+}
+
+func TestEncode_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range loxtest.Cases() {
+		if test.ExpectedError != nil {
+			continue
+		}
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			fn := compile(t, test.Source)
+			data := encode(t, fn)
+			assert.True(t, bytecode.IsCompiled(data))
+
+			f, err := bytecode.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, "test.lox", f.Source)
+			assert.Equal(t, fn, f.Script)
+
+			var bob strings.Builder
+			machine := vm.New(&bob,
+				vm.WithCapabilities(native.AllCapabilities),
+				vm.WithHost(native.Host{FS: loxtest.NewMemFS(test.Files)}),
+			)
+			require.NoError(t, machine.Interpret(f.Script))
+			assert.Equal(t, test.Output, bob.String())
+		})
+	}
+}
+
+func TestDecode_errors(t *testing.T) {
+	t.Parallel()
+
+	data := encode(t, compile(t, `fun f(a) { return a + 1; } print f(2);`))
+	withVersion := func(version byte) []byte {
+		b := bytes.Clone(data)
+		b[len(bytecode.Magic)+1] = version
+		return b
+	}
+	withParameter := compile(t, `print 1;`)
+	withParameter.Arity = 1
+
+	tests := []struct {
+		Name          string
+		Data          []byte
+		ExpectedError error
+	}{
+		{Name: "empty", Data: nil, ExpectedError: bytecode.ErrNotCompiled},
+		{Name: "source", Data: []byte("print 1;"), ExpectedError: bytecode.ErrNotCompiled},
+		{Name: "future_version", Data: withVersion(bytecode.Version + 1), ExpectedError: bytecode.ErrUnsupportedVersion},
+		{Name: "truncated", Data: data[:len(data)-3], ExpectedError: bytecode.ErrMalformed},
+		{Name: "trailing_data", Data: append(bytes.Clone(data), 0), ExpectedError: bytecode.ErrMalformed},
+		{Name: "script_parameter", Data: encode(t, withParameter), ExpectedError: bytecode.ErrMalformed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := bytecode.Decode(bytes.NewReader(test.Data))
+			assert.ErrorIs(t, err, test.ExpectedError)
+		})
+	}
+}
+
+func TestDecode_badCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name    string
+		Source  string
+		Patch   map[int]bytecode.OpCode
+		Message string
+	}{
+		{
+			// OpConstant 9 with a single constant.
+			Name:    "constant_out_of_range",
+			Source:  `print 1;`,
+			Patch:   map[int]bytecode.OpCode{1: 0, 2: 9},
+			Message: "constant 9 out of range",
+		},
+		{
+			// The OpJumpIfFalse at 0001 lands on the operand of the OpJump at 0009.
+			Name:    "jump_into_instruction",
+			Source:  `if (true) print 1;`,
+			Patch:   map[int]bytecode.OpCode{3: 7},
+			Message: "OpJumpIfFalse at 0001 jumps into the middle of an instruction",
+		},
+		{
+			Name:    "stack_underflow",
+			Source:  `print 1;`,
+			Patch:   map[int]bytecode.OpCode{3: bytecode.OpPop, 4: bytecode.OpPop},
+			Message: "OpReturn at 0005 takes 1 values from a stack of 0",
+		},
+		{
+			Name:    "local_out_of_range",
+			Source:  `print 1;`,
+			Patch:   map[int]bytecode.OpCode{0: bytecode.OpGetLocal, 1: 2, 2: bytecode.OpNil},
+			Message: "OpGetLocal at 0000 addresses slot 2 of 1",
+		},
+		{
+			// The branch that skips the print leaves an extra value for the code after it.
+			Name:    "uneven_paths",
+			Source:  `if (true) print 1;`,
+			Patch:   map[int]bytecode.OpCode{12: bytecode.OpNil},
+			Message: "paths to 0013 leave the stack differently",
+		},
+		{
+			Name:    "no_handler",
+			Source:  `print 1;`,
+			Patch:   map[int]bytecode.OpCode{4: bytecode.OpEndTry},
+			Message: "OpEndTry at 0004 has no handler to remove",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			fn := compile(t, test.Source)
+			for offset, b := range test.Patch {
+				fn.Chunk.Code[offset] = byte(b)
+			}
+
+			_, err := bytecode.Decode(bytes.NewReader(encode(t, fn)))
+			require.ErrorIs(t, err, bytecode.ErrMalformed)
+			assert.ErrorContains(t, err, test.Message)
+		})
+	}
+}
+
+// FuzzDecode checks that whatever decodes runs without panicking.
+func FuzzDecode(f *testing.F) {
+	// Programs run only briefly, since corrupt ones can loop forever or build strings exponentially.
+	const maxSteps = 100
+
+	for _, test := range loxtest.Cases() {
+		tokens, err := scanner.New(test.Source).ScanTokens()
+		if err != nil {
+			continue
+		}
+		stmts, err := parser.New(tokens).Parse()
+		if err != nil {
+			continue
+		}
+		fn, err := compiler.Compile(stmts)
+		if err != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		require.NoError(f, bytecode.Encode(&buf, &bytecode.File{Script: fn}))
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, err := bytecode.Decode(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		// The program may fail, with its own error or with ErrMalformed if it gets the types of values wrong,
+		// but mustn't panic.
+		_ = vm.New(io.Discard, vm.WithMaxSteps(maxSteps)).Interpret(file.Script)
+	})
+}
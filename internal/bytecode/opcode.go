@@ -0,0 +1,85 @@
+package bytecode
+
+// OpCode identifies an instruction. Operands follow the opcode in the code, and wider operands are big-endian.
+//
+//go:generate stringer -type=OpCode
+type OpCode byte
+
+const (
+	// OpConstant pushes the constant at its 16-bit operand.
+	OpConstant OpCode = iota
+	OpNil
+	OpTrue
+	OpFalse
+	OpPop
+
+	// OpGetLocal and OpSetLocal address a stack slot of the current call by their 8-bit operand.
+	OpGetLocal
+	OpSetLocal
+	// OpGetGlobal, OpDefineGlobal, and OpSetGlobal name a global by the constant at their 16-bit operand.
+	OpGetGlobal
+	OpDefineGlobal
+	OpSetGlobal
+	// OpGetUpvalue and OpSetUpvalue address an upvalue of the current closure by their 8-bit operand.
+	OpGetUpvalue
+	OpSetUpvalue
+	// OpGetProperty, OpSetProperty, and OpGetSuper name a property by the constant at their 16-bit operand.
+	OpGetProperty
+	OpSetProperty
+	OpGetSuper
+	// OpList collects as many values as its 16-bit operand into a list, and OpMap as many pairs of keys and values
+	// into a map. OpGetIndex and OpSetIndex index either.
+	OpList
+	OpMap
+	OpGetIndex
+	OpSetIndex
+
+	OpEqual
+	OpGreater
+	OpGreaterEqual
+	OpLess
+	OpLessEqual
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpNot
+	OpNegate
+
+	OpPrint
+
+	// OpJump, OpJumpIfFalse, and OpLoop move the instruction pointer by their 16-bit operand,
+	// counted from the end of the instruction.
+	OpJump
+	OpJumpIfFalse
+	OpLoop
+
+	// OpTry installs a handler at the instruction its 16-bit operand jumps to, counted as for OpJump.
+	// A thrown error unwinds the stack to where it was at OpTry and jumps to the handler with the error pushed.
+	// OpEndTry removes the most recently installed handler, and OpThrow throws the value on top of the stack.
+	OpTry
+	OpEndTry
+	OpThrow
+
+	// OpCall calls the value beneath as many arguments as its 8-bit operand.
+	OpCall
+	// OpInvoke and OpSuperInvoke call the method named by the constant at their 16-bit operand,
+	// with as many arguments as their following 8-bit operand.
+	OpInvoke
+	OpSuperInvoke
+	// OpClosure wraps the function constant at its 16-bit operand in a closure.
+	// It is followed by a pair of 8-bit operands for each upvalue: whether the upvalue captures a local
+	// of the enclosing function rather than one of its upvalues, and the index of that local or upvalue.
+	OpClosure
+	OpCloseUpvalue
+	OpReturn
+
+	// OpClass and OpMethod name the class or method by the constant at their 16-bit operand.
+	OpClass
+	OpInherit
+	OpMethod
+
+	// OpImport pushes the namespace of the module at the path named by the constant at its 16-bit operand,
+	// running the module first unless it has already been imported.
+	OpImport
+)
@@ -0,0 +1,531 @@
+//nolint:nilnil // The visitors return nothing but errors.
+
+// Package compiler compiles syntax trees into bytecode for the virtual machine.
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/resolver"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+var (
+	ErrTooManyArguments  = errors.New("can't have more than 255 arguments")
+	ErrTooManyConstants  = errors.New("too many constants in one chunk")
+	ErrTooManyElements   = errors.New("can't have more than 65535 elements in a list literal")
+	ErrTooManyEntries    = errors.New("can't have more than 65535 entries in a map literal")
+	ErrTooManyLocals     = errors.New("too many local variables in function")
+	ErrTooManyParameters = errors.New("can't have more than 255 parameters")
+	ErrTooManyUpvalues   = errors.New("too many closure variables in function")
+	ErrJumpTooLarge      = errors.New("too much code to jump over")
+)
+
+const (
+	maxArguments = math.MaxUint8
+	maxElements  = math.MaxUint16
+	maxLocals    = math.MaxUint8 + 1
+	maxUpvalues  = math.MaxUint8 + 1
+)
+
+type functionKind string
+
+const (
+	kindScript      functionKind = "SCRIPT"
+	kindFunction    functionKind = "FUNCTION"
+	kindMethod      functionKind = "METHOD"
+	kindInitializer functionKind = "INITIALIZER"
+)
+
+type local struct {
+	name string
+	// depth is the scope depth of the local, or -1 while its initializer is being compiled.
+	depth      int
+	isCaptured bool
+}
+
+// tryBlock is a try statement whose body or catch clause is being compiled.
+type tryBlock struct {
+	// finally is nil if the statement has no finally clause.
+	finally []ast.Stmt
+	// handlers counts the handlers the statement has installed where compilation is: one for its body,
+	// and one for its catch clause when it also has a finally clause to run.
+	handlers int
+}
+
+// loop is a loop whose body is being compiled.
+type loop struct {
+	// scopeDepth and tries are the depths of the scopes and try statements around the loop,
+	// which breaking out of it or continuing it leaves.
+	scopeDepth int
+	tries      int
+	// breaks and continues are the jumps to patch to the loop's exit and to the end of its body.
+	breaks    []int
+	continues []int
+}
+
+type upvalue struct {
+	index   uint8
+	isLocal bool
+}
+
+// Compiler compiles the body of a single function.
+// Functions declared within it are compiled by compilers that enclose it.
+type Compiler struct {
+	enclosing  *Compiler
+	function   *bytecode.Function
+	kind       functionKind
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+	constants  map[loxtype.Type]uint16
+	tries      []tryBlock
+	loops      []*loop
+
+	// tok is the token most recently compiled, whose position is given to the code emitted.
+	tok *token.Token
+}
+
+var (
+	_ ast.StmtVisitor = (*Compiler)(nil)
+	_ ast.ExprVisitor = (*Compiler)(nil)
+)
+
+// Compile checks a program with the resolver and compiles it into the function that runs it as a script.
+func Compile(stmts []ast.Stmt) (*bytecode.Function, error) {
+	if err := resolver.New(map[ast.Expr]int{}).Resolve(stmts); err != nil {
+		return nil, err
+	}
+
+	c := newCompiler(nil, kindScript, "")
+	if err := c.compileStmts(stmts); err != nil {
+		return nil, err
+	}
+	return c.end(), nil
+}
+
+func newCompiler(enclosing *Compiler, kind functionKind, name string) *Compiler {
+	c := &Compiler{
+		enclosing: enclosing,
+		function:  &bytecode.Function{Name: name},
+		kind:      kind,
+		constants: map[loxtype.Type]uint16{},
+	}
+	if enclosing != nil {
+		c.tok = enclosing.tok
+	}
+
+	// The first slot holds the function being called, or the receiver of a method.
+	receiver := ""
+	if kind == kindMethod || kind == kindInitializer {
+		receiver = "this"
+	}
+	c.locals = append(c.locals, local{name: receiver, depth: 0})
+	return c
+}
+
+func (c *Compiler) end() *bytecode.Function {
+	c.emitReturn()
+	c.function.UpvalueCount = len(c.upvalues)
+	return c.function
+}
+
+func (c *Compiler) compileStmts(stmts []ast.Stmt) error {
+	for _, stmt := range stmts {
+		if err := c.compileStmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileStmt(s ast.Stmt) error {
+	if _, err := s.Accept(nil, c); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Compiler) compileExpr(e ast.Expr) error {
+	if _, err := e.Accept(nil, c); err != nil {
+		return err
+	}
+	return nil
+}
+
+// at records tok as the source of the code emitted next.
+func (c *Compiler) at(tok *token.Token) {
+	c.tok = tok
+}
+
+func (c *Compiler) error(err error) error {
+	if c.tok == nil {
+		return &ierrors.Error{Err: err}
+	}
+	return ierrors.New(c.tok, err)
+}
+
+func (c *Compiler) position() bytecode.Position {
+	if c.tok == nil {
+		return bytecode.Position{}
+	}
+	return bytecode.PositionOf(c.tok)
+}
+
+func (c *Compiler) chunk() *bytecode.Chunk {
+	return &c.function.Chunk
+}
+
+func (c *Compiler) emit(op bytecode.OpCode, operands ...byte) {
+	pos := c.position()
+	c.chunk().Write(byte(op), pos)
+	for _, b := range operands {
+		c.chunk().Write(b, pos)
+	}
+}
+
+func (c *Compiler) emitUint16(op bytecode.OpCode, operand uint16, operands ...byte) {
+	c.emit(op, append([]byte{byte(operand >> 8), byte(operand)}, operands...)...)
+}
+
+func (c *Compiler) emitReturn() {
+	if c.kind == kindInitializer {
+		c.emit(bytecode.OpGetLocal, 0)
+	} else {
+		c.emit(bytecode.OpNil)
+	}
+	c.emit(bytecode.OpReturn)
+}
+
+// emitJump emits a jump with a placeholder operand and returns where the operand is, for patchJump.
+func (c *Compiler) emitJump(op bytecode.OpCode) int {
+	c.emitUint16(op, math.MaxUint16)
+	return len(c.chunk().Code) - 2
+}
+
+// patchJump points the jump whose operand is at offset to the next instruction to be emitted.
+func (c *Compiler) patchJump(offset int) error {
+	jump := len(c.chunk().Code) - offset - 2
+	if jump > math.MaxUint16 {
+		return c.error(ErrJumpTooLarge)
+	}
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+	return nil
+}
+
+func (c *Compiler) emitLoop(start int) error {
+	// The jump is taken from the end of the loop instruction, which is three bytes long.
+	jump := len(c.chunk().Code) - start + 3
+	if jump > math.MaxUint16 {
+		return c.error(ErrJumpTooLarge)
+	}
+	c.emitUint16(bytecode.OpLoop, uint16(jump))
+	return nil
+}
+
+// makeConstant adds a value to the constant pool, reusing the slot of an equal number or string.
+func (c *Compiler) makeConstant(value loxtype.Type) (uint16, error) {
+	_, reusable := value.(loxtype.Number)
+	if _, ok := value.(loxtype.String); ok {
+		reusable = true
+	}
+	if index, ok := c.constants[value]; ok && reusable {
+		return index, nil
+	}
+
+	index := c.chunk().AddConstant(value)
+	if index > math.MaxUint16 {
+		return 0, c.error(ErrTooManyConstants)
+	}
+	if reusable {
+		c.constants[value] = uint16(index)
+	}
+	return uint16(index), nil
+}
+
+func (c *Compiler) emitConstant(value loxtype.Type) error {
+	index, err := c.makeConstant(value)
+	if err != nil {
+		return err
+	}
+	c.emitUint16(bytecode.OpConstant, index)
+	return nil
+}
+
+func (c *Compiler) identifierConstant(name *token.Token) (uint16, error) {
+	return c.makeConstant(loxtype.String(name.Lexeme))
+}
+
+func (c *Compiler) beginScope() {
+	c.scopeDepth++
+}
+
+func (c *Compiler) endScope() {
+	c.scopeDepth--
+	for len(c.locals) > 0 && c.locals[len(c.locals)-1].depth > c.scopeDepth {
+		if c.locals[len(c.locals)-1].isCaptured {
+			c.emit(bytecode.OpCloseUpvalue)
+		} else {
+			c.emit(bytecode.OpPop)
+		}
+		c.locals = c.locals[:len(c.locals)-1]
+	}
+}
+
+// addHiddenLocal adds an initialized local for the value on top of the stack, which no name can refer to.
+func (c *Compiler) addHiddenLocal() error {
+	if err := c.addLocal(""); err != nil {
+		return err
+	}
+	c.locals[len(c.locals)-1].depth = c.scopeDepth
+	return nil
+}
+
+// dropHiddenLocal forgets the local added last without emitting code to pop it,
+// for when the code that follows leaves the function or throws.
+func (c *Compiler) dropHiddenLocal() {
+	c.locals = c.locals[:len(c.locals)-1]
+}
+
+func (c *Compiler) addLocal(name string) error {
+	if len(c.locals) == maxLocals {
+		return c.error(ErrTooManyLocals)
+	}
+	c.locals = append(c.locals, local{name: name, depth: -1})
+	return nil
+}
+
+// declareVariable adds a local for a declaration within a scope. Globals need no declaring.
+// The resolver has already rejected redeclarations.
+func (c *Compiler) declareVariable(name *token.Token) error {
+	if c.scopeDepth == 0 {
+		return nil
+	}
+	return c.addLocal(name.Lexeme)
+}
+
+// defineVariable makes a declared variable available, either by marking the local initialized
+// or by defining the global named by the constant at index with the value on top of the stack.
+func (c *Compiler) defineVariable(global uint16) {
+	if c.scopeDepth > 0 {
+		c.markInitialized()
+		return
+	}
+	c.emitUint16(bytecode.OpDefineGlobal, global)
+}
+
+func (c *Compiler) markInitialized() {
+	if c.scopeDepth == 0 {
+		return
+	}
+	c.locals[len(c.locals)-1].depth = c.scopeDepth
+}
+
+// globalConstant returns the constant naming a variable if it's declared in the global scope.
+func (c *Compiler) globalConstant(name *token.Token) (uint16, error) {
+	if c.scopeDepth > 0 {
+		return 0, nil
+	}
+	return c.identifierConstant(name)
+}
+
+func (c *Compiler) resolveLocal(name string) (int, bool) {
+	for i := len(c.locals) - 1; i >= 0; i-- {
+		if c.locals[i].name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (c *Compiler) resolveUpvalue(name string) (int, bool, error) {
+	if c.enclosing == nil {
+		return 0, false, nil
+	}
+
+	if index, ok := c.enclosing.resolveLocal(name); ok {
+		c.enclosing.locals[index].isCaptured = true
+		upvalue, err := c.addUpvalue(uint8(index), true)
+		return upvalue, true, err
+	}
+
+	index, ok, err := c.enclosing.resolveUpvalue(name)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	upvalue, err := c.addUpvalue(uint8(index), false)
+	return upvalue, true, err
+}
+
+func (c *Compiler) addUpvalue(index uint8, isLocal bool) (int, error) {
+	for i, uv := range c.upvalues {
+		if uv.index == index && uv.isLocal == isLocal {
+			return i, nil
+		}
+	}
+	if len(c.upvalues) == maxUpvalues {
+		return 0, c.error(ErrTooManyUpvalues)
+	}
+	c.upvalues = append(c.upvalues, upvalue{index: index, isLocal: isLocal})
+	return len(c.upvalues) - 1, nil
+}
+
+// namedVariable emits code to get the variable, or to set it to the value on top of the stack.
+func (c *Compiler) namedVariable(name *token.Token, set bool) error {
+	c.at(name)
+
+	if slot, ok := c.resolveLocal(name.Lexeme); ok {
+		c.emit(pick(set, bytecode.OpSetLocal, bytecode.OpGetLocal), byte(slot))
+		return nil
+	}
+
+	index, ok, err := c.resolveUpvalue(name.Lexeme)
+	if err != nil {
+		return err
+	}
+	if ok {
+		c.emit(pick(set, bytecode.OpSetUpvalue, bytecode.OpGetUpvalue), byte(index))
+		return nil
+	}
+
+	global, err := c.identifierConstant(name)
+	if err != nil {
+		return err
+	}
+	c.emitUint16(pick(set, bytecode.OpSetGlobal, bytecode.OpGetGlobal), global)
+	return nil
+}
+
+func pick(set bool, setter, getter bytecode.OpCode) bytecode.OpCode {
+	if set {
+		return setter
+	}
+	return getter
+}
+
+// compileFunction compiles the declaration of a function and emits the closure that wraps it.
+func (c *Compiler) compileFunction(s *ast.FunctionStmt, kind functionKind) error {
+	inner := newCompiler(c, kind, s.Name.Lexeme)
+	inner.beginScope()
+
+	for _, param := range s.Params {
+		inner.at(param)
+		inner.function.Arity++
+		if inner.function.Arity > maxArguments {
+			return inner.error(ErrTooManyParameters)
+		}
+		if err := inner.declareVariable(param); err != nil {
+			return err
+		}
+		inner.markInitialized()
+	}
+
+	if err := inner.compileStmts(s.Body); err != nil {
+		return err
+	}
+	fn := inner.end()
+
+	c.at(s.Name)
+	index, err := c.makeConstant(fn)
+	if err != nil {
+		return err
+	}
+	operands := make([]byte, 0, 2*len(inner.upvalues))
+	for _, uv := range inner.upvalues {
+		isLocal := byte(0)
+		if uv.isLocal {
+			isLocal = 1
+		}
+		operands = append(operands, isLocal, uv.index)
+	}
+	c.emitUint16(bytecode.OpClosure, index, operands...)
+	return nil
+}
+
+func (c *Compiler) method(s *ast.FunctionStmt) error {
+	name, err := c.identifierConstant(s.Name)
+	if err != nil {
+		return err
+	}
+
+	kind := kindMethod
+	if s.Name.Lexeme == "init" {
+		kind = kindInitializer
+	}
+	if err = c.compileFunction(s, kind); err != nil {
+		return err
+	}
+	c.emitUint16(bytecode.OpMethod, name)
+	return nil
+}
+
+func (c *Compiler) block(stmts []ast.Stmt) error {
+	c.beginScope()
+	if err := c.compileStmts(stmts); err != nil {
+		return err
+	}
+	c.endScope()
+	return nil
+}
+
+// exitTries emits what leaving the try statements being compiled takes, from the innermost out to the one at depth:
+// removing the handlers they installed, then running their finally clauses.
+func (c *Compiler) exitTries(depth int) error {
+	tries := c.tries
+	defer func() { c.tries = tries }()
+
+	for k := len(tries) - 1; k >= depth; k-- {
+		for range tries[k].handlers {
+			c.emit(bytecode.OpEndTry)
+		}
+		// A finally clause is only guarded by the statements around its own.
+		c.tries = tries[:k:k]
+		if tries[k].finally != nil {
+			if err := c.block(tries[k].finally); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exitLoop emits what leaving the body of the innermost loop takes: leaving the try statements within it
+// and popping its locals, which stay declared for the code that follows. It returns the jump that finishes the exit.
+func (c *Compiler) exitLoop() (*loop, int, error) {
+	l := c.loops[len(c.loops)-1]
+	if err := c.exitTries(l.tries); err != nil {
+		return nil, 0, err
+	}
+	for i := len(c.locals) - 1; i >= 0 && c.locals[i].depth > l.scopeDepth; i-- {
+		if c.locals[i].isCaptured {
+			c.emit(bytecode.OpCloseUpvalue)
+		} else {
+			c.emit(bytecode.OpPop)
+		}
+	}
+	return l, c.emitJump(bytecode.OpJump), nil
+}
+
+func (c *Compiler) arguments(paren *token.Token, args []ast.Expr) (byte, error) {
+	for _, arg := range args {
+		if err := c.compileExpr(arg); err != nil {
+			return 0, err
+		}
+	}
+	c.at(paren)
+	if len(args) > maxArguments {
+		return 0, c.error(ErrTooManyArguments)
+	}
+	return byte(len(args)), nil
+}
+
+func (c *Compiler) unexpected(node any) error {
+	return c.error(fmt.Errorf("unexpected node %T", node))
+}
@@ -0,0 +1,164 @@
+package compiler_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/compiler"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+func parse(t *testing.T, source string) []ast.Stmt {
+	t.Helper()
+	tokens, err := scanner.New(source).ScanTokens()
+	require.NoError(t, err)
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+	return stmts
+}
+
+func params(n int) string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "p" + strconv.Itoa(i)
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name      string
+		Source    string
+		Code      []bytecode.OpCode
+		Constants []loxtype.Type
+	}
+
+	op := func(ops ...bytecode.OpCode) []bytecode.OpCode { return ops }
+
+	tests := []Test{
+		{
+			Name:   "arithmetic",
+			Source: `print 1 + 2 * 1;`,
+			Code: op(
+				bytecode.OpConstant, 0, 0,
+				bytecode.OpConstant, 0, 1,
+				bytecode.OpConstant, 0, 0,
+				bytecode.OpMultiply,
+				bytecode.OpAdd,
+				bytecode.OpPrint,
+				bytecode.OpNil,
+				bytecode.OpReturn,
+			),
+			Constants: []loxtype.Type{loxtype.Number(1), loxtype.Number(2)},
+		},
+		{
+			Name:   "globals_and_locals",
+			Source: `var a = true; { var b = a; print !b; }`,
+			Code: op(
+				bytecode.OpTrue,
+				bytecode.OpDefineGlobal, 0, 0,
+				bytecode.OpGetGlobal, 0, 0,
+				bytecode.OpGetLocal, 1,
+				bytecode.OpNot,
+				bytecode.OpPrint,
+				bytecode.OpPop,
+				bytecode.OpNil,
+				bytecode.OpReturn,
+			),
+			Constants: []loxtype.Type{loxtype.String("a")},
+		},
+		{
+			Name:   "if_else",
+			Source: `if (nil) print 1; else print 2;`,
+			Code: op(
+				bytecode.OpNil,
+				bytecode.OpJumpIfFalse, 0, 8,
+				bytecode.OpPop,
+				bytecode.OpConstant, 0, 0,
+				bytecode.OpPrint,
+				bytecode.OpJump, 0, 5,
+				bytecode.OpPop,
+				bytecode.OpConstant, 0, 1,
+				bytecode.OpPrint,
+				bytecode.OpNil,
+				bytecode.OpReturn,
+			),
+			Constants: []loxtype.Type{loxtype.Number(1), loxtype.Number(2)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			fn, err := compiler.Compile(parse(t, test.Source))
+			require.NoError(t, err)
+
+			code := make([]bytecode.OpCode, len(fn.Chunk.Code))
+			for i, b := range fn.Chunk.Code {
+				code[i] = bytecode.OpCode(b)
+			}
+			assert.Equal(t, test.Code, code)
+			assert.Equal(t, test.Constants, fn.Chunk.Constants)
+			assert.Len(t, fn.Chunk.Positions, len(fn.Chunk.Code))
+		})
+	}
+}
+
+func TestCompile_errors(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name          string
+		Source        string
+		ExpectedError error
+	}
+
+	tests := []Test{
+		{
+			Name:          "too_many_arguments",
+			Source:        "f(" + strings.Repeat("1, ", 255) + "1);",
+			ExpectedError: compiler.ErrTooManyArguments,
+		},
+		{
+			Name:          "too_many_elements",
+			Source:        "print [" + strings.Repeat("nil, ", 65535) + "nil];",
+			ExpectedError: compiler.ErrTooManyElements,
+		},
+		{
+			Name:          "too_many_entries",
+			Source:        "print {" + strings.Repeat("nil: nil, ", 65535) + "nil: nil};",
+			ExpectedError: compiler.ErrTooManyEntries,
+		},
+		{
+			Name:          "too_many_parameters",
+			Source:        "fun f(" + params(256) + ") {}",
+			ExpectedError: compiler.ErrTooManyParameters,
+		},
+		{
+			Name:          "checked_by_resolver",
+			Source:        "print this;",
+			ExpectedError: resolver.ErrThisOutsideClass,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := compiler.Compile(parse(t, test.Source))
+			require.ErrorIs(t, err, test.ExpectedError)
+		})
+	}
+}
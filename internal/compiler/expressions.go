@@ -0,0 +1,292 @@
+//nolint:nilnil // The visitors return nothing but errors.
+package compiler
+
+import (
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/environment"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+func (c *Compiler) VisitAssignExpr(_ *environment.Environment, e *ast.AssignExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Value); err != nil {
+		return nil, err
+	}
+	if err := c.namedVariable(e.Name, true); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitBinaryExpr(_ *environment.Environment, e *ast.BinaryExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Left); err != nil {
+		return nil, err
+	}
+	if err := c.compileExpr(e.Right); err != nil {
+		return nil, err
+	}
+
+	c.at(e.Operator)
+	switch e.Operator.Type {
+	case token.TypeBangEqual:
+		c.emit(bytecode.OpEqual)
+		c.emit(bytecode.OpNot)
+	case token.TypeEqualEqual:
+		c.emit(bytecode.OpEqual)
+	case token.TypeGreater:
+		c.emit(bytecode.OpGreater)
+	case token.TypeGreaterEqual:
+		c.emit(bytecode.OpGreaterEqual)
+	case token.TypeLess:
+		c.emit(bytecode.OpLess)
+	case token.TypeLessEqual:
+		c.emit(bytecode.OpLessEqual)
+	case token.TypeMinus:
+		c.emit(bytecode.OpSubtract)
+	case token.TypePlus:
+		c.emit(bytecode.OpAdd)
+	case token.TypeSlash:
+		c.emit(bytecode.OpDivide)
+	case token.TypeStar:
+		c.emit(bytecode.OpMultiply)
+	default:
+		return nil, c.unexpected(e)
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitCallExpr(_ *environment.Environment, e *ast.CallExpr) (loxtype.Type, error) {
+	// Calls of methods are compiled to a single instruction rather than creating a bound method only to call it.
+	switch callee := e.Callee.(type) {
+	case *ast.GetExpr:
+		if err := c.compileExpr(callee.Object); err != nil {
+			return nil, err
+		}
+		name, err := c.identifierConstant(callee.Name)
+		if err != nil {
+			return nil, err
+		}
+		argc, err := c.arguments(e.Paren, e.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		c.emitUint16(bytecode.OpInvoke, name, argc)
+		return nil, nil
+
+	case *ast.SuperExpr:
+		if err := c.namedVariable(thisToken(callee.Keyword), false); err != nil {
+			return nil, err
+		}
+		name, err := c.identifierConstant(callee.Method)
+		if err != nil {
+			return nil, err
+		}
+		argc, err := c.arguments(e.Paren, e.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		if err = c.namedVariable(callee.Keyword, false); err != nil {
+			return nil, err
+		}
+		c.at(callee.Method)
+		c.emitUint16(bytecode.OpSuperInvoke, name, argc)
+		return nil, nil
+	}
+
+	if err := c.compileExpr(e.Callee); err != nil {
+		return nil, err
+	}
+	argc, err := c.arguments(e.Paren, e.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	c.emit(bytecode.OpCall, argc)
+	return nil, nil
+}
+
+func (c *Compiler) VisitGetExpr(_ *environment.Environment, e *ast.GetExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Object); err != nil {
+		return nil, err
+	}
+	c.at(e.Name)
+	name, err := c.identifierConstant(e.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.emitUint16(bytecode.OpGetProperty, name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitGroupingExpr(_ *environment.Environment, e *ast.GroupingExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Expression); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitIndexExpr(_ *environment.Environment, e *ast.IndexExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Object); err != nil {
+		return nil, err
+	}
+	if err := c.compileExpr(e.Index); err != nil {
+		return nil, err
+	}
+	c.at(e.Bracket)
+	c.emit(bytecode.OpGetIndex)
+	return nil, nil
+}
+
+func (c *Compiler) VisitListExpr(_ *environment.Environment, e *ast.ListExpr) (loxtype.Type, error) {
+	for _, element := range e.Elements {
+		if err := c.compileExpr(element); err != nil {
+			return nil, err
+		}
+	}
+	c.at(e.Bracket)
+	if len(e.Elements) > maxElements {
+		return nil, c.error(ErrTooManyElements)
+	}
+	c.emitUint16(bytecode.OpList, uint16(len(e.Elements)))
+	return nil, nil
+}
+
+func (c *Compiler) VisitLiteralExpr(_ *environment.Environment, e *ast.LiteralExpr) (loxtype.Type, error) {
+	// Literals the parser made up, such as the condition of "for (;;)", have no token.
+	if e.Token != nil {
+		c.at(e.Token)
+	}
+	switch value := e.Value.(type) {
+	case loxtype.Nil:
+		c.emit(bytecode.OpNil)
+	case loxtype.Boolean:
+		c.emit(pick(bool(value), bytecode.OpTrue, bytecode.OpFalse))
+	default:
+		if err := c.emitConstant(value); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitLogicalExpr(_ *environment.Environment, e *ast.LogicalExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Left); err != nil {
+		return nil, err
+	}
+
+	// The left operand is the result if it short-circuits; otherwise it's discarded for the right operand.
+	var endJump int
+	if e.Operator.Type == token.TypeOr {
+		elseJump := c.emitJump(bytecode.OpJumpIfFalse)
+		endJump = c.emitJump(bytecode.OpJump)
+		if err := c.patchJump(elseJump); err != nil {
+			return nil, err
+		}
+	} else {
+		endJump = c.emitJump(bytecode.OpJumpIfFalse)
+	}
+
+	c.emit(bytecode.OpPop)
+	if err := c.compileExpr(e.Right); err != nil {
+		return nil, err
+	}
+	if err := c.patchJump(endJump); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitMapExpr(_ *environment.Environment, e *ast.MapExpr) (loxtype.Type, error) {
+	for k := range e.Keys {
+		if err := c.compileExpr(e.Keys[k]); err != nil {
+			return nil, err
+		}
+		if err := c.compileExpr(e.Values[k]); err != nil {
+			return nil, err
+		}
+	}
+	c.at(e.Brace)
+	if len(e.Keys) > maxElements {
+		return nil, c.error(ErrTooManyEntries)
+	}
+	c.emitUint16(bytecode.OpMap, uint16(len(e.Keys)))
+	return nil, nil
+}
+
+func (c *Compiler) VisitSetExpr(_ *environment.Environment, e *ast.SetExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Object); err != nil {
+		return nil, err
+	}
+	if err := c.compileExpr(e.Value); err != nil {
+		return nil, err
+	}
+	c.at(e.Name)
+	name, err := c.identifierConstant(e.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.emitUint16(bytecode.OpSetProperty, name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitSetIndexExpr(_ *environment.Environment, e *ast.SetIndexExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Object); err != nil {
+		return nil, err
+	}
+	if err := c.compileExpr(e.Index); err != nil {
+		return nil, err
+	}
+	if err := c.compileExpr(e.Value); err != nil {
+		return nil, err
+	}
+	c.at(e.Bracket)
+	c.emit(bytecode.OpSetIndex)
+	return nil, nil
+}
+
+func (c *Compiler) VisitSuperExpr(_ *environment.Environment, e *ast.SuperExpr) (loxtype.Type, error) {
+	if err := c.namedVariable(thisToken(e.Keyword), false); err != nil {
+		return nil, err
+	}
+	if err := c.namedVariable(e.Keyword, false); err != nil {
+		return nil, err
+	}
+	c.at(e.Method)
+	name, err := c.identifierConstant(e.Method)
+	if err != nil {
+		return nil, err
+	}
+	c.emitUint16(bytecode.OpGetSuper, name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitThisExpr(_ *environment.Environment, e *ast.ThisExpr) (loxtype.Type, error) {
+	if err := c.namedVariable(e.Keyword, false); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitUnaryExpr(_ *environment.Environment, e *ast.UnaryExpr) (loxtype.Type, error) {
+	if err := c.compileExpr(e.Right); err != nil {
+		return nil, err
+	}
+
+	c.at(e.Operator)
+	switch e.Operator.Type {
+	case token.TypeBang:
+		c.emit(bytecode.OpNot)
+	case token.TypeMinus:
+		c.emit(bytecode.OpNegate)
+	default:
+		return nil, c.unexpected(e)
+	}
+	return nil, nil
+}
+
+func (c *Compiler) VisitVariableExpr(_ *environment.Environment, e *ast.VariableExpr) (loxtype.Type, error) {
+	if err := c.namedVariable(e.Name, false); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,374 @@
+package compiler
+
+import (
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/environment"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+func (c *Compiler) VisitBlockStmt(_ *environment.Environment, s *ast.BlockStmt) (ast.Completion, error) {
+	c.beginScope()
+	if err := c.compileStmts(s.Statements); err != nil {
+		return ast.Completion{}, err
+	}
+	c.endScope()
+	return ast.Completion{}, nil
+}
+
+// VisitBreakStmt compiles a jump to the exit of the loop. The resolver has already rejected breaking outside of one.
+func (c *Compiler) VisitBreakStmt(_ *environment.Environment, s *ast.BreakStmt) (ast.Completion, error) {
+	c.at(s.Keyword)
+	l, jump, err := c.exitLoop()
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	l.breaks = append(l.breaks, jump)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitClassStmt(_ *environment.Environment, s *ast.ClassStmt) (ast.Completion, error) {
+	c.at(s.Name)
+	name, err := c.identifierConstant(s.Name)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	if err = c.declareVariable(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	c.emitUint16(bytecode.OpClass, name)
+	c.defineVariable(name)
+
+	if s.Superclass != nil {
+		if err = c.namedVariable(s.Superclass.Name, false); err != nil {
+			return ast.Completion{}, err
+		}
+
+		// Methods capture the superclass from a scope of its own, just as the interpreter's environments do.
+		c.beginScope()
+		if err = c.addLocal("super"); err != nil {
+			return ast.Completion{}, err
+		}
+		c.markInitialized()
+
+		if err = c.namedVariable(s.Name, false); err != nil {
+			return ast.Completion{}, err
+		}
+		c.at(s.Superclass.Name)
+		c.emit(bytecode.OpInherit)
+	}
+
+	if err = c.namedVariable(s.Name, false); err != nil {
+		return ast.Completion{}, err
+	}
+	for _, m := range s.Methods {
+		if err = c.method(m); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	c.emit(bytecode.OpPop)
+
+	if s.Superclass != nil {
+		c.endScope()
+	}
+	return ast.Completion{}, nil
+}
+
+// VisitContinueStmt compiles a jump to the end of the loop's body, where its increment runs before its condition.
+func (c *Compiler) VisitContinueStmt(_ *environment.Environment, s *ast.ContinueStmt) (ast.Completion, error) {
+	c.at(s.Keyword)
+	l, jump, err := c.exitLoop()
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	l.continues = append(l.continues, jump)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitExpressionStmt(_ *environment.Environment, s *ast.ExpressionStmt) (ast.Completion, error) {
+	if err := c.compileExpr(s.Expression); err != nil {
+		return ast.Completion{}, err
+	}
+	c.emit(bytecode.OpPop)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitFunctionStmt(_ *environment.Environment, s *ast.FunctionStmt) (ast.Completion, error) {
+	c.at(s.Name)
+	global, err := c.globalConstant(s.Name)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	if err = c.declareVariable(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	// A local function may refer to itself, so it's initialized before its body is compiled.
+	c.markInitialized()
+
+	if err = c.compileFunction(s, kindFunction); err != nil {
+		return ast.Completion{}, err
+	}
+	c.defineVariable(global)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitIfStmt(_ *environment.Environment, s *ast.IfStmt) (ast.Completion, error) {
+	if err := c.compileExpr(s.Condition); err != nil {
+		return ast.Completion{}, err
+	}
+
+	thenJump := c.emitJump(bytecode.OpJumpIfFalse)
+	c.emit(bytecode.OpPop)
+	if err := c.compileStmt(s.ThenBranch); err != nil {
+		return ast.Completion{}, err
+	}
+	elseJump := c.emitJump(bytecode.OpJump)
+
+	if err := c.patchJump(thenJump); err != nil {
+		return ast.Completion{}, err
+	}
+	c.emit(bytecode.OpPop)
+	if s.ElseBranch != nil {
+		if err := c.compileStmt(s.ElseBranch); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	if err := c.patchJump(elseJump); err != nil {
+		return ast.Completion{}, err
+	}
+	return ast.Completion{}, nil
+}
+
+// VisitImportStmt binds the namespace of a module to a variable, as a var statement would.
+func (c *Compiler) VisitImportStmt(_ *environment.Environment, s *ast.ImportStmt) (ast.Completion, error) {
+	c.at(s.Name)
+	global, err := c.globalConstant(s.Name)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	if err = c.declareVariable(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+
+	c.at(s.Path)
+	path, ok := s.Path.Literal.(loxtype.String)
+	if !ok {
+		path = loxtype.String(s.Path.Lexeme)
+	}
+	constant, err := c.makeConstant(path)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	c.emitUint16(bytecode.OpImport, constant)
+
+	c.at(s.Name)
+	c.defineVariable(global)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitPrintStmt(_ *environment.Environment, s *ast.PrintStmt) (ast.Completion, error) {
+	if err := c.compileExpr(s.Expression); err != nil {
+		return ast.Completion{}, err
+	}
+	c.emit(bytecode.OpPrint)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitReturnStmt(_ *environment.Environment, s *ast.ReturnStmt) (ast.Completion, error) {
+	c.at(s.Keyword)
+	if s.Value == nil && len(c.tries) == 0 {
+		c.emitReturn()
+		return ast.Completion{}, nil
+	}
+
+	switch {
+	case s.Value != nil:
+		// The resolver has already rejected returning a value from an initializer.
+		if err := c.compileExpr(s.Value); err != nil {
+			return ast.Completion{}, err
+		}
+	case c.kind == kindInitializer:
+		c.emit(bytecode.OpGetLocal, 0)
+	default:
+		c.emit(bytecode.OpNil)
+	}
+
+	// The value waits in a local of its own while finally clauses run.
+	if len(c.tries) > 0 {
+		if err := c.addHiddenLocal(); err != nil {
+			return ast.Completion{}, err
+		}
+		if err := c.exitTries(0); err != nil {
+			return ast.Completion{}, err
+		}
+		c.dropHiddenLocal()
+	}
+
+	c.at(s.Keyword)
+	c.emit(bytecode.OpReturn)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitThrowStmt(_ *environment.Environment, s *ast.ThrowStmt) (ast.Completion, error) {
+	if err := c.compileExpr(s.Value); err != nil {
+		return ast.Completion{}, err
+	}
+	c.at(s.Keyword)
+	c.emit(bytecode.OpThrow)
+	return ast.Completion{}, nil
+}
+
+// VisitTryStmt compiles the statement's finally clause once for leaving it normally, once for leaving it by a thrown
+// error, which is rethrown after, and once more for every return within that leaves it, by [Compiler.exitTries].
+//
+//nolint:gocyclo,cyclop // Each clause is optional.
+func (c *Compiler) VisitTryStmt(_ *environment.Environment, s *ast.TryStmt) (ast.Completion, error) {
+	c.at(s.Keyword)
+	hasFinally := s.FinallyBody != nil
+	c.tries = append(c.tries, tryBlock{finally: s.FinallyBody, handlers: 1})
+	handler := c.emitJump(bytecode.OpTry)
+	if err := c.block(s.Body); err != nil {
+		return ast.Completion{}, err
+	}
+	c.at(s.Keyword)
+	c.emit(bytecode.OpEndTry)
+	c.tries[len(c.tries)-1].handlers = 0
+	exits := []int{c.emitJump(bytecode.OpJump)}
+
+	// Handlers start with the error pushed where the stack was when they were installed.
+	if err := c.patchJump(handler); err != nil {
+		return ast.Completion{}, err
+	}
+	if s.CatchName != nil {
+		if hasFinally {
+			handler = c.emitJump(bytecode.OpTry)
+			c.tries[len(c.tries)-1].handlers = 1
+		} else {
+			c.tries = c.tries[:len(c.tries)-1]
+		}
+
+		c.beginScope()
+		c.at(s.CatchName)
+		if err := c.addLocal(s.CatchName.Lexeme); err != nil {
+			return ast.Completion{}, err
+		}
+		c.markInitialized()
+		if err := c.compileStmts(s.CatchBody); err != nil {
+			return ast.Completion{}, err
+		}
+		c.endScope()
+
+		if hasFinally {
+			c.emit(bytecode.OpEndTry)
+		}
+		exits = append(exits, c.emitJump(bytecode.OpJump))
+		if hasFinally {
+			if err := c.patchJump(handler); err != nil {
+				return ast.Completion{}, err
+			}
+		}
+	}
+
+	if hasFinally {
+		c.tries = c.tries[:len(c.tries)-1]
+
+		c.beginScope()
+		if err := c.addHiddenLocal(); err != nil {
+			return ast.Completion{}, err
+		}
+		if err := c.block(s.FinallyBody); err != nil {
+			return ast.Completion{}, err
+		}
+		c.at(s.Keyword)
+		c.emit(bytecode.OpThrow)
+		c.dropHiddenLocal()
+		c.scopeDepth--
+	}
+
+	for _, exit := range exits {
+		if err := c.patchJump(exit); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	if hasFinally {
+		if err := c.block(s.FinallyBody); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitVarStmt(_ *environment.Environment, s *ast.VarStmt) (ast.Completion, error) {
+	c.at(s.Name)
+	global, err := c.globalConstant(s.Name)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+	if err = c.declareVariable(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+
+	if s.Initializer != nil {
+		if err = c.compileExpr(s.Initializer); err != nil {
+			return ast.Completion{}, err
+		}
+	} else {
+		c.emit(bytecode.OpNil)
+	}
+
+	c.at(s.Name)
+	c.defineVariable(global)
+	return ast.Completion{}, nil
+}
+
+func (c *Compiler) VisitWhileStmt(_ *environment.Environment, s *ast.WhileStmt) (ast.Completion, error) {
+	loopStart := len(c.chunk().Code)
+	if err := c.compileExpr(s.Condition); err != nil {
+		return ast.Completion{}, err
+	}
+
+	exitJump := c.emitJump(bytecode.OpJumpIfFalse)
+	c.emit(bytecode.OpPop)
+
+	l := &loop{scopeDepth: c.scopeDepth, tries: len(c.tries)}
+	c.loops = append(c.loops, l)
+	if err := c.compileStmt(s.Body); err != nil {
+		return ast.Completion{}, err
+	}
+	c.loops = c.loops[:len(c.loops)-1]
+
+	for _, jump := range l.continues {
+		if err := c.patchJump(jump); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	if s.Increment != nil {
+		if err := c.compileExpr(s.Increment); err != nil {
+			return ast.Completion{}, err
+		}
+		c.emit(bytecode.OpPop)
+	}
+	if err := c.emitLoop(loopStart); err != nil {
+		return ast.Completion{}, err
+	}
+
+	if err := c.patchJump(exitJump); err != nil {
+		return ast.Completion{}, err
+	}
+	c.emit(bytecode.OpPop)
+	for _, jump := range l.breaks {
+		if err := c.patchJump(jump); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{}, nil
+}
+
+// thisToken stands in for the receiver when a method call on super needs to load it.
+func thisToken(keyword *token.Token) *token.Token {
+	tok := *keyword
+	tok.Type = token.TypeThis
+	tok.Lexeme = "this"
+	return &tok
+}
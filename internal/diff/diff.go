@@ -0,0 +1,153 @@
+// Package diff compares texts line by line, for showing how a tool would change a file.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is how many unchanged lines are shown around each change.
+const context = 3
+
+// op is a line of a diff: kept in both texts (' '), deleted from the old (-), or inserted into the new (+).
+type op struct {
+	kind byte
+	line string
+	// old and new are the indices of the line in each text, or where it would go in the text it isn't in.
+	old, new int
+}
+
+// Unified returns the differences between two texts in unified format, naming them oldName and newName in its
+// header. It returns an empty string if the texts are the same.
+//
+// It finds a longest common subsequence of their lines by dynamic programming, in time and space proportional to
+// the product of their lengths outside any common prefix and suffix, which is plenty fast for source files.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := edits(lines(oldText), lines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		first := next(ops, start)
+		if first == len(ops) {
+			break
+		}
+		// A hunk runs from the context before its first change to the context after its last,
+		// taking in any later change whose context would overlap it.
+		last := first
+		for {
+			change := next(ops, last+1)
+			if change == len(ops) || change-last-1 > 2*context {
+				break
+			}
+			last = change
+		}
+		from, to := max(first-context, start), min(last+context+1, len(ops))
+		hunk(&b, ops[from:to])
+		start = to
+	}
+	return b.String()
+}
+
+// next finds the first change at or after index i.
+func next(ops []op, i int) int {
+	for i < len(ops) && ops[i].kind == ' ' {
+		i++
+	}
+	return i
+}
+
+// hunk writes a range of a diff under a header giving the lines it covers in each text.
+func hunk(b *strings.Builder, ops []op) {
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", span(ops[0].old, oldCount), span(ops[0].new, newCount))
+	for _, o := range ops {
+		b.WriteByte(o.kind)
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// span formats the lines a hunk covers in one text, given the index of its first. Lines are counted from one,
+// except that an empty range names the line just before it.
+func span(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// lines splits text into lines, keeping the newline that ends each one.
+func lines(text string) []string {
+	l := strings.SplitAfter(text, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+// edits lists the operations that turn a into b, keeping as many lines as possible.
+func edits(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	n, m := len(a)-prefix-suffix, len(b)-prefix-suffix
+
+	// kept[i][j] is the length of the longest common subsequence of the middles of a and b from i and j on.
+	kept := make([][]int, n+1)
+	for i := range kept {
+		kept[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[prefix+i] == b[prefix+j] {
+				kept[i][j] = kept[i+1][j+1] + 1
+			} else {
+				kept[i][j] = max(kept[i+1][j], kept[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+m)
+	for k := range prefix {
+		ops = append(ops, op{kind: ' ', line: a[k], old: k, new: k})
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		o := op{old: prefix + i, new: prefix + j}
+		switch {
+		case i < n && j < m && a[o.old] == b[o.new]:
+			o.kind, o.line = ' ', a[o.old]
+			i, j = i+1, j+1
+		case j == m || i < n && kept[i+1][j] >= kept[i][j+1]:
+			o.kind, o.line = '-', a[o.old]
+			i++
+		default:
+			o.kind, o.line = '+', b[o.new]
+			j++
+		}
+		ops = append(ops, o)
+	}
+	for k := range suffix {
+		ops = append(ops, op{kind: ' ', line: a[len(a)-suffix+k], old: len(a) - suffix + k, new: len(b) - suffix + k})
+	}
+	return ops
+}
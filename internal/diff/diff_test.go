@@ -0,0 +1,79 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/matt-hoiland/glox/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name     string
+		Old      string
+		New      string
+		Expected string
+	}
+
+	numbered := func(from, to int) string {
+		var b strings.Builder
+		for n := from; n <= to; n++ {
+			b.WriteString(strings.Repeat("x", n) + "\n")
+		}
+		return b.String()
+	}
+
+	tests := []Test{
+		{Name: "same", Old: "a\nb\n", New: "a\nb\n", Expected: ""},
+		{
+			Name:     "change",
+			Old:      "a\nb\nc\n",
+			New:      "a\nB\nc\n",
+			Expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			Name:     "insert_into_empty",
+			Old:      "",
+			New:      "a\n",
+			Expected: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			Name:     "delete_everything",
+			Old:      "a\nb\n",
+			New:      "",
+			Expected: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			Name:     "missing_final_newline",
+			Old:      "a\nb",
+			New:      "a\nb\n",
+			Expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			Name: "separate_hunks",
+			Old:  numbered(1, 12),
+			New:  "changed\n" + numbered(2, 11) + "changed\n",
+			Expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-x\n+changed\n xx\n xxx\n xxxx\n" +
+				"@@ -9,4 +9,4 @@\n xxxxxxxxx\n xxxxxxxxxx\n xxxxxxxxxxx\n-xxxxxxxxxxxx\n+changed\n",
+		},
+		{
+			Name: "overlapping_context_joins_hunks",
+			Old:  numbered(1, 8),
+			New:  "changed\n" + numbered(2, 7) + "changed\n",
+			Expected: "--- old\n+++ new\n" +
+				"@@ -1,8 +1,8 @@\n-x\n+changed\n xx\n xxx\n xxxx\n xxxxx\n xxxxxx\n xxxxxxx\n-xxxxxxxx\n+changed\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.Expected, diff.Unified("old", "new", test.Old, test.New))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package environment
 
 import (
+	"maps"
+	"slices"
+
 	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
 	"github.com/matt-hoiland/glox/internal/token"
@@ -59,6 +62,28 @@ func (e *Environment) GetAt(name *token.Token, distance int) (loxtype.Type, erro
 	return e.ancestor(distance).Get(name)
 }
 
+// Lookup returns the value bound to name in this environment itself, ignoring those that enclose it.
+func (e *Environment) Lookup(name string) (loxtype.Type, bool) {
+	value, ok := e.values[name]
+	return value, ok
+}
+
+// Names returns the names bound in this environment itself, ignoring those that enclose it, in sorted order.
+func (e *Environment) Names() []string {
+	return slices.Sorted(maps.Keys(e.values))
+}
+
+// Visible returns the names that can be looked up from this environment, bound in it or in those enclosing it,
+// in sorted order.
+func (e *Environment) Visible() []string {
+	var names []string
+	for env := e; env != nil; env = env.enclosing {
+		names = append(names, env.Names()...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
 func (e *Environment) MakeChild() *Environment {
 	child := New()
 	child.enclosing = e
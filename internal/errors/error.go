@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/matt-hoiland/glox/internal/token"
 )
@@ -10,6 +11,12 @@ type Error struct {
 	Line  int
 	Where string
 	Err   error
+
+	// Column, Offset, and Length locate the offending text within the source, see [token.Token].
+	// They are zero when the position is unknown.
+	Column int
+	Offset int
+	Length int
 }
 
 var (
@@ -19,9 +26,12 @@ var (
 
 func New(tok *token.Token, err error) *Error {
 	e := &Error{
-		Line:  tok.Line,
-		Where: " at '" + tok.Lexeme + "'",
-		Err:   err,
+		Line:   tok.Line,
+		Where:  " at '" + tok.Lexeme + "'",
+		Err:    err,
+		Column: tok.Column,
+		Offset: tok.Offset,
+		Length: tok.Length,
 	}
 	if tok.Type == token.TypeEOF {
 		e.Where = " at end"
@@ -36,3 +46,108 @@ func (err *Error) Error() string {
 func (err *Error) Unwrap() error {
 	return err.Err
 }
+
+// List collects every [Error] reported during a single pass over some source code.
+type List []*Error
+
+var (
+	_ error                         = List(nil)
+	_ interface{ Unwrap() []error } = List(nil)
+)
+
+// Err returns the list as an error, or nil if the list is empty.
+func (l List) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+func (l List) Error() string {
+	var bob strings.Builder
+	for i, err := range l {
+		if i > 0 {
+			bob.WriteRune('\n')
+		}
+		bob.WriteString(err.Error())
+	}
+	return bob.String()
+}
+
+func (l List) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, err := range l {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Frame is one call in a [Traceback].
+type Frame struct {
+	Function string
+	// Line is where execution was within the function, unless the function is native.
+	Line   int
+	Native bool
+	// File names the file the function was declared in, if it's known.
+	File string
+}
+
+func (f Frame) String() string {
+	switch {
+	case f.Native:
+		return "at " + f.Function + " (native)"
+	case f.File != "":
+		return fmt.Sprintf("at %s (%s:%d)", f.Function, f.File, f.Line)
+	default:
+		return fmt.Sprintf("at %s (line %d)", f.Function, f.Line)
+	}
+}
+
+// Traceback annotates a runtime error with the calls that were in progress when it happened.
+type Traceback struct {
+	Err error
+	// Frames lists the calls from the innermost outward.
+	Frames []Frame
+}
+
+var (
+	_ error                       = (*Traceback)(nil)
+	_ interface{ Unwrap() error } = (*Traceback)(nil)
+)
+
+func (tb *Traceback) Error() string {
+	var bob strings.Builder
+	bob.WriteString(tb.Err.Error())
+	for _, line := range frameLines(tb.Frames, Frame.String) {
+		bob.WriteString("\n  " + line)
+	}
+	return bob.String()
+}
+
+// minCollapsed is the fewest times a frame has to repeat in a row for its repeats to be counted rather than listed.
+const minCollapsed = 3
+
+// frameLines formats frames one per line. A run of the same frame, as unbounded recursion leaves behind,
+// is written once and followed by a count of the rest.
+func frameLines(frames []Frame, format func(Frame) string) []string {
+	lines := make([]string, 0, len(frames))
+	for k := 0; k < len(frames); {
+		run := 1
+		for k+run < len(frames) && frames[k+run] == frames[k] {
+			run++
+		}
+		if run < minCollapsed {
+			for _, f := range frames[k : k+run] {
+				lines = append(lines, format(f))
+			}
+		} else {
+			lines = append(lines, format(frames[k]), fmt.Sprintf("... repeated %d more times", run-1))
+		}
+		k += run
+	}
+	return lines
+}
+
+func (tb *Traceback) Unwrap() error {
+	return tb.Err
+}
@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,73 @@ func TestError_Error(t *testing.T) {
 	require.ErrorIs(t, err, assert.AnError)
 	assert.Equal(t, "[line 42] Errorblah: assert.AnError general error for testing", s)
 }
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		var list errors.List
+		require.NoError(t, list.Err())
+	})
+
+	t.Run("many", func(t *testing.T) {
+		t.Parallel()
+
+		first, second := stderrors.New("first"), stderrors.New("second")
+		list := errors.List{
+			{Line: 1, Err: first},
+			{Line: 3, Where: " at end", Err: second},
+		}
+
+		err := list.Err()
+		require.ErrorIs(t, err, first)
+		require.ErrorIs(t, err, second)
+		assert.Equal(t, "[line 1] Error: first\n[line 3] Error at end: second", err.Error())
+	})
+}
+
+func TestTraceback_Error(t *testing.T) {
+	t.Parallel()
+
+	var err error = &errors.Traceback{
+		Err: &errors.Error{Line: 2, Where: " at '-'", Err: assert.AnError},
+		Frames: []errors.Frame{
+			{Function: "<native fn: clock>", Native: true},
+			{Function: "greet", Line: 2},
+			{Function: "<script>", Line: 7},
+		},
+	}
+
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t,
+		"[line 2] Error at '-': assert.AnError general error for testing\n"+
+			"  at <native fn: clock> (native)\n"+
+			"  at greet (line 2)\n"+
+			"  at <script> (line 7)",
+		err.Error())
+}
+
+func TestTraceback_Error_collapsesRepeatedFrames(t *testing.T) {
+	t.Parallel()
+
+	f := errors.Frame{Function: "f", Line: 1}
+	var err error = &errors.Traceback{
+		Err: &errors.Error{Line: 1, Err: assert.AnError},
+		Frames: []errors.Frame{
+			f, f, f, f,
+			{Function: "g", Line: 2}, {Function: "g", Line: 2},
+			{Function: "<script>", Line: 3},
+		},
+	}
+
+	assert.Equal(t,
+		"[line 1] Error: assert.AnError general error for testing\n"+
+			"  at f (line 1)\n"+
+			"  ... repeated 3 more times\n"+
+			"  at g (line 2)\n"+
+			"  at g (line 2)\n"+
+			"  at <script> (line 3)",
+		err.Error())
+}
@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Renderer formats errors as diagnostics that quote the offending line of source and underline the offending text:
+//
+//	script.lox:3:7: error at 'foo': undefined variable: foo
+//	 3 | print foo;
+//	   |       ^~~
+type Renderer struct {
+	// Filename, if set, prefixes the location of every diagnostic.
+	Filename string
+	// Source is the text that the positions of the errors refer to.
+	Source string
+}
+
+// Render formats every error joined within err, one diagnostic after another.
+// Errors that carry no position are formatted on a single line,
+// and errors that carry a [Traceback] are followed by its frames.
+func (r Renderer) Render(err error) string {
+	var bob strings.Builder
+	for i, e := range flatten(err) {
+		if i > 0 {
+			bob.WriteRune('\n')
+		}
+		r.render(&bob, e)
+
+		var tb *Traceback
+		if errors.As(e, &tb) {
+			for _, line := range frameLines(tb.Frames, r.frame) {
+				bob.WriteString("\n  " + line)
+			}
+		}
+	}
+	return bob.String()
+}
+
+func (r Renderer) frame(f Frame) string {
+	if f.Native || f.File != "" || r.Filename == "" {
+		return f.String()
+	}
+	return fmt.Sprintf("at %s (%s:%d)", f.Function, r.Filename, f.Line)
+}
+
+func (r Renderer) render(bob *strings.Builder, err error) {
+	// An error raised within another file was given that file's source, which its position refers to.
+	for src := (*sourceError)(nil); errors.As(err, &src); {
+		r, err = src.renderer, src.err
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		if r.Filename != "" {
+			bob.WriteString(r.Filename + ": ")
+		}
+		bob.WriteString("error: " + err.Error())
+		return
+	}
+
+	location := strconv.Itoa(e.Line)
+	if e.Column > 0 {
+		location += ":" + strconv.Itoa(e.Column)
+	}
+	if r.Filename != "" {
+		location = r.Filename + ":" + location
+	}
+	fmt.Fprintf(bob, "%s: error%s: %s", location, e.Where, e.Err.Error())
+
+	if e.Column == 0 || r.Source == "" || e.Offset > len(r.Source) {
+		return
+	}
+
+	lineStart := strings.LastIndexByte(r.Source[:e.Offset], '\n') + 1
+	lineEnd := len(r.Source)
+	if i := strings.IndexByte(r.Source[e.Offset:], '\n'); i >= 0 {
+		lineEnd = e.Offset + i
+	}
+	text := strings.TrimSuffix(r.Source[lineStart:lineEnd], "\r")
+
+	// Tabs are kept so that the caret lines up with the quoted text however wide the terminal draws them.
+	var indent strings.Builder
+	for _, r := range r.Source[lineStart:e.Offset] {
+		if r == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteRune(' ')
+		}
+	}
+
+	// Text spanning several lines is only underlined up to the end of the first.
+	underlined := utf8.RuneCountInString(r.Source[e.Offset:min(e.Offset+e.Length, lineEnd)])
+
+	// Tokens spanning several lines report the line they end on, so the quoted line is numbered by counting.
+	gutter := strconv.Itoa(strings.Count(r.Source[:lineStart], "\n") + 1)
+	fmt.Fprintf(bob, "\n %s | %s", gutter, text)
+	fmt.Fprintf(bob, "\n %s | %s^%s", strings.Repeat(" ", len(gutter)), indent.String(),
+		strings.Repeat("~", max(underlined-1, 0)))
+}
+
+// flatten unpacks errors that were joined together, such as a [List] or the result of [errors.Join],
+// keeping the source that any of them were given.
+func flatten(err error) []error {
+	if src, ok := err.(*sourceError); ok {
+		var errs []error
+		for _, e := range flatten(src.err) {
+			errs = append(errs, &sourceError{renderer: src.renderer, err: e})
+		}
+		return errs
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flatten(e)...)
+	}
+	return errs
+}
+
+// WithSource wraps err so that its message is rendered as diagnostics against the given source.
+// Errors that err wraps which were given a source of their own are rendered against that instead.
+func WithSource(err error, filename, source string) error {
+	return &sourceError{
+		renderer: Renderer{Filename: filename, Source: source},
+		err:      err,
+	}
+}
+
+type sourceError struct {
+	renderer Renderer
+	err      error
+}
+
+var (
+	_ error                       = (*sourceError)(nil)
+	_ interface{ Unwrap() error } = (*sourceError)(nil)
+)
+
+func (e *sourceError) Error() string {
+	return e.renderer.Render(e.err)
+}
+
+func (e *sourceError) Unwrap() error {
+	return e.err
+}
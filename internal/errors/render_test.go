@@ -0,0 +1,138 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/errors"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name     string
+		Renderer errors.Renderer
+		Err      error
+		Output   string
+	}
+
+	source := "var a = 1;\n\tprint föö + a;\nprint \"multi\nline\";\n"
+
+	tests := []Test{
+		{
+			Name:     "with_filename",
+			Renderer: errors.Renderer{Filename: "script.lox", Source: source},
+			Err: &errors.Error{
+				Line: 1, Column: 5, Offset: 4, Length: 1,
+				Where: " at 'a'", Err: assert.AnError,
+			},
+			Output: "script.lox:1:5: error at 'a': assert.AnError general error for testing\n" +
+				" 1 | var a = 1;\n" +
+				"   |     ^",
+		},
+		{
+			Name:     "tabs_and_multibyte_runes",
+			Renderer: errors.Renderer{Source: source},
+			Err: &errors.Error{
+				Line: 2, Column: 8, Offset: 18, Length: 5,
+				Where: " at 'föö'", Err: assert.AnError,
+			},
+			Output: "2:8: error at 'föö': assert.AnError general error for testing\n" +
+				" 2 | \tprint föö + a;\n" +
+				"   | \t      ^~~",
+		},
+		{
+			Name:     "multiline_text",
+			Renderer: errors.Renderer{Source: source},
+			Err: &errors.Error{
+				Line: 4, Column: 7, Offset: 35, Length: 12,
+				Where: "", Err: assert.AnError,
+			},
+			Output: "4:7: error: assert.AnError general error for testing\n" +
+				" 3 | print \"multi\n" +
+				"   |       ^~~~~~",
+		},
+		{
+			Name:     "no_position",
+			Renderer: errors.Renderer{Filename: "script.lox", Source: source},
+			Err:      &errors.Error{Line: 3, Err: assert.AnError},
+			Output:   "script.lox:3: error: assert.AnError general error for testing",
+		},
+		{
+			Name:     "not_a_diagnostic",
+			Renderer: errors.Renderer{Filename: "script.lox", Source: source},
+			Err:      assert.AnError,
+			Output:   "script.lox: error: assert.AnError general error for testing",
+		},
+		{
+			Name:     "wrapped_diagnostic",
+			Renderer: errors.Renderer{Source: source},
+			Err: fmt.Errorf("context: %w", &errors.Error{
+				Line: 1, Column: 9, Offset: 8, Length: 1,
+				Where: " at '1'", Err: assert.AnError,
+			}),
+			Output: "1:9: error at '1': assert.AnError general error for testing\n" +
+				" 1 | var a = 1;\n" +
+				"   |         ^",
+		},
+		{
+			Name:     "joined_lists",
+			Renderer: errors.Renderer{Source: source},
+			Err: stderrors.Join(
+				errors.List{{Line: 1, Column: 1, Offset: 0, Length: 3, Err: assert.AnError}},
+				errors.List{{Line: 1, Err: assert.AnError}},
+			),
+			Output: "1:1: error: assert.AnError general error for testing\n" +
+				" 1 | var a = 1;\n" +
+				"   | ^~~\n" +
+				"1: error: assert.AnError general error for testing",
+		},
+		{
+			Name:     "traceback",
+			Renderer: errors.Renderer{Filename: "script.lox", Source: source},
+			Err: fmt.Errorf("context: %w", &errors.Traceback{
+				Err: &errors.Error{
+					Line: 1, Column: 5, Offset: 4, Length: 1,
+					Where: " at 'a'", Err: assert.AnError,
+				},
+				Frames: []errors.Frame{
+					{Function: "<native fn: clock>", Native: true},
+					{Function: "inner", Line: 1},
+					{Function: "<script>", Line: 3},
+				},
+			}),
+			Output: "script.lox:1:5: error at 'a': assert.AnError general error for testing\n" +
+				" 1 | var a = 1;\n" +
+				"   |     ^\n" +
+				"  at <native fn: clock> (native)\n" +
+				"  at inner (script.lox:1)\n" +
+				"  at <script> (script.lox:3)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.Output, test.Renderer.Render(test.Err))
+		})
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	inner := &errors.Error{Line: 1, Column: 1, Offset: 0, Length: 5, Err: assert.AnError}
+	err := errors.WithSource(inner, "hello.lox", "print 1;")
+
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t,
+		"hello.lox:1:1: error: assert.AnError general error for testing\n"+
+			" 1 | print 1;\n"+
+			"   | ^~~~~",
+		err.Error())
+}
@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// Runtime errors shared by every backend, so that a program fails the same way however it is run.
+var (
+	ErrType           = errors.New("type-error")
+	ErrNonBooleanType = fmt.Errorf("non-boolean %w", ErrType)
+	ErrNonNumericType = fmt.Errorf("non-numeric %w", ErrType)
+	ErrNonStringType  = fmt.Errorf("non-string %w", ErrType)
+	ErrNonListType    = fmt.Errorf("non-list %w", ErrType)
+	ErrNonMapType     = fmt.Errorf("non-map %w", ErrType)
+
+	ErrNotCallable   = errors.New("can only call functions and classes")
+	ErrArgumentCount = errors.New("wrong number of arguments")
+	ErrArgumentRange = errors.New("argument out of range")
+
+	ErrNotAnInstance      = errors.New("only instances, errors, and modules have properties")
+	ErrUndefinedProperty  = errors.New("undefined property")
+	ErrSuperclassNotClass = errors.New("superclass must be a class")
+
+	ErrNotIndexable    = errors.New("can only index lists and maps")
+	ErrIndexNotInteger = errors.New("index must be an integer")
+	ErrIndexOutOfRange = errors.New("index out of range")
+	ErrUnhashableKey   = errors.New("map keys must be strings, numbers other than NaN, booleans, or nil")
+	ErrUndefinedKey    = errors.New("undefined key")
+
+	ErrInvalidCodePoint = errors.New("invalid code point")
+
+	ErrThrown = errors.New("thrown")
+)
+
+// Errors for importing modules.
+var (
+	ErrModuleNotFound = errors.New("module not found")
+	ErrImportCycle    = errors.New("import cycle")
+	ErrModuleReadOnly = errors.New("module bindings are read-only")
+	ErrImportDenied   = errors.New("importing modules needs the file system capability")
+)
+
+// Errors for exceeding the limits that the host running a program sets. Programs can't catch them,
+// nor can they catch the host cancelling them, so that they can't keep running past their limits.
+var (
+	ErrStackOverflow = errors.New("stack overflow")
+	ErrStepLimit     = errors.New("step limit exceeded")
+	ErrHeapLimit     = errors.New("heap limit exceeded")
+)
+
+// ErrExit is what every [ExitError] is.
+var ErrExit = errors.New("exit")
+
+// uncatchable lists the errors that catch clauses don't receive and finally clauses don't run for.
+var uncatchable = []error{
+	ErrStackOverflow, ErrStepLimit, ErrHeapLimit, ErrExit, context.Canceled, context.DeadlineExceeded,
+}
+
+// ExitError is how a program exits. It unwinds the program uncaught, so that the host decides what exiting means.
+type ExitError struct {
+	Code int
+}
+
+var (
+	_ error                       = (*ExitError)(nil)
+	_ interface{ Unwrap() error } = (*ExitError)(nil)
+)
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (*ExitError) Unwrap() error {
+	return ErrExit
+}
+
+// Uncatchable reports whether err stops the program however it tries to handle errors.
+func Uncatchable(err error) bool {
+	for _, target := range uncatchable {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Thrown returns the error object for a value thrown at the given line. Thrown error objects are rethrown as they are,
+// and one that was caught should fail the program with its Origin, just as the error first did.
+func Thrown(value loxtype.Type, line int) *loxtype.Error {
+	if e, ok := value.(*loxtype.Error); ok {
+		return e
+	}
+	return &loxtype.Error{Message: value.String(), Line: line, Value: value, Err: ErrThrown}
+}
+
+// Catch returns the error object a catch clause receives for err,
+// or false if err isn't an error that programs can catch.
+func Catch(err error) (*loxtype.Error, bool) {
+	if Uncatchable(err) {
+		return nil, false
+	}
+	if thrown := (*loxtype.Error)(nil); errors.As(err, &thrown) {
+		if thrown.Origin == nil {
+			thrown.Origin = err
+		}
+		return thrown, true
+	}
+	if e := (*Error)(nil); errors.As(err, &e) {
+		message := e.Err.Error()
+		return &loxtype.Error{Message: message, Line: e.Line, Value: loxtype.String(message), Err: err, Origin: err}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,264 @@
+// Package format prints Lox programs in the one style glox accepts as canonical: statements one per line, indented
+// by two spaces within blocks, opening braces at the ends of lines, and spaces around binary operators and after
+// commas. Comments are kept where they were written, and so is a single blank line between statements.
+//
+// It prints the tokens of a program rather than its syntax tree, since the parser desugars for loops and forgets how
+// literals were written, but it parses them first, so that only valid programs are formatted.
+package format
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+const indentation = "  "
+
+// Source formats a program. If it isn't valid, Source reports the scanner's and parser's errors instead.
+func Source(source string) (string, error) {
+	tokens, scanErr := scanner.New(source, scanner.WithComments()).ScanTokens()
+	_, parseErr := parser.New(tokens).Parse()
+	if err := errors.Join(scanErr, parseErr); err != nil {
+		return "", err
+	}
+
+	p := &printer{source: source, lineStart: true}
+	for _, tok := range tokens {
+		p.print(tok)
+	}
+	return p.out.String(), nil
+}
+
+// bracket is the kind of an open bracket, which decides how what's between it and its match is laid out.
+type bracket int
+
+const (
+	paren bracket = iota
+	square
+	// block braces enclose statements, which go on lines of their own.
+	block
+	// mapBraces enclose the entries of a map literal, which stay on the line.
+	mapBraces
+)
+
+type printer struct {
+	source   string
+	out      strings.Builder
+	brackets []bracket
+
+	prev *token.Token
+	// prevUnary says that the previous token is a unary operator, and prevEndsBlock that it's the end of a block.
+	prevUnary     bool
+	prevEndsBlock bool
+
+	// lineStart says that nothing has been written on the current line, and blank that it follows a blank line.
+	lineStart bool
+	blank     bool
+	// continued says that the current line continues a statement broken up by a comment, so it's indented further.
+	continued bool
+}
+
+// print writes a token along with the comments before it, first breaking the line or spacing it from the previous
+// token as the style calls for.
+func (p *printer) print(tok *token.Token) {
+	structural := p.breaksBefore(tok)
+	if structural {
+		p.continued = false
+	}
+	// A comment that breaks a line within a statement leaves the rest of the statement to continue on the next.
+	within := !structural && p.prev != nil
+	// Blank lines are kept between statements, but not at the start or end of a block.
+	blanks, opening := structural || p.prev == nil, p.prevOpensBlock()
+
+	end, broken := p.prevEnd(), false
+	for _, c := range tok.Comments {
+		if p.prev != nil && !p.lineStart && !p.newlineBetween(end, c.Offset) {
+			p.write(" " + strings.TrimRight(c.Text, " \t\r"))
+		} else {
+			p.continued = p.continued || within
+			p.newline(blanks && !opening && p.blankBetween(end, c.Offset))
+			p.write(strings.TrimRight(c.Text, " \t\r"))
+		}
+		end, broken, opening = c.Offset+len(c.Text), true, false
+	}
+
+	closesBlock := tok.Type == token.TypeRightBrace && p.top() == block
+	if tok.Type == token.TypeRightBrace || tok.Type == token.TypeRightParen || tok.Type == token.TypeRightBracket {
+		p.brackets = p.brackets[:max(len(p.brackets)-1, 0)]
+	}
+
+	switch {
+	case tok.Type == token.TypeEOF:
+		p.newline(false)
+		return
+	case structural || broken:
+		p.continued = p.continued || within
+		p.newline(blanks && !opening && !closesBlock && p.blankBetween(end, tok.Offset))
+	case p.prev != nil && p.spaced(tok):
+		p.write(" ")
+	}
+	p.write(tok.Lexeme)
+
+	switch tok.Type {
+	case token.TypeLeftParen:
+		p.brackets = append(p.brackets, paren)
+	case token.TypeLeftBracket:
+		p.brackets = append(p.brackets, square)
+	case token.TypeLeftBrace:
+		if p.opensBlock() {
+			p.brackets = append(p.brackets, block)
+		} else {
+			p.brackets = append(p.brackets, mapBraces)
+		}
+	}
+	p.prevUnary = tok.Type == token.TypeBang || tok.Type == token.TypeMinus && !p.endsOperand()
+	p.prevEndsBlock = closesBlock
+	p.prev = tok
+}
+
+// breaksBefore says whether a token has to start a new line, because it begins a statement or ends a block.
+func (p *printer) breaksBefore(tok *token.Token) bool {
+	switch {
+	case p.prev == nil:
+		return false
+	case tok.Type == token.TypeEOF:
+		return true
+	case tok.Type == token.TypeRightBrace && p.top() == block:
+		// Empty blocks are closed on the line they're opened on, unless they hold comments.
+		return !p.prevOpensBlock() || len(tok.Comments) > 0
+	case p.prevOpensBlock():
+		return true
+	case p.prev.Type == token.TypeSemicolon:
+		// The clauses of a for loop are separated by semicolons too.
+		return p.top() != paren
+	case p.prevEndsBlock:
+		switch tok.Type {
+		case token.TypeElse, token.TypeCatch, token.TypeFinally:
+			return false
+		default:
+			return true
+		}
+	default:
+		return false
+	}
+}
+
+// opensBlock says whether a brace after the previous token encloses statements rather than the entries of a map.
+// Blocks follow the headers of statements that have them, or stand where a statement begins.
+func (p *printer) opensBlock() bool {
+	if p.prev == nil || p.prevEndsBlock || p.prevOpensBlock() {
+		return true
+	}
+	switch p.prev.Type {
+	case token.TypeRightParen, token.TypeIdentifier, token.TypeElse, token.TypeTry, token.TypeFinally:
+		return true
+	case token.TypeSemicolon:
+		return p.top() != paren
+	default:
+		return false
+	}
+}
+
+// spaced says whether a token on the same line as the previous one is set apart from it by a space.
+func (p *printer) spaced(tok *token.Token) bool {
+	switch p.prev.Type {
+	case token.TypeLeftParen, token.TypeLeftBracket, token.TypeLeftBrace, token.TypeDot:
+		return false
+	}
+	if p.prevUnary {
+		// Negating a negation needs the space to read as two minuses.
+		return p.prev.Type == token.TypeMinus && tok.Type == token.TypeMinus
+	}
+	switch tok.Type {
+	case token.TypeRightParen, token.TypeRightBracket, token.TypeRightBrace,
+		token.TypeComma, token.TypeSemicolon, token.TypeDot, token.TypeColon:
+		return false
+	case token.TypeLeftParen, token.TypeLeftBracket:
+		// Calls and indexes follow what they call or index directly.
+		return !p.endsOperand()
+	}
+	return true
+}
+
+// endsOperand says whether the previous token can end an operand, which makes a minus after it subtract
+// rather than negate.
+func (p *printer) endsOperand() bool {
+	if p.prev == nil {
+		return false
+	}
+	switch p.prev.Type {
+	case token.TypeIdentifier, token.TypeString, token.TypeNumber, token.TypeTrue, token.TypeFalse, token.TypeNil,
+		token.TypeThis, token.TypeRightParen, token.TypeRightBracket:
+		return true
+	case token.TypeRightBrace:
+		return !p.prevEndsBlock
+	default:
+		return false
+	}
+}
+
+func (p *printer) prevOpensBlock() bool {
+	return p.prev != nil && p.prev.Type == token.TypeLeftBrace && p.top() == block
+}
+
+func (p *printer) top() bracket {
+	if len(p.brackets) == 0 {
+		return block
+	}
+	return p.brackets[len(p.brackets)-1]
+}
+
+// prevEnd is the offset just past the previous token in the source.
+func (p *printer) prevEnd() int {
+	if p.prev == nil {
+		return 0
+	}
+	return p.prev.Offset + p.prev.Length
+}
+
+func (p *printer) newlineBetween(from, to int) bool {
+	return strings.Contains(p.source[from:to], "\n")
+}
+
+func (p *printer) blankBetween(from, to int) bool {
+	return strings.Count(p.source[from:to], "\n") > 1
+}
+
+// newline ends the current line, if anything is on it, and writes a blank line after it if asked to.
+func (p *printer) newline(blank bool) {
+	if p.out.Len() == 0 {
+		return
+	}
+	if !p.lineStart {
+		p.out.WriteByte('\n')
+		p.lineStart = true
+	}
+	if blank && !p.blank {
+		p.out.WriteByte('\n')
+		p.blank = true
+	}
+}
+
+// write writes text on the current line, indenting it first if it begins the line.
+func (p *printer) write(text string) {
+	if text == "" {
+		return
+	}
+	if p.lineStart {
+		depth := 0
+		for _, b := range p.brackets {
+			if b == block {
+				depth++
+			}
+		}
+		if p.continued {
+			depth++
+		}
+		p.out.WriteString(strings.Repeat(indentation, depth))
+	}
+	p.out.WriteString(text)
+	p.lineStart, p.blank = false, false
+}
@@ -0,0 +1,195 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/format"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name     string
+		Source   string
+		Expected string
+	}
+
+	tests := []Test{
+		{Name: "empty", Source: "", Expected: ""},
+		{Name: "final_newline", Source: "print 1;", Expected: "print 1;\n"},
+		{
+			Name:     "one_statement_per_line",
+			Source:   "var a=1;  print a;\r\n\r\n\r\n\r\nprint  a;",
+			Expected: "var a = 1;\nprint a;\n\nprint a;\n",
+		},
+		{
+			Name:     "operators",
+			Source:   "print -a-(-b)*!c>=d   and e or f!=g/h;",
+			Expected: "print -a - (-b) * !c >= d and e or f != g / h;\n",
+		},
+		{Name: "double_negation", Source: "print - -a;", Expected: "print - -a;\n"},
+		{
+			Name:     "calls_and_indexes",
+			Source:   "print f (a,b) [0] . c ( ) ;print [1,2][0];",
+			Expected: "print f(a, b)[0].c();\nprint [1, 2][0];\n",
+		},
+		{
+			Name:     "maps",
+			Source:   `var m = { "a" : { } , "b":[ ] };print ({"k":1})["k"];`,
+			Expected: "var m = {\"a\": {}, \"b\": []};\nprint ({\"k\": 1})[\"k\"];\n",
+		},
+		{
+			Name:   "blocks",
+			Source: "{var a;{}\n\n\n{print a;}\n}",
+			Expected: "{\n" +
+				"  var a;\n" +
+				"  {}\n" +
+				"\n" +
+				"  {\n" +
+				"    print a;\n" +
+				"  }\n" +
+				"}\n",
+		},
+		{
+			Name: "control_flow",
+			Source: "if(a){print 1;}else if(b)print 2;else{print 3;}" +
+				"while(a)a=a-1;for(var i=0;i<2;i=i+1){continue;}for(;;)break;",
+			Expected: "if (a) {\n" +
+				"  print 1;\n" +
+				"} else if (b) print 2;\n" +
+				"else {\n" +
+				"  print 3;\n" +
+				"}\n" +
+				"while (a) a = a - 1;\n" +
+				"for (var i = 0; i < 2; i = i + 1) {\n" +
+				"  continue;\n" +
+				"}\n" +
+				"for (;;) break;\n",
+		},
+		{
+			Name:   "declarations",
+			Source: "import \"lib.lox\"as lib;fun f(a,b){return;}class A<B{init(){super.init();this.x=1;}m(){}}",
+			Expected: "import \"lib.lox\" as lib;\n" +
+				"fun f(a, b) {\n" +
+				"  return;\n" +
+				"}\n" +
+				"class A < B {\n" +
+				"  init() {\n" +
+				"    super.init();\n" +
+				"    this.x = 1;\n" +
+				"  }\n" +
+				"  m() {}\n" +
+				"}\n",
+		},
+		{
+			Name:   "try",
+			Source: "try{throw \"x\";}catch(e){print e;}finally{}",
+			Expected: "try {\n" +
+				"  throw \"x\";\n" +
+				"} catch (e) {\n" +
+				"  print e;\n" +
+				"} finally {}\n",
+		},
+		{
+			Name: "comments",
+			Source: "// leading\n\n// more\nprint 1;   // trailing  \n" +
+				"fun f() { // opening\n  // inside\n\n  return; }\n" +
+				"fun g() {\n// only\n}\n" +
+				"print f(1, // first\n2);\n" +
+				"// last",
+			Expected: "// leading\n" +
+				"\n" +
+				"// more\n" +
+				"print 1; // trailing\n" +
+				"fun f() { // opening\n" +
+				"  // inside\n" +
+				"\n" +
+				"  return;\n" +
+				"}\n" +
+				"fun g() {\n" +
+				"  // only\n" +
+				"}\n" +
+				"print f(1, // first\n" +
+				"  2);\n" +
+				"// last\n",
+		},
+		{
+			Name:     "literals_as_written",
+			Source:   "print 1.50+\"two\nlines\";",
+			Expected: "print 1.50 + \"two\nlines\";\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			formatted, err := format.Source(test.Source)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, formatted)
+
+			again, err := format.Source(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, formatted, again, "formatting is idempotent")
+		})
+	}
+}
+
+func TestSource_invalid(t *testing.T) {
+	t.Parallel()
+
+	formatted, err := format.Source("print (1;\nvar = @;")
+	require.Error(t, err)
+	assert.Empty(t, formatted)
+	assert.Contains(t, err.Error(), "expect ')' after expression")
+	assert.Contains(t, err.Error(), "unexpected rune")
+}
+
+// TestSource_suite formats every valid program in the suite, checking that formatting changes nothing but layout.
+func TestSource_suite(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range loxtest.Cases() {
+		before, ok := tree(c.Source)
+		if !ok {
+			continue
+		}
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			formatted, err := format.Source(c.Source)
+			require.NoError(t, err)
+			after, ok := tree(formatted)
+			require.True(t, ok, formatted)
+			assert.Equal(t, before, after)
+
+			again, err := format.Source(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, formatted, again)
+		})
+	}
+}
+
+// tree prints the syntax tree of a program, if it parses.
+func tree(source string) (string, bool) {
+	tokens, err := scanner.New(source).ScanTokens()
+	if err != nil {
+		return "", false
+	}
+	stmts, err := parser.New(tokens).Parse()
+	if err != nil {
+		return "", false
+	}
+	lines := make([]string, len(stmts))
+	for k, stmt := range stmts {
+		lines[k] = ast.Print(stmt)
+	}
+	return strings.Join(lines, "\n"), true
+}
@@ -0,0 +1,100 @@
+package interpreter
+
+import (
+	"fmt"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+type loxClass struct {
+	name       string
+	superclass *loxClass
+	methods    map[string]*loxFunction
+}
+
+var _ callable = (*loxClass)(nil)
+
+func newClass(name string, superclass *loxClass, methods map[string]*loxFunction) *loxClass {
+	return &loxClass{
+		name:       name,
+		superclass: superclass,
+		methods:    methods,
+	}
+}
+
+func (c *loxClass) Arity() int {
+	if fn := c.findMethod("init"); fn != nil {
+		return fn.Arity()
+	}
+	return 0
+}
+
+func (c *loxClass) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxClass)
+	return loxtype.Boolean(ok && o == c)
+}
+
+func (*loxClass) IsTruthy() loxtype.Boolean { return true }
+func (c *loxClass) String() string          { return c.name }
+
+// Call instantiates the class, running its initializer if it has one.
+func (c *loxClass) Call(i *Interpreter, args []loxtype.Type) (loxtype.Type, error) {
+	instance := newInstance(c)
+	if fn := c.findMethod("init"); fn != nil {
+		if _, err := fn.bind(instance).Call(i, args); err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+// findMethod looks up a method on the class, walking the superclass chain if the class doesn't define it.
+func (c *loxClass) findMethod(name string) *loxFunction {
+	if m, ok := c.methods[name]; ok {
+		return m
+	}
+	if c.superclass != nil {
+		return c.superclass.findMethod(name)
+	}
+	return nil
+}
+
+type loxInstance struct {
+	class  *loxClass
+	fields map[string]loxtype.Type
+}
+
+var _ loxtype.Type = (*loxInstance)(nil)
+
+func newInstance(class *loxClass) *loxInstance {
+	return &loxInstance{
+		class:  class,
+		fields: map[string]loxtype.Type{},
+	}
+}
+
+func (inst *loxInstance) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxInstance)
+	return loxtype.Boolean(ok && o == inst)
+}
+
+func (*loxInstance) IsTruthy() loxtype.Boolean { return true }
+func (inst *loxInstance) String() string       { return inst.class.name + " instance" }
+
+// get looks up a property on the instance.
+// Fields shadow methods, and methods are bound to the instance before they are returned.
+func (inst *loxInstance) get(name *token.Token) (loxtype.Type, error) {
+	if value, ok := inst.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+	if m := inst.class.findMethod(name.Lexeme); m != nil {
+		return m.bind(inst), nil
+	}
+	return nil, ierrors.New(name, fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name.Lexeme))
+}
+
+func (inst *loxInstance) set(name *token.Token, value loxtype.Type) {
+	inst.fields[name.Lexeme] = value
+}
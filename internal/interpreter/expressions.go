@@ -1,17 +1,23 @@
 package interpreter
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/matt-hoiland/glox/internal/ast"
 	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
 	"github.com/matt-hoiland/glox/internal/token"
 )
 
 var _ ast.ExprVisitor = (*Interpreter)(nil)
 
 func (i *Interpreter) evaluate(env *environment.Environment, e ast.Expr) (loxtype.Type, error) {
+	if err := i.meter.Step(); err != nil {
+		return nil, err
+	}
 	return e.Accept(env, i)
 }
 
@@ -39,36 +45,41 @@ func (i *Interpreter) VisitBinaryExpr(env *environment.Environment, e *ast.Binar
 		return nil, fmt.Errorf("could not evaluate right operand of binary expression: %w", err)
 	}
 
+	var result loxtype.Type
 	switch e.Operator.Type {
 	case token.TypeBangEqual:
 		return !left.Equals(right), nil
 	case token.TypeEqualEqual:
 		return left.Equals(right), nil
 	case token.TypeGreater:
-		return i.biopGreater(left, right)
+		result, err = i.biopGreater(left, right)
 	case token.TypeGreaterEqual:
-		return i.biopGreaterEqual(left, right)
+		result, err = i.biopGreaterEqual(left, right)
 	case token.TypeLess:
-		return i.biopLess(left, right)
+		result, err = i.biopLess(left, right)
 	case token.TypeLessEqual:
-		return i.biopLessEqual(left, right)
+		result, err = i.biopLessEqual(left, right)
 	case token.TypeMinus:
-		return i.biopMinus(left, right)
+		result, err = i.biopMinus(left, right)
 	case token.TypeSlash:
-		return i.biopSlash(left, right)
+		result, err = i.biopSlash(left, right)
 	case token.TypeStar:
-		return i.biopStar(left, right)
+		result, err = i.biopStar(left, right)
 	case token.TypePlus:
-		return i.biopPlus(left, right)
+		result, err = i.biopPlus(left, right)
 	default:
-		return nil, ErrUnimplemented
+		err = ErrUnimplemented
+	}
+	if err != nil {
+		return nil, ierrors.New(e.Operator, err)
 	}
+	return result, nil
 }
 
 func (*Interpreter) biopGreater(left, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("greater expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("greater expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.Greater(b), nil
 }
@@ -76,7 +87,7 @@ func (*Interpreter) biopGreater(left, right loxtype.Type) (loxtype.Type, error)
 func (*Interpreter) biopGreaterEqual(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("greater-equal expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("greater-equal expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.GreaterEqual(b), nil
 }
@@ -84,7 +95,7 @@ func (*Interpreter) biopGreaterEqual(left loxtype.Type, right loxtype.Type) (lox
 func (*Interpreter) biopLess(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("less expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("less expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.Less(b), nil
 }
@@ -92,7 +103,7 @@ func (*Interpreter) biopLess(left loxtype.Type, right loxtype.Type) (loxtype.Typ
 func (*Interpreter) biopLessEqual(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("less-equal expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("less-equal expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.LessEqual(b), nil
 }
@@ -100,7 +111,7 @@ func (*Interpreter) biopLessEqual(left loxtype.Type, right loxtype.Type) (loxtyp
 func (*Interpreter) biopMinus(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("minus expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("minus expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.Subtract(b), nil
 }
@@ -108,7 +119,7 @@ func (*Interpreter) biopMinus(left loxtype.Type, right loxtype.Type) (loxtype.Ty
 func (*Interpreter) biopSlash(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("slash expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("slash expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.Divide(b), nil
 }
@@ -116,18 +127,21 @@ func (*Interpreter) biopSlash(left loxtype.Type, right loxtype.Type) (loxtype.Ty
 func (*Interpreter) biopStar(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	a, b, ok := convertBoth[loxtype.Number](left, right)
 	if !ok {
-		return nil, fmt.Errorf("star expression: %w", ErrNonNumericType)
+		return nil, fmt.Errorf("star expression: %w", ierrors.ErrNonNumericType)
 	}
 	return a.Multiply(b), nil
 }
 
-func (*Interpreter) biopPlus(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
+func (i *Interpreter) biopPlus(left loxtype.Type, right loxtype.Type) (loxtype.Type, error) {
 	na, nb, nok := convertBoth[loxtype.Number](left, right)
 	sa, sb, sok := convertBoth[loxtype.String](left, right)
 	if !nok && !sok {
-		return nil, fmt.Errorf("operands to plus expression must be either string or numeric: %w", ErrType)
+		return nil, fmt.Errorf("operands to plus expression must be either string or numeric: %w", ierrors.ErrType)
 	}
 	if !nok {
+		if err := i.meter.Allocate(len(sa) + len(sb)); err != nil {
+			return nil, err
+		}
 		return sa.Add(sb), nil
 	}
 	return na.Add(nb), nil
@@ -167,20 +181,90 @@ func (i *Interpreter) VisitCallExpr(env *environment.Environment, e *ast.CallExp
 	}
 
 	if function, ok = callee.(callable); !ok {
-		return nil, fmt.Errorf("%s not a callable: can only call functions and classes", function)
+		return nil, ierrors.New(e.Paren, fmt.Errorf("%w: %s", ierrors.ErrNotCallable, callee))
 	}
 
-	if len(arguments) != function.Arity() {
-		return nil, fmt.Errorf("expected %d arguments but got %d", function.Arity(), len(arguments))
+	if err = checkArity(function, len(arguments)); err != nil {
+		return nil, ierrors.New(e.Paren, err)
 	}
 
-	return function.Call(i, arguments)
+	if err = i.enter(function, e.Paren); err != nil {
+		return nil, ierrors.New(e.Paren, err)
+	}
+	result, err := function.Call(i, arguments)
+	if err != nil {
+		// Errors from native functions know nothing of the source, so they're placed at the call.
+		if !errors.As(err, new(*ierrors.Error)) {
+			err = ierrors.New(e.Paren, err)
+		}
+		err = i.traceback(err)
+	}
+	i.stack = i.stack[:len(i.stack)-1]
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (i *Interpreter) VisitGetExpr(env *environment.Environment, e *ast.GetExpr) (loxtype.Type, error) {
+	object, err := i.evaluate(env, e.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	if caught, ok := object.(*loxtype.Error); ok {
+		value, ok := caught.Get(e.Name.Lexeme)
+		if !ok {
+			return nil, ierrors.New(e.Name, fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, e.Name.Lexeme))
+		}
+		return value, nil
+	}
+
+	if ns, ok := object.(*namespace); ok {
+		return ns.get(e.Name)
+	}
+
+	instance, ok := object.(*loxInstance)
+	if !ok {
+		return nil, ierrors.New(e.Name, ierrors.ErrNotAnInstance)
+	}
+	return instance.get(e.Name)
 }
 
 func (i *Interpreter) VisitGroupingExpr(env *environment.Environment, e *ast.GroupingExpr) (loxtype.Type, error) {
 	return i.evaluate(env, e.Expression)
 }
 
+func (i *Interpreter) VisitIndexExpr(env *environment.Environment, e *ast.IndexExpr) (loxtype.Type, error) {
+	object, err := i.evaluate(env, e.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(env, e.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := native.GetIndex(object, index)
+	if err != nil {
+		return nil, ierrors.New(e.Bracket, err)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitListExpr(env *environment.Environment, e *ast.ListExpr) (loxtype.Type, error) {
+	elements := make([]loxtype.Type, len(e.Elements))
+	for k, expr := range e.Elements {
+		element, err := i.evaluate(env, expr)
+		if err != nil {
+			return nil, err
+		}
+		elements[k] = element
+	}
+	return &loxtype.List{Elements: elements}, nil
+}
+
 func (i *Interpreter) VisitLiteralExpr(_ *environment.Environment, e *ast.LiteralExpr) (loxtype.Type, error) {
 	return e.Value, nil
 }
@@ -204,6 +288,98 @@ func (i *Interpreter) VisitLogicalExpr(env *environment.Environment, e *ast.Logi
 	return i.evaluate(env, e.Right)
 }
 
+func (i *Interpreter) VisitMapExpr(env *environment.Environment, e *ast.MapExpr) (loxtype.Type, error) {
+	m := loxtype.NewMap()
+	for k := range e.Keys {
+		key, err := i.evaluate(env, e.Keys[k])
+		if err != nil {
+			return nil, err
+		}
+		value, err := i.evaluate(env, e.Values[k])
+		if err != nil {
+			return nil, err
+		}
+		if err = native.SetIndex(m, key, value); err != nil {
+			return nil, ierrors.New(e.Brace, err)
+		}
+	}
+	return m, nil
+}
+
+func (i *Interpreter) VisitSetExpr(env *environment.Environment, e *ast.SetExpr) (loxtype.Type, error) {
+	object, err := i.evaluate(env, e.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := object.(*namespace); ok {
+		return nil, ierrors.New(e.Name, ierrors.ErrModuleReadOnly)
+	}
+	instance, ok := object.(*loxInstance)
+	if !ok {
+		return nil, ierrors.New(e.Name, ierrors.ErrNotAnInstance)
+	}
+
+	value, err := i.evaluate(env, e.Value)
+	if err != nil {
+		return nil, err
+	}
+	instance.set(e.Name, value)
+	return value, nil
+}
+
+func (i *Interpreter) VisitSetIndexExpr(env *environment.Environment, e *ast.SetIndexExpr) (loxtype.Type, error) {
+	object, err := i.evaluate(env, e.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(env, e.Index)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluate(env, e.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = native.SetIndex(object, index, value); err != nil {
+		return nil, ierrors.New(e.Bracket, err)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitSuperExpr(env *environment.Environment, e *ast.SuperExpr) (loxtype.Type, error) {
+	distance := i.locals[e]
+
+	value, err := env.GetAt(superToken, distance)
+	if err != nil {
+		return nil, err
+	}
+	superclass, ok := value.(*loxClass)
+	if !ok {
+		return nil, ierrors.New(e.Keyword, ierrors.ErrSuperclassNotClass)
+	}
+
+	// "this" is always bound in the environment just inside the one holding "super".
+	if value, err = env.GetAt(thisToken, distance-1); err != nil {
+		return nil, err
+	}
+	instance, ok := value.(*loxInstance)
+	if !ok {
+		return nil, ierrors.New(e.Keyword, ierrors.ErrNotAnInstance)
+	}
+
+	m := superclass.findMethod(e.Method.Lexeme)
+	if m == nil {
+		return nil, ierrors.New(e.Method, fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, e.Method.Lexeme))
+	}
+	return m.bind(instance), nil
+}
+
+func (i *Interpreter) VisitThisExpr(env *environment.Environment, e *ast.ThisExpr) (loxtype.Type, error) {
+	return i.lookUpVariable(env, e.Keyword, e)
+}
+
 func (i *Interpreter) VisitUnaryExpr(env *environment.Environment, e *ast.UnaryExpr) (loxtype.Type, error) {
 	right, err := i.evaluate(env, e.Right)
 	if err != nil {
@@ -216,11 +392,11 @@ func (i *Interpreter) VisitUnaryExpr(env *environment.Environment, e *ast.UnaryE
 	case token.TypeMinus:
 		n, ok := right.(loxtype.Number)
 		if !ok {
-			return nil, fmt.Errorf("cannot apply minus operator: %w", ErrNonNumericType)
+			return nil, ierrors.New(e.Operator, fmt.Errorf("cannot apply minus operator: %w", ierrors.ErrNonNumericType))
 		}
 		return n.Negate(), nil
 	default:
-		return nil, ErrUnimplemented
+		return nil, ierrors.New(e.Operator, ErrUnimplemented)
 	}
 }
 
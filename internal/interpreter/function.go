@@ -1,100 +1,120 @@
 package interpreter
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/matt-hoiland/glox/internal/ast"
 	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
 )
 
-type functionType string
-
-const (
-	none     functionType = "NONE"
-	function functionType = "FUNCTION"
-)
-
-//nolint:errname // Intentional abuse of go's error system.
-type returnValue struct {
-	value loxtype.Type
-}
-
-var _ error = (*returnValue)(nil)
-
-func (r *returnValue) Error() string {
-	return "return value"
-}
-
-func (i *Interpreter) VisitReturnStmt(env *environment.Environment, s *ast.ReturnStmt) (loxtype.Type, error) {
-	var (
-		value loxtype.Type
-		err   error
-	)
-
-	if s.Value != nil {
-		if value, err = i.evaluate(env, s.Value); err != nil {
-			return nil, err
-		}
-	}
-
-	return nil, &returnValue{value}
-}
-
 type callable interface {
 	loxtype.Type
 	Arity() int
 	Call(*Interpreter, []loxtype.Type) (loxtype.Type, error)
 }
 
+// checkArity returns [ierrors.ErrArgumentCount] unless the function can be called with argc arguments.
+// Native functions may leave arguments out.
+func checkArity(function callable, argc int) error {
+	if nf, ok := function.(*nativeFunction); ok {
+		return nf.fn.CheckArity(argc)
+	}
+	if argc != function.Arity() {
+		return fmt.Errorf("%w: expected %d but got %d", ierrors.ErrArgumentCount, function.Arity(), argc)
+	}
+	return nil
+}
+
 type loxFunction struct {
-	closure *environment.Environment
-	stmt    *ast.FunctionStmt
+	closure       *environment.Environment
+	stmt          *ast.FunctionStmt
+	isInitializer bool
+	// module is where the function was declared.
+	module *module
 }
 
 var _ callable = (*loxFunction)(nil)
 
-func newFunction(env *environment.Environment, stmt *ast.FunctionStmt) *loxFunction {
+func newFunction(env *environment.Environment, stmt *ast.FunctionStmt, isInitializer bool, m *module) *loxFunction {
 	return &loxFunction{
-		closure: env,
-		stmt:    stmt,
+		closure:       env,
+		stmt:          stmt,
+		isInitializer: isInitializer,
+		module:        m,
 	}
 }
 
-func (f *loxFunction) Arity() int                        { return len(f.stmt.Params) }
-func (*loxFunction) Equals(loxtype.Type) loxtype.Boolean { return false }
-func (*loxFunction) IsTruthy() loxtype.Boolean           { return true }
-func (f *loxFunction) String() string                    { return fmt.Sprintf("<fn: %s>", f.stmt.Name.Lexeme) }
+// bind returns a copy of the method whose closure defines "this" as the given instance.
+func (f *loxFunction) bind(instance *loxInstance) *loxFunction {
+	env := f.closure.MakeChild()
+	env.Define(thisToken, instance)
+	return newFunction(env, f.stmt, f.isInitializer, f.module)
+}
+
+func (f *loxFunction) Arity() int              { return len(f.stmt.Params) }
+func (*loxFunction) IsTruthy() loxtype.Boolean { return true }
+func (f *loxFunction) String() string          { return fmt.Sprintf("<fn: %s>", f.stmt.Name.Lexeme) }
+
+// Equals compares functions by identity.
+// Methods are bound afresh each time they're accessed, so two accesses of the same method are not equal.
+func (f *loxFunction) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxFunction)
+	return loxtype.Boolean(ok && o == f)
+}
 
 func (f *loxFunction) Call(i *Interpreter, args []loxtype.Type) (loxtype.Type, error) {
 	env := f.closure.MakeChild()
 	for i, param := range f.stmt.Params {
 		env.Define(param, args[i])
 	}
-	var val *returnValue
-	if err := i.executeBlock(env, f.stmt.Body); err != nil && !errors.As(err, &val) {
+	caller := i.module
+	i.module = f.module
+	c, err := i.executeBlock(env, f.stmt.Body)
+	i.module = caller
+	if err = unwind(c, err); err != nil {
+		if f.module != caller {
+			err = f.module.withSource(err)
+		}
 		return nil, err
 	}
-	if val == nil || val.value == nil {
-		return loxtype.Nil{}, nil
+	if f.isInitializer {
+		return f.closure.GetAt(thisToken, 0)
+	}
+	if c.Kind == ast.CompletionReturn {
+		return c.Value, nil
 	}
-	return val.value, nil
+	return loxtype.Nil{}, nil
 }
 
+// nativeFunction adapts a built-in function to the interpreter's calling convention.
 type nativeFunction struct {
-	name  string
-	arity int
-	impl  func(*Interpreter, []loxtype.Type) (loxtype.Type, error)
+	fn *native.Function
 }
 
 var _ callable = (*nativeFunction)(nil)
 
-func (nf *nativeFunction) Arity() int                       { return nf.arity }
-func (*nativeFunction) Equals(loxtype.Type) loxtype.Boolean { return false }
-func (*nativeFunction) IsTruthy() loxtype.Boolean           { return true }
-func (nf *nativeFunction) String() string                   { return fmt.Sprintf("<native fn: %s>", nf.name) }
+func (nf *nativeFunction) Arity() int { return nf.fn.Arity }
+
+func (nf *nativeFunction) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*nativeFunction)
+	return loxtype.Boolean(ok && o.fn == nf.fn)
+}
+
+func (*nativeFunction) IsTruthy() loxtype.Boolean { return true }
+func (nf *nativeFunction) String() string         { return nf.fn.String() }
 
 func (nf *nativeFunction) Call(i *Interpreter, args []loxtype.Type) (loxtype.Type, error) {
-	return nf.impl(i, args)
+	result, err := nf.fn.Call(args)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := result.(loxtype.String); ok {
+		if err = i.meter.Allocate(len(s)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,192 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+// Eval runs code in the global scope, where what it declares remains for the code run after it,
+// as the lines of the REPL do. If the last statement is an expression, Eval returns its value, and otherwise nil.
+func (i *Interpreter) Eval(code string) (loxtype.Type, error) {
+	return i.EvalContext(context.Background(), code)
+}
+
+// EvalContext is [Interpreter.Eval], stopping the code with the context's error if the context is cancelled.
+func (i *Interpreter) EvalContext(ctx context.Context, code string) (loxtype.Type, error) {
+	tokens, scanErr := scanner.New(code).ScanTokens()
+	stmts, parseErr := parser.New(tokens, parser.InREPLMode()).Parse()
+	if err := errors.Join(scanErr, parseErr); err != nil {
+		return nil, err
+	}
+	if err := resolver.New(i.locals).Resolve(stmts); err != nil {
+		return nil, err
+	}
+
+	var last *ast.ExpressionStmt
+	if n := len(stmts); n > 0 {
+		if last, _ = stmts[n-1].(*ast.ExpressionStmt); last != nil {
+			stmts = stmts[:n-1]
+		}
+	}
+	var value loxtype.Type = loxtype.Nil{}
+	err := i.metered(ctx, func() error {
+		if runErr := i.interpret(i.globals, stmts); runErr != nil || last == nil {
+			return runErr
+		}
+		result, evalErr := i.evaluate(i.globals, last.Expression)
+		value = result
+		return evalErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Define binds a global variable, which every script the interpreter runs can see.
+// Native functions are wrapped so that scripts can call them.
+func (i *Interpreter) Define(name string, value loxtype.Type) {
+	if fn, ok := value.(*native.Function); ok {
+		value = &nativeFunction{fn: fn}
+	}
+	i.globals.Define(&token.Token{Type: token.TypeIdentifier, Lexeme: name}, value)
+}
+
+// Global returns the value of a global variable, as defined by [Interpreter.Define] or [Interpreter.Eval].
+func (i *Interpreter) Global(name string) (loxtype.Type, error) {
+	value, ok := i.globals.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", environment.ErrUndefinedVariable, name)
+	}
+	if fn, ok := value.(*nativeFunction); ok {
+		return fn.fn, nil
+	}
+	return value, nil
+}
+
+// Globals returns the names of the global variables, in sorted order.
+func (i *Interpreter) Globals() []string {
+	return i.globals.Visible()
+}
+
+// Properties returns the names of the properties a value has, which can follow it after a dot, in sorted order.
+func Properties(value loxtype.Type) []string {
+	var names []string
+	switch v := value.(type) {
+	case *loxInstance:
+		names = slices.Collect(maps.Keys(v.fields))
+		for c := v.class; c != nil; c = c.superclass {
+			names = slices.AppendSeq(names, maps.Keys(c.methods))
+		}
+	case *namespace:
+		names = v.module.env.Names()
+	case *loxtype.Error:
+		names = []string{"line", "message", "value"}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// LoadContext runs a file in the global scope, as [Interpreter.EvalContext] runs code, so that what it declares
+// remains for the code run after it. Imports within the file are found relative to it, and its errors,
+// including those raised later by the functions it declares, are reported against its source.
+//
+// The file is read through the host's file system, as imports are, and so needs the file system capability.
+func (i *Interpreter) LoadContext(ctx context.Context, filename string) error {
+	if i.capabilities&native.CapabilityFS == 0 {
+		return ierrors.ErrImportDenied
+	}
+	source, err := fs.ReadFile(i.host.FS, filename)
+	if err != nil {
+		return err
+	}
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	m := &module{name: filename, path: path, source: string(source), env: i.globals}
+	if err = i.metered(ctx, func() error { return i.load(m, nil) }); err != nil {
+		return m.withSource(err)
+	}
+	return nil
+}
+
+// Call calls a function or class, as a call expression in a script would,
+// stopping it with the context's error if the context is cancelled.
+func (i *Interpreter) Call(ctx context.Context, callee loxtype.Type, args []loxtype.Type) (loxtype.Type, error) {
+	if fn, ok := callee.(*native.Function); ok {
+		callee = &nativeFunction{fn: fn}
+	}
+	function, ok := callee.(callable)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ierrors.ErrNotCallable, callee)
+	}
+	if err := checkArity(function, len(args)); err != nil {
+		return nil, err
+	}
+
+	var result loxtype.Type
+	err := i.metered(ctx, func() error {
+		if enterErr := i.enter(function, nil); enterErr != nil {
+			return enterErr
+		}
+		value, callErr := function.Call(i, args)
+		if callErr != nil {
+			callErr = i.traceback(callErr)
+		}
+		i.stack = i.stack[:len(i.stack)-1]
+		result = value
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TypeName names the type of a value, as the interpreter's values are described to a script's programmer.
+func TypeName(value loxtype.Type) string {
+	switch v := value.(type) {
+	case loxtype.Nil:
+		return "nil"
+	case loxtype.Boolean:
+		return "boolean"
+	case loxtype.Number:
+		return "number"
+	case loxtype.String:
+		return "string"
+	case *loxtype.List:
+		return "list"
+	case *loxtype.Map:
+		return "map"
+	case *loxtype.Error:
+		return "error"
+	case *loxFunction:
+		return "function"
+	case *nativeFunction, *native.Function:
+		return "native function"
+	case *loxClass:
+		return "class"
+	case *loxInstance:
+		return "instance of " + v.class.name
+	case *namespace:
+		return "module"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -1,123 +1,233 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"io"
-	"os"
-	"time"
+	"path/filepath"
 
 	"github.com/matt-hoiland/glox/internal/ast"
 	"github.com/matt-hoiland/glox/internal/environment"
+	"github.com/matt-hoiland/glox/internal/limits"
 	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
 	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
 	"github.com/matt-hoiland/glox/internal/scanner"
 	"github.com/matt-hoiland/glox/internal/token"
 )
 
-var (
-	ErrUnimplemented = errors.New("unimplemented")
+var ErrUnimplemented = errors.New("unimplemented")
 
-	ErrType           = errors.New("type-error")
-	ErrNonBooleanType = fmt.Errorf("non-boolean %w", ErrType)
-	ErrNonNumericType = fmt.Errorf("non-numeric %w", ErrType)
-	ErrNonStringType  = fmt.Errorf("non-string %w", ErrType)
+var (
+	superToken = &token.Token{
+		Type:   token.TypeSuper,
+		Lexeme: "super",
+	}
+	thisToken = &token.Token{
+		Type:   token.TypeThis,
+		Lexeme: "this",
+	}
 )
 
 type Interpreter struct {
 	w       io.Writer
 	globals *environment.Environment
 	locals  map[ast.Expr]int
+	stack   []frame
+
+	capabilities native.Capability
+	host         native.Host
+	limits       limits.Limits
+	// meter tracks the current run against the limits.
+	meter *limits.Meter
+
+	scriptPath string
+	searchPath []string
+	// modules holds every module loaded or loading, by absolute path, and importing lists those loading.
+	modules   map[string]*module
+	importing []*module
+	// module is the module whose code is running, or nil for code given to Interpret.
+	module *module
+}
+
+// Option configures an [Interpreter].
+type Option func(*Interpreter)
+
+// WithScriptPath names the file that holds the code given to [Interpreter.Run],
+// which modules it imports are found relative to. Without it, they're found relative to the working directory.
+func WithScriptPath(path string) Option {
+	return func(i *Interpreter) {
+		i.scriptPath = path
+	}
+}
+
+// WithSearchPath adds directories to look for imported modules in
+// when they aren't found relative to the file that imports them.
+func WithSearchPath(dirs ...string) Option {
+	return func(i *Interpreter) {
+		i.searchPath = append(i.searchPath, dirs...)
+	}
+}
+
+// WithCapabilities grants programs the built-in functions of the capabilities given, beyond those that only work
+// on values. Without it, programs can't reach the host at all.
+func WithCapabilities(granted native.Capability) Option {
+	return func(i *Interpreter) {
+		i.capabilities |= granted
+	}
 }
 
-func New(w io.Writer) *Interpreter {
+// WithHost sets what the built-in functions of the I/O and file system capabilities and imports reach the host
+// through, such as a file system in memory for tests. The output defaults to the writer that print statements write to.
+func WithHost(host native.Host) Option {
+	return func(i *Interpreter) {
+		i.host = host
+	}
+}
+
+// WithMaxSteps stops programs with [ierrors.ErrStepLimit] once they've executed n statements and expressions.
+func WithMaxSteps(n int) Option {
+	return func(i *Interpreter) {
+		i.limits.Steps = n
+	}
+}
+
+// WithMaxCallDepth stops programs with [ierrors.ErrStackOverflow] when they'd have more than n calls in progress,
+// rather than [limits.DefaultCallDepth].
+func WithMaxCallDepth(n int) Option {
+	return func(i *Interpreter) {
+		i.limits.CallDepth = n
+	}
+}
+
+// WithMaxHeap stops programs with [ierrors.ErrHeapLimit] once the Go heap holds more than n bytes.
+func WithMaxHeap(n uint64) Option {
+	return func(i *Interpreter) {
+		i.limits.Heap = n
+	}
+}
+
+func New(w io.Writer, opts ...Option) *Interpreter {
 	env := &Interpreter{
 		w:       w,
 		globals: environment.New(),
 		locals:  map[ast.Expr]int{},
+		modules: map[string]*module{},
 	}
+	for _, opt := range opts {
+		opt(env)
+	}
+	env.meter = limits.NewMeter(context.Background(), env.limits)
 
-	env.globals.Define(
-		&token.Token{
-			Type:   token.TypeIdentifier,
-			Lexeme: "clock",
-		},
-		&nativeFunction{
-			name:  "clock",
-			arity: 0,
-			impl: func(*Interpreter, []loxtype.Type) (loxtype.Type, error) {
-				return loxtype.Number(time.Now().UnixMilli()), nil
-			},
-		},
-	)
-
-	env.globals.Define(
-		&token.Token{
-			Type:   token.TypeIdentifier,
-			Lexeme: "exit",
-		},
-		&nativeFunction{
-			name:  "exit",
-			arity: 0,
-			impl: func(*Interpreter, []loxtype.Type) (loxtype.Type, error) {
-				os.Exit(0)
-				return nil, nil //nolint: nilnil // native function
-			},
-		},
-	)
+	if env.host.Stdout == nil {
+		env.host.Stdout = w
+	}
+	// Modules are read through the file system too, so it's needed whether or not the functions are granted.
+	if env.host.FS == nil {
+		env.host.FS = native.OSFS{}
+	}
+	for _, fn := range native.Functions(env.capabilities, env.host) {
+		env.Define(fn.Name, fn)
+	}
+	for _, c := range native.Constants() {
+		env.Define(c.Name, c.Value)
+	}
 
 	return env
 }
 
 func (i *Interpreter) Run(code string) error {
+	return i.RunContext(context.Background(), code)
+}
+
+// RunContext runs a script, stopping it with the context's error if the context is cancelled before it finishes.
+func (i *Interpreter) RunContext(ctx context.Context, code string) error {
 	var (
-		env    = i.globals.MakeChild()
-		tokens []*token.Token
-		stmts  []ast.Stmt
-		err    error
+		env = i.globals.MakeChild()
+		err error
 	)
 
-	if tokens, err = scanner.New(code).ScanTokens(); err != nil {
-		return err
-	}
-
-	if stmts, err = parser.New(tokens).Parse(); err != nil {
+	// Parse even if scanning failed so that syntax errors are reported alongside the scanning errors.
+	tokens, scanErr := scanner.New(code).ScanTokens()
+	stmts, parseErr := parser.New(tokens).Parse()
+	if err = errors.Join(scanErr, parseErr); err != nil {
 		return err
 	}
 
-	if err = newResolver(i).resolveStmts(stmts); err != nil {
+	if err = resolver.New(i.locals).Resolve(stmts); err != nil {
 		return err
 	}
 
-	if err = i.interpret(env, stmts); err != nil {
-		return err
+	// The script is a module too, so that its errors keep its source when they pass through other modules,
+	// and so that importing it is a cycle.
+	script := &module{name: i.scriptPath, source: code, env: env}
+	if i.scriptPath != "" {
+		if script.path, err = filepath.Abs(i.scriptPath); err != nil {
+			return err
+		}
+		i.modules[script.path] = script
 	}
-	return nil
+	i.importing = append(i.importing, script)
+	i.module = script
+	defer func() {
+		script.loaded = true
+		i.module = nil
+		i.importing = i.importing[:0]
+	}()
+
+	return i.metered(ctx, func() error {
+		return i.interpret(env, stmts)
+	})
 }
 
 func (i *Interpreter) Evaluate(expr ast.Expr) (loxtype.Type, error) {
-	return i.evaluate(i.globals, expr)
+	return i.EvaluateContext(context.Background(), expr)
+}
+
+// EvaluateContext is [Interpreter.Evaluate], stopping the expression with the context's error if the context is
+// cancelled.
+func (i *Interpreter) EvaluateContext(ctx context.Context, expr ast.Expr) (loxtype.Type, error) {
+	var value loxtype.Type
+	err := i.metered(ctx, func() error {
+		result, evalErr := i.evaluate(i.globals, expr)
+		value = result
+		return evalErr
+	})
+	return value, err
 }
 
 func (i *Interpreter) Interpret(stmts []ast.Stmt) error {
-	return i.interpret(i.globals, stmts)
+	return i.InterpretContext(context.Background(), stmts)
 }
 
-func (i *Interpreter) interpret(env *environment.Environment, stmts []ast.Stmt) error {
-	for _, s := range stmts {
-		if err := i.execute(env, s); err != nil {
-			return err
-		}
+// InterpretContext is [Interpreter.Interpret], stopping the statements with the context's error if the context is
+// cancelled.
+func (i *Interpreter) InterpretContext(ctx context.Context, stmts []ast.Stmt) error {
+	return i.metered(ctx, func() error {
+		return i.interpret(i.globals, stmts)
+	})
+}
+
+// metered runs f under a fresh meter for the context, so that every run is held to the limits on its own.
+func (i *Interpreter) metered(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	return nil
+	outer := i.meter
+	i.meter = limits.NewMeter(ctx, i.limits)
+	defer func() { i.meter = outer }()
+	return f()
+}
+
+// interpret runs top-level statements, which the resolver has already checked can't return.
+func (i *Interpreter) interpret(env *environment.Environment, stmts []ast.Stmt) error {
+	c, err := i.executeBlock(env, stmts)
+	return unwind(c, err)
 }
 
 func (i *Interpreter) lookUpVariable(env *environment.Environment, name *token.Token,
-	expr *ast.VariableExpr) (loxtype.Type, error) {
+	expr ast.Expr) (loxtype.Type, error) {
 	distance := i.locals[expr]
 	return env.GetAt(name, distance)
 }
-
-func (i *Interpreter) resolve(expr ast.Expr, distance int) {
-	i.locals[expr] = distance
-}
@@ -1,215 +1,251 @@
 package interpreter_test
 
 import (
+	"context"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/native"
 )
 
-func TestInterpreter_Evaluate(t *testing.T) {
+func TestInterpreter_Run(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range loxtest.Cases() {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var bob strings.Builder
+			err := interpreter.New(&bob,
+				interpreter.WithCapabilities(native.AllCapabilities),
+				interpreter.WithHost(native.Host{FS: loxtest.NewMemFS(test.Files)}),
+			).Run(test.Source)
+
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				require.ErrorContains(t, err, test.Message)
+				if test.Diagnostic != "" {
+					assert.Equal(t, test.Diagnostic, ierrors.Renderer{Source: test.Source}.Render(err))
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Output, bob.String())
+		})
+	}
+}
+
+func TestInterpreter_Run_runtimeErrorsAreRendered(t *testing.T) {
 	t.Parallel()
 
 	type Test struct {
-		Name          string
-		Source        string
-		Output        string
-		ExpectedError error
+		Name   string
+		Source string
+		Output string
 	}
 
 	tests := []Test{
 		{
-			Name:   "success/negate_integer",
-			Source: `print (- 4);`,
-			Output: "-4\n",
+			Name:   "type_error",
+			Source: "var a = 1;\nprint a + nil;",
+			Output: "script.lox:2:9: error at '+': " +
+				"operands to plus expression must be either string or numeric: type-error\n" +
+				" 2 | print a + nil;\n" +
+				"   |         ^",
 		},
 		{
-			Name:          "error/negate_string",
-			Source:        `print (-"Hello");`,
-			ExpectedError: interpreter.ErrNonNumericType,
+			Name:   "undefined_variable",
+			Source: "fun greet() {\n\tprint greeting;\n}\ngreet();",
+			Output: "script.lox:2:8: error at 'greeting': undefined variable: greeting\n" +
+				" 2 | \tprint greeting;\n" +
+				"   | \t      ^~~~~~~~\n" +
+				"  at greet (script.lox:2)\n" +
+				"  at <script> (script.lox:4)",
 		},
 		{
-			Name:   "success/truthiness/nil_is_falsey",
-			Source: `print (!nil);`,
-			Output: "true\n",
+			Name:   "not_callable",
+			Source: `"not a function"();`,
+			Output: "script.lox:1:18: error at ')': can only call functions and classes: not a function\n" +
+				` 1 | "not a function"();` + "\n" +
+				"   |                  ^",
 		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			err := interpreter.New(io.Discard).Run(test.Source)
+
+			var e *ierrors.Error
+			require.ErrorAs(t, err, &e)
+			renderer := ierrors.Renderer{Filename: "script.lox", Source: test.Source}
+			assert.Equal(t, test.Output, renderer.Render(err))
+		})
+	}
+}
+
+func TestInterpreter_Run_traceback(t *testing.T) {
+	t.Parallel()
+
+	source := loxtest.Dedent(`
+		fun inner(x) {
+			return -x;
+		}
+
+		fun outer(x) {
+			return inner(x) + 1;
+		}
+
+		class Widget {
+			init(x) {
+				this.value = outer(x);
+			}
+		}
+
+		Widget("oops");
+	`)
+	err := interpreter.New(io.Discard).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrNonNumericType)
+
+	var tb *ierrors.Traceback
+	require.ErrorAs(t, err, &tb)
+	assert.Equal(t, []ierrors.Frame{
+		{Function: "inner", Line: 2},
+		{Function: "outer", Line: 6},
+		{Function: "Widget", Line: 11},
+		{Function: "<script>", Line: 15},
+	}, tb.Frames)
+}
+
+func TestInterpreter_Run_stackOverflow(t *testing.T) {
+	t.Parallel()
+
+	source := "fun f() { f(); }\nf();"
+	err := interpreter.New(io.Discard, interpreter.WithMaxCallDepth(10)).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrStackOverflow)
+
+	renderer := ierrors.Renderer{Filename: "script.lox", Source: source}
+	assert.Equal(t,
+		"script.lox:1:13: error at ')': stack overflow\n"+
+			" 1 | fun f() { f(); }\n"+
+			"   |             ^\n"+
+			"  at f (script.lox:1)\n"+
+			"  ... repeated 9 more times\n"+
+			"  at <script> (script.lox:2)",
+		renderer.Render(err))
+}
+
+func TestInterpreter_Run_noTracebackAtTopLevel(t *testing.T) {
+	t.Parallel()
+
+	err := interpreter.New(io.Discard).Run(`print -"top";`)
+	require.ErrorIs(t, err, ierrors.ErrNonNumericType)
+	require.NotErrorAs(t, err, new(*ierrors.Traceback))
+}
+
+func BenchmarkInterpreter_Run_calls(b *testing.B) {
+	source := `
+		fun fib(n) {
+			if (n < 2) return n;
+			return fib(n - 1) + fib(n - 2);
+		}
+		fib(20);
+	`
+	for b.Loop() {
+		if err := interpreter.New(io.Discard).Run(source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestInterpreter_Run_imports(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name          string
+		Files         map[string]string
+		Source        string
+		Output        string
+		ExpectedError error
+	}
+
+	tests := []Test{
 		{
-			Name: "success/assignment",
+			Name: "namespace",
+			Files: map[string]string{
+				"lib/counter.lox": `
+					print "loading";
+					var count = 0;
+					fun bump() { count = count + 1; return count; }
+				`,
+			},
 			Source: `
-			var a = "hello";
-			a = "world";
-			print a;
+				import "lib/counter.lox" as counter;
+				import "lib/counter.lox" as again;
+				print counter.bump();
+				print again.count;
+				print counter == again;
+				print counter;
 			`,
-			Output: "world\n",
+			Output: "loading\n1\n1\ntrue\n<module lib/counter.lox>\n",
 		},
 		{
-			Name: "success/lexical_scope",
-			Source: `
-				var a = 4;
-				var shadowed = "shadow";
-				{
-					a = 6;
-					var shadowed = "block";
-					print shadowed;
-				}
-				print a;
-				print shadowed;
-			`,
-			Output: dedent(`
-				block
-				6
-				shadow
-			`),
+			Name: "relative_to_importer",
+			Files: map[string]string{
+				"lib/a.lox": `import "b.lox" as b; var name = b.name;`,
+				"lib/b.lox": `var name = "b";`,
+			},
+			Source: `import "lib/a.lox" as a; print a.name;`,
+			Output: "b\n",
 		},
 		{
-			Name: "success/nystrom/lexical_scope",
-			Source: `
-				var a = "global a";
-				var b = "global b";
-				var c = "global c";
-				{
-					var a = "outer a";
-					var b = "outer b";
-					{
-						var a = "inner a";
-						print a;
-						print b;
-						print c;
-					}
-					print a;
-					print b;
-					print c;
-				}
-				print a;
-				print b;
-				print c;
-			`,
-			Output: dedent(`
-				inner a
-				outer b
-				global c
-				outer a
-				outer b
-				global c
-				global a
-				global b
-				global c
-			`),
-		},
-		{
-			Name: "success/if_statements",
-			Source: `
-				if (true) {
-					print "Hello";
-				}
-
-				if (false) {
-					print "Matt!";
-				} else {
-					print "World!";
-				}
-			`,
-			Output: dedent(`
-				Hello
-				World!
-			`),
+			Name:   "search_path",
+			Files:  map[string]string{"search/shared.lox": `var name = "shared";`},
+			Source: `import "shared.lox" as shared; print shared.name;`,
+			Output: "shared\n",
 		},
 		{
-			Name: "success/short_circuiting",
-			Source: `
-				print "hi" or 2;     // "hi".
-				print nil or "yes";  // "yes".
-				print nil and "bye"; // "nil".
-			`,
-			Output: dedent(`
-				hi
-				yes
-				nil
-			`),
+			Name:          "not_found",
+			Source:        `import "missing.lox" as missing;`,
+			ExpectedError: ierrors.ErrModuleNotFound,
 		},
 		{
-			Name: "success/simple_while_loop",
-			Source: `
-				var i = 0;
-				while (i < 5) {
-					print "Hello";
-					i = i + 1;
-				}
-			`,
-			Output: dedent(`
-				Hello
-				Hello
-				Hello
-				Hello
-				Hello
-			`),
+			Name: "cycle",
+			Files: map[string]string{
+				"a.lox": `import "b.lox" as b;`,
+				"b.lox": `import "a.lox" as a;`,
+			},
+			Source:        `import "a.lox" as a;`,
+			ExpectedError: ierrors.ErrImportCycle,
 		},
 		{
-			Name: "success/for_loop/fibonacci",
-			Source: `
-				var a = 0;
-				var temp;
-
-				for (var b = 1; a < 10000; b = temp + b) {
-					print a;
-					temp = a;
-					a = b;
-				}
-			`,
-			Output: dedent(`
-				0
-				1
-				1
-				2
-				3
-				5
-				8
-				13
-				21
-				34
-				55
-				89
-				144
-				233
-				377
-				610
-				987
-				1597
-				2584
-				4181
-				6765
-			`),
-		},
-		{
-			Name: "success/function_call/clock",
-			Source: `
-				var mils = clock();
-				if (mils > 0) {
-					print "success";
-				}
-			`,
-			Output: dedent(`
-				success
-			`),
+			Name:          "import_script",
+			Files:         map[string]string{"main.lox": `import "main.lox" as main;`},
+			Source:        `import "main.lox" as main;`,
+			ExpectedError: ierrors.ErrImportCycle,
 		},
 		{
-			Name: "success/functions/count_to_three",
-			Source: `
-				fun count(n) {
-					if (n > 1) count(n - 1);
-					print n;
-				}
-
-				count(3);
-			`,
-			Output: dedent(`
-				1
-				2
-				3
-			`),
+			Name:          "undefined_binding",
+			Files:         map[string]string{"empty.lox": ``},
+			Source:        `import "empty.lox" as empty; empty.clock;`,
+			ExpectedError: ierrors.ErrUndefinedProperty,
+		},
+		{
+			Name:          "read_only_binding",
+			Files:         map[string]string{"util.lox": `var x = 1;`},
+			Source:        `import "util.lox" as util; util.x = 5;`,
+			ExpectedError: ierrors.ErrModuleReadOnly,
 		},
 	}
 
@@ -218,30 +254,207 @@ func TestInterpreter_Evaluate(t *testing.T) {
 			t.Parallel()
 
 			var bob strings.Builder
-			err := interpreter.New(&bob).Run(test.Source)
+			err := interpreter.New(&bob,
+				interpreter.WithCapabilities(native.CapabilityFS),
+				interpreter.WithHost(native.Host{FS: loxtest.NewMemFS(test.Files)}),
+				interpreter.WithScriptPath("main.lox"),
+				interpreter.WithSearchPath("search"),
+			).Run(test.Source)
 
 			if test.ExpectedError != nil {
 				require.ErrorIs(t, err, test.ExpectedError)
 				return
 			}
-
 			require.NoError(t, err)
 			assert.Equal(t, test.Output, bob.String())
 		})
 	}
 }
 
-func dedent(s string) string {
-	var (
-		bob             strings.Builder
-		passedFirstLine bool
+func TestInterpreter_Run_importedErrorsAreRendered(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"lib/util.lox": "fun negate(x) {\n\treturn -x;\n}\n"})
+	source := "import \"lib/util.lox\" as util;\nutil.negate(\"one\");"
+	err := interpreter.New(io.Discard,
+		interpreter.WithCapabilities(native.CapabilityFS),
+		interpreter.WithHost(native.Host{FS: fsys}),
+		interpreter.WithScriptPath("main.lox"),
+	).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrNonNumericType)
+
+	renderer := ierrors.Renderer{Filename: "main.lox", Source: source}
+	assert.Equal(t, "lib/util.lox:2:9: error at '-': cannot apply minus operator: non-numeric type-error\n"+
+		" 2 | \treturn -x;\n"+
+		"   | \t       ^\n"+
+		"  at negate (lib/util.lox:2)\n"+
+		"  at <script> (main.lox:2)", renderer.Render(err))
+}
+
+func TestInterpreter_RunContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := interpreter.New(io.Discard).RunContext(ctx, `
+		fun spin() { while (true) {} }
+		try { spin(); } catch (e) { print "caught"; }
+	`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	var bob strings.Builder
+	err = interpreter.New(&bob).RunContext(cancelled, `print "unreachable";`)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, bob.String())
+}
+
+func TestInterpreter_Run_limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name   string
+		Option interpreter.Option
+		Source string
+		Err    error
+	}{
+		{
+			Name:   "steps",
+			Option: interpreter.WithMaxSteps(100),
+			Source: `while (true) {}`,
+			Err:    ierrors.ErrStepLimit,
+		},
+		{
+			Name:   "steps within the limit",
+			Option: interpreter.WithMaxSteps(100),
+			Source: `for (var i = 0; i < 3; i = i + 1) {}`,
+		},
+		{
+			Name:   "call depth",
+			Option: interpreter.WithMaxCallDepth(10),
+			Source: `fun f(n) { if (n > 0) f(n - 1); } f(10);`,
+			Err:    ierrors.ErrStackOverflow,
+		},
+		{
+			Name:   "call depth above the default",
+			Option: interpreter.WithMaxCallDepth(1000),
+			Source: `fun f(n) { if (n > 0) f(n - 1); } f(500);`,
+		},
+		{
+			Name:   "heap",
+			Option: interpreter.WithMaxHeap(1),
+			Source: `var l = []; while (true) { push(l, "more"); }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+		{
+			Name:   "heap between checks of the steps",
+			Option: interpreter.WithMaxHeap(64 << 20),
+			Source: `var s = "x"; for (var i = 0; i < 30; i = i + 1) { s = s + s; }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+		{
+			Name:   "heap grown by a native function",
+			Option: interpreter.WithMaxHeap(64 << 20),
+			Source: `var s = "x"; for (var i = 0; i < 30; i = i + 1) { s = replace(s, "x", "xx"); }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			err := interpreter.New(io.Discard, test.Option).Run(test.Source)
+			if test.Err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.Err)
+		})
+	}
+}
+
+//nolint:paralleltest // It sets an environment variable.
+func TestInterpreter_Run_capabilities(t *testing.T) {
+	t.Setenv("GLOX_TEST_VARIABLE", "set")
+
+	tests := []struct {
+		Name    string
+		Granted native.Capability
+		Source  string
+		Output  string
+		Err     error
+	}{
+		{Name: "none", Source: `clock();`, Err: environment.ErrUndefinedVariable},
+		{Name: "values", Source: `print len([1, 2]);`, Output: "2\n"},
+		{Name: "time", Granted: native.CapabilityTime, Source: `print clock() > 0;`, Output: "true\n"},
+		{Name: "other", Granted: native.CapabilityTime, Source: `exit();`, Err: environment.ErrUndefinedVariable},
+		{
+			Name:    "import",
+			Granted: native.CapabilityIO,
+			Source:  `import "lib.lox" as lib;`,
+			Err:     ierrors.ErrImportDenied,
+		},
+		{
+			Name:    "env",
+			Granted: native.CapabilityEnv,
+			Source:  `print getenv("GLOX_TEST_VARIABLE"); print getenv("GLOX_TEST_UNSET");`,
+			Output:  "set\nnil\n",
+		},
+		{
+			Name:    "exit",
+			Granted: native.CapabilityProcess,
+			Source:  `print 1; try { exit(); } finally { print 2; } print 3;`,
+			Output:  "1\n",
+			Err:     ierrors.ErrExit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var bob strings.Builder
+			err := interpreter.New(&bob, interpreter.WithCapabilities(test.Granted)).Run(test.Source)
+			if test.Err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.Err)
+			}
+			assert.Equal(t, test.Output, bob.String())
+		})
+	}
+}
+
+func TestInterpreter_Run_io(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"data.csv": "a,1\nb,2\r\n"})
+	var stdout, stderr strings.Builder
+	i := interpreter.New(&stdout,
+		interpreter.WithCapabilities(native.CapabilityIO|native.CapabilityFS),
+		interpreter.WithHost(native.Host{FS: fsys, Stdin: strings.NewReader("Ada\nsecond"), Stderr: &stderr}),
 	)
-	for line := range strings.Lines(s) {
-		if !passedFirstLine {
-			passedFirstLine = true
-			continue
+
+	err := i.Run(`
+		var name = input("name? ");
+		print "hello, " + name;
+		print readLine();
+		print readLine();
+		var total = 0;
+		var lines = readLines("data.csv");
+		for (var k = 0; k < len(lines); k = k + 1) {
+			total = total + parseNumber(split(lines[k], ",")[1]);
 		}
-		bob.WriteString(strings.TrimLeft(line, "\t "))
-	}
-	return bob.String()
+		writeFile("total.txt", "total");
+		appendFile("total.txt", ": " + toFixed(total, 0));
+		printErr(readFile("total.txt"));
+		try { readFile("missing.txt"); } catch (e) { printErr("missing"); }
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "name? hello, Ada\nsecond\nnil\n", stdout.String())
+	assert.Equal(t, "total: 3\nmissing\n", stderr.String())
+	assert.Equal(t, "total: 3", string(fsys.MapFS["total.txt"].Data))
+
+	err = interpreter.New(io.Discard, interpreter.WithHost(native.Host{FS: fsys})).Run(`readFile("data.csv");`)
+	require.ErrorIs(t, err, environment.ErrUndefinedVariable, "file access needs its capability")
 }
@@ -0,0 +1,163 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+// moduleFrame names the top-level code of imported modules in tracebacks.
+const moduleFrame = "<module>"
+
+// module is a file of code the interpreter has run, whose top-level bindings live in env.
+type module struct {
+	// name is the path of the file as errors report it, and path is its absolute path, which identifies it.
+	name   string
+	path   string
+	source string
+	env    *environment.Environment
+	// loaded is false while the module's top-level code is still running.
+	loaded bool
+}
+
+// withSource gives err the module's source, since the positions of errors raised within the module refer to it.
+func (m *module) withSource(err error) error {
+	if m == nil {
+		return err
+	}
+	return ierrors.WithSource(err, m.name, m.source)
+}
+
+// namespace is the value an import binds, whose properties are the top-level bindings of a module.
+type namespace struct {
+	module *module
+}
+
+var _ loxtype.Type = (*namespace)(nil)
+
+func (n *namespace) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*namespace)
+	return loxtype.Boolean(ok && o.module == n.module)
+}
+
+func (*namespace) IsTruthy() loxtype.Boolean { return true }
+func (n *namespace) String() string          { return "<module " + n.module.name + ">" }
+
+func (n *namespace) get(name *token.Token) (loxtype.Type, error) {
+	if value, ok := n.module.env.Lookup(name.Lexeme); ok {
+		return value, nil
+	}
+	return nil, ierrors.New(name, fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name.Lexeme))
+}
+
+// VisitImportStmt binds the namespace of a module, running the module first unless it has already been imported.
+func (i *Interpreter) VisitImportStmt(env *environment.Environment, s *ast.ImportStmt) (ast.Completion, error) {
+	if i.capabilities&native.CapabilityFS == 0 {
+		return ast.Completion{}, ierrors.New(s.Path, ierrors.ErrImportDenied)
+	}
+	name, path, err := i.findModule(s.Path)
+	if err != nil {
+		return ast.Completion{}, err
+	}
+
+	m, ok := i.modules[path]
+	if ok && !m.loaded {
+		return ast.Completion{}, ierrors.New(s.Path, fmt.Errorf("%w: %s", ierrors.ErrImportCycle, i.importChain(m)))
+	}
+	if !ok {
+		source, err := fs.ReadFile(i.host.FS, name)
+		if err != nil {
+			return ast.Completion{}, ierrors.New(s.Path, err)
+		}
+		m = &module{name: name, path: path, source: string(source), env: i.globals.MakeChild()}
+		if err = i.load(m, s.Keyword); err != nil {
+			return ast.Completion{}, m.withSource(err)
+		}
+	}
+
+	env.Define(s.Name, &namespace{module: m})
+	return ast.Completion{}, nil
+}
+
+// findModule looks for the file an import names relative to the directory of the importing file,
+// then relative to each directory of the search path in turn, in the host's file system.
+func (i *Interpreter) findModule(tok *token.Token) (name, path string, err error) {
+	target := tok.Lexeme
+	if s, ok := tok.Literal.(loxtype.String); ok {
+		target = string(s)
+	}
+
+	dirs := []string{""}
+	if !filepath.IsAbs(target) {
+		importer := ""
+		if i.module != nil {
+			importer = i.module.name
+		}
+		dirs = append([]string{filepath.Dir(importer)}, i.searchPath...)
+	}
+
+	for _, dir := range dirs {
+		name = filepath.Join(dir, target)
+		if info, statErr := fs.Stat(i.host.FS, name); statErr != nil || info.IsDir() {
+			continue
+		}
+		if path, err = filepath.Abs(name); err != nil {
+			return "", "", ierrors.New(tok, err)
+		}
+		return name, path, nil
+	}
+	return "", "", ierrors.New(tok, fmt.Errorf("%w: %s", ierrors.ErrModuleNotFound, target))
+}
+
+// load runs the top-level code of a module, which is registered while it runs so that importing it again is a cycle.
+// Modules that fail to load are forgotten.
+func (i *Interpreter) load(m *module, keyword *token.Token) error {
+	tokens, scanErr := scanner.New(m.source).ScanTokens()
+	stmts, parseErr := parser.New(tokens).Parse()
+	if err := errors.Join(scanErr, parseErr); err != nil {
+		return err
+	}
+	if err := resolver.New(i.locals).Resolve(stmts); err != nil {
+		return err
+	}
+
+	i.modules[m.path] = m
+	i.importing = append(i.importing, m)
+	i.stack = append(i.stack, frame{function: moduleFrame, file: m.name, call: keyword})
+	importer := i.module
+	i.module = m
+
+	c, err := i.executeBlock(m.env, stmts)
+	err = unwind(c, err)
+
+	i.module = importer
+	i.stack = i.stack[:len(i.stack)-1]
+	i.importing = i.importing[:len(i.importing)-1]
+	if err != nil {
+		delete(i.modules, m.path)
+		return err
+	}
+	m.loaded = true
+	return nil
+}
+
+// importChain describes the imports that lead from m back around to it.
+func (i *Interpreter) importChain(m *module) string {
+	var names []string
+	for k := len(i.importing) - 1; k >= 0 && i.importing[k] != m; k-- {
+		names = append([]string{i.importing[k].name}, names...)
+	}
+	return strings.Join(append(append([]string{m.name}, names...), m.name), " -> ")
+}
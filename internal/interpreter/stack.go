@@ -0,0 +1,81 @@
+package interpreter
+
+import (
+	"errors"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+// scriptFrame names the top-level code in tracebacks.
+const scriptFrame = "<script>"
+
+// frame records a call in progress.
+type frame struct {
+	function string
+	native   bool
+	// file names the file the function was declared in, if it's known.
+	file string
+	// call is the closing parenthesis of the call expression, which lies in the caller.
+	// It's nil for calls made by the Go program hosting the interpreter.
+	call *token.Token
+}
+
+func newFrame(function callable, call *token.Token) frame {
+	f := frame{call: call}
+	switch fn := function.(type) {
+	case *loxFunction:
+		f.function = fn.stmt.Name.Lexeme
+		if fn.module != nil {
+			f.file = fn.module.name
+		}
+	case *loxClass:
+		f.function = fn.name
+	case *nativeFunction:
+		f.function = fn.String()
+		f.native = true
+	default:
+		f.function = fn.String()
+	}
+	return f
+}
+
+// enter pushes a frame for a call, unless the program has been cancelled or has too many calls in progress already.
+func (i *Interpreter) enter(function callable, call *token.Token) error {
+	if err := i.meter.Interrupted(); err != nil {
+		return err
+	}
+	if len(i.stack) >= i.limits.MaxCallDepth() {
+		return ierrors.ErrStackOverflow
+	}
+	i.stack = append(i.stack, newFrame(function, call))
+	return nil
+}
+
+// traceback annotates err with the calls currently in progress.
+// It leaves err alone if it was already annotated by a deeper call.
+func (i *Interpreter) traceback(err error) error {
+	if errors.As(err, new(*ierrors.Traceback)) {
+		return err
+	}
+
+	// Execution within the innermost call is wherever the error happened,
+	// and within every other call it is wherever the next call was made.
+	var line int
+	if e := (*ierrors.Error)(nil); errors.As(err, &e) {
+		line = e.Line
+	}
+
+	frames := make([]ierrors.Frame, 0, len(i.stack)+1)
+	for k := len(i.stack) - 1; k >= 0; k-- {
+		f := i.stack[k]
+		frames = append(frames, ierrors.Frame{Function: f.function, Line: line, Native: f.native, File: f.file})
+		if f.call == nil {
+			return &ierrors.Traceback{Err: err, Frames: frames}
+		}
+		line = f.call.Line
+	}
+	frames = append(frames, ierrors.Frame{Function: scriptFrame, Line: line})
+
+	return &ierrors.Traceback{Err: err, Frames: frames}
+}
@@ -5,106 +5,225 @@ import (
 
 	"github.com/matt-hoiland/glox/internal/ast"
 	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
 )
 
 var _ ast.StmtVisitor = (*Interpreter)(nil)
 
-func (i *Interpreter) execute(env *environment.Environment, s ast.Stmt) error {
-	if _, err := s.Accept(env, i); err != nil {
-		return err
+func (i *Interpreter) execute(env *environment.Environment, s ast.Stmt) (ast.Completion, error) {
+	if err := i.meter.Step(); err != nil {
+		return ast.Completion{}, err
 	}
-	return nil
+	return s.Accept(env, i)
 }
 
-func (i *Interpreter) VisitBlockStmt(env *environment.Environment, s *ast.BlockStmt) (loxtype.Type, error) {
-	if err := i.executeBlock(env.MakeChild(), s.Statements); err != nil {
-		return nil, err
+func (i *Interpreter) VisitBlockStmt(env *environment.Environment, s *ast.BlockStmt) (ast.Completion, error) {
+	return i.executeBlock(env.MakeChild(), s.Statements)
+}
+
+func (i *Interpreter) VisitBreakStmt(*environment.Environment, *ast.BreakStmt) (ast.Completion, error) {
+	return ast.Completion{Kind: ast.CompletionBreak}, nil
+}
+
+func (i *Interpreter) VisitClassStmt(env *environment.Environment, s *ast.ClassStmt) (ast.Completion, error) {
+	var (
+		superclass *loxClass
+		methodEnv  = env
+	)
+
+	if s.Superclass != nil {
+		value, err := i.evaluate(env, s.Superclass)
+		if err != nil {
+			return ast.Completion{}, err
+		}
+		var ok bool
+		if superclass, ok = value.(*loxClass); !ok {
+			return ast.Completion{}, ierrors.New(s.Superclass.Name, ierrors.ErrSuperclassNotClass)
+		}
+
+		methodEnv = env.MakeChild()
+		methodEnv.Define(superToken, superclass)
 	}
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+
+	methods := make(map[string]*loxFunction, len(s.Methods))
+	for _, m := range s.Methods {
+		methods[m.Name.Lexeme] = newFunction(methodEnv, m, m.Name.Lexeme == "init", i.module)
+	}
+	env.Define(s.Name, newClass(s.Name.Lexeme, superclass, methods))
+	return ast.Completion{}, nil
 }
 
-func (i *Interpreter) executeBlock(env *environment.Environment, stmts []ast.Stmt) error {
+// unwind returns the error a statement failed with, or the error a throw completion becomes once it leaves the
+// statements of a function or script, since the expressions beyond can only carry errors.
+func unwind(c ast.Completion, err error) error {
+	if err == nil && c.Kind == ast.CompletionThrow {
+		return c.Err
+	}
+	return err
+}
+
+// executeBlock runs stmts in order until one of them completes abruptly, and returns how the last one completed.
+func (i *Interpreter) executeBlock(env *environment.Environment, stmts []ast.Stmt) (ast.Completion, error) {
 	for _, stmt := range stmts {
-		if err := i.execute(env, stmt); err != nil {
-			return err
+		c, err := i.execute(env, stmt)
+		if err != nil || c.Kind != ast.CompletionNormal {
+			return c, err
 		}
 	}
-	return nil
+	return ast.Completion{}, nil
+}
+
+func (i *Interpreter) VisitContinueStmt(*environment.Environment, *ast.ContinueStmt) (ast.Completion, error) {
+	return ast.Completion{Kind: ast.CompletionContinue}, nil
 }
 
-func (i *Interpreter) VisitExpressionStmt(env *environment.Environment, s *ast.ExpressionStmt) (loxtype.Type, error) {
+func (i *Interpreter) VisitExpressionStmt(env *environment.Environment, s *ast.ExpressionStmt) (ast.Completion, error) {
 	_, err := i.evaluate(env, s.Expression)
-	if err != nil {
-		return nil, err
-	}
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+	return ast.Completion{}, err
 }
 
-func (i *Interpreter) VisitFunctionStmt(env *environment.Environment, s *ast.FunctionStmt) (loxtype.Type, error) {
-	env.Define(s.Name, newFunction(env, s))
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+func (i *Interpreter) VisitFunctionStmt(env *environment.Environment, s *ast.FunctionStmt) (ast.Completion, error) {
+	env.Define(s.Name, newFunction(env, s, false, i.module))
+	return ast.Completion{}, nil
 }
 
-func (i *Interpreter) VisitIfStmt(env *environment.Environment, s *ast.IfStmt) (loxtype.Type, error) {
+func (i *Interpreter) VisitIfStmt(env *environment.Environment, s *ast.IfStmt) (ast.Completion, error) {
 	cond, err := i.evaluate(env, s.Condition)
 	if err != nil {
-		return nil, err
+		return ast.Completion{}, err
 	}
 
 	if cond.IsTruthy() {
-		err = i.execute(env, s.ThenBranch)
+		return i.execute(env, s.ThenBranch)
 	} else if s.ElseBranch != nil {
-		err = i.execute(env, s.ElseBranch)
+		return i.execute(env, s.ElseBranch)
 	}
+	return ast.Completion{}, nil
+}
 
+func (i *Interpreter) VisitPrintStmt(env *environment.Environment, s *ast.PrintStmt) (ast.Completion, error) {
+	value, err := i.evaluate(env, s.Expression)
 	if err != nil {
-		return nil, err
+		return ast.Completion{}, err
 	}
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+	fmt.Fprintln(i.w, value)
+	return ast.Completion{}, nil
 }
 
-func (i *Interpreter) VisitPrintStmt(env *environment.Environment, s *ast.PrintStmt) (loxtype.Type, error) {
-	value, err := i.evaluate(env, s.Expression)
+func (i *Interpreter) VisitReturnStmt(env *environment.Environment, s *ast.ReturnStmt) (ast.Completion, error) {
+	var value loxtype.Type = loxtype.Nil{}
+	if s.Value != nil {
+		var err error
+		if value, err = i.evaluate(env, s.Value); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{Kind: ast.CompletionReturn, Value: value}, nil
+}
+
+func (i *Interpreter) VisitThrowStmt(env *environment.Environment, s *ast.ThrowStmt) (ast.Completion, error) {
+	value, err := i.evaluate(env, s.Value)
 	if err != nil {
-		return nil, err
+		return ast.Completion{}, err
 	}
-	fmt.Fprintln(i.w, value)
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+	thrown := ierrors.Thrown(value, s.Keyword.Line)
+	err = thrown.Origin
+	if err == nil {
+		err = ierrors.New(s.Keyword, thrown)
+	}
+	return ast.Completion{Kind: ast.CompletionThrow, Value: thrown, Err: err}, nil
+}
+
+// VisitTryStmt runs the catch clause for errors the program can catch, and the finally clause however control
+// leaves the statement, including by returning. An abrupt exit from the finally clause replaces the original one.
+func (i *Interpreter) VisitTryStmt(env *environment.Environment, s *ast.TryStmt) (ast.Completion, error) {
+	c, err := i.executeBlock(env.MakeChild(), s.Body)
+	if ierrors.Uncatchable(err) {
+		return c, err
+	}
+
+	if s.CatchName != nil {
+		if caught, ok := catch(c, err); ok {
+			catchEnv := env.MakeChild()
+			catchEnv.Define(s.CatchName, caught)
+			c, err = i.executeBlock(catchEnv, s.CatchBody)
+			if ierrors.Uncatchable(err) {
+				return c, err
+			}
+		}
+	}
+
+	if s.FinallyBody != nil {
+		finally, finallyErr := i.executeBlock(env.MakeChild(), s.FinallyBody)
+		if finallyErr != nil || finally.Kind != ast.CompletionNormal {
+			return finally, finallyErr
+		}
+	}
+
+	return c, err
 }
 
-func (i *Interpreter) VisitVarStmt(env *environment.Environment, s *ast.VarStmt) (loxtype.Type, error) {
+// catch returns the error object a catch clause receives for a statement that threw, or failed with an error the
+// program can catch.
+func catch(c ast.Completion, err error) (*loxtype.Error, bool) {
+	if err != nil {
+		return ierrors.Catch(err)
+	}
+	if c.Kind != ast.CompletionThrow {
+		return nil, false
+	}
+	thrown := c.Value.(*loxtype.Error) //nolint:forcetypeassert // Throw statements only throw error objects.
+	if thrown.Origin == nil {
+		thrown.Origin = c.Err
+	}
+	return thrown, true
+}
+
+func (i *Interpreter) VisitVarStmt(env *environment.Environment, s *ast.VarStmt) (ast.Completion, error) {
 	var (
 		value loxtype.Type = loxtype.Nil{}
 		err   error
 	)
 	if s.Initializer != nil {
 		if value, err = i.evaluate(env, s.Initializer); err != nil {
-			return nil, err
+			return ast.Completion{}, err
 		}
 	}
 
 	env.Define(s.Name, value)
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+	return ast.Completion{}, nil
 }
 
-func (i *Interpreter) VisitWhileStmt(env *environment.Environment, s *ast.WhileStmt) (loxtype.Type, error) {
-	var (
-		cond loxtype.Type
-		err  error
-	)
-
+func (i *Interpreter) VisitWhileStmt(env *environment.Environment, s *ast.WhileStmt) (ast.Completion, error) {
 	for {
-		if cond, err = i.evaluate(env, s.Condition); err != nil {
-			return nil, err
+		cond, err := i.evaluate(env, s.Condition)
+		if err != nil {
+			return ast.Completion{}, err
 		}
 		if !cond.IsTruthy() {
-			break
+			return ast.Completion{}, nil
 		}
-		if err = i.execute(env, s.Body); err != nil {
-			return nil, err
+
+		c, err := i.execute(env, s.Body)
+		if err != nil {
+			return ast.Completion{}, err
+		}
+		switch c.Kind {
+		case ast.CompletionBreak:
+			return ast.Completion{}, nil
+		case ast.CompletionReturn, ast.CompletionThrow:
+			return c, nil
+		case ast.CompletionNormal, ast.CompletionContinue:
 		}
-	}
 
-	return nil, nil //nolint:nilnil // TODO: Emit final type?
+		if s.Increment != nil {
+			if _, err = i.evaluate(env, s.Increment); err != nil {
+				return ast.Completion{}, err
+			}
+		}
+		if err = i.meter.Interrupted(); err != nil {
+			return ast.Completion{}, err
+		}
+	}
 }
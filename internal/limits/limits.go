@@ -0,0 +1,109 @@
+// Package limits bounds the resources a running program may use, so that the host running it can stop it.
+package limits
+
+import (
+	"context"
+	"runtime/metrics"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+)
+
+// DefaultCallDepth is how many calls may be in progress at once unless the host says otherwise.
+const DefaultCallDepth = 256
+
+// heapInterval is how many steps are taken between checks of the heap, which are too slow to make every step.
+const heapInterval = 1024
+
+// heapQuantum is how many bytes a program may be charged for between checks of the heap, so that a program
+// allocating quickly is stopped long before its steps would next have the heap checked.
+const heapQuantum = 1 << 20
+
+// heapMetric is the memory occupied by objects on the Go heap, live or not yet collected.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// Limits are what a program may use while it runs. Zero means no limit, except for the call depth.
+type Limits struct {
+	// Steps is how many steps a program may take. A step is an instruction for the virtual machine,
+	// or a statement or expression for the interpreter.
+	Steps int
+	// CallDepth is how many calls may be in progress at once. Zero means [DefaultCallDepth].
+	CallDepth int
+	// Heap is how many bytes the Go heap may hold. The heap is shared with the host and anything else it runs,
+	// so this guards against a program that runs away with memory rather than measuring what it uses precisely.
+	Heap uint64
+}
+
+// MaxCallDepth returns how many calls may be in progress at once, which is [DefaultCallDepth] if unset.
+func (l Limits) MaxCallDepth() int {
+	if l.CallDepth > 0 {
+		return l.CallDepth
+	}
+	return DefaultCallDepth
+}
+
+// Meter tracks a single run of a program against its limits and its context.
+type Meter struct {
+	ctx    context.Context //nolint:containedctx // The meter lasts only as long as the run.
+	done   <-chan struct{}
+	limits Limits
+	steps  int
+	// charged is how many bytes the program has been charged for since the heap was last checked.
+	charged int
+	sample  []metrics.Sample
+}
+
+// NewMeter returns a meter for a run that the context can cancel.
+func NewMeter(ctx context.Context, limits Limits) *Meter {
+	m := &Meter{ctx: ctx, done: ctx.Done(), limits: limits}
+	if limits.Heap > 0 {
+		m.sample = []metrics.Sample{{Name: heapMetric}}
+	}
+	return m
+}
+
+// Step counts a step, returning an error if the program has taken too many or the heap has grown too large.
+func (m *Meter) Step() error {
+	m.steps++
+	if m.limits.Steps > 0 && m.steps > m.limits.Steps {
+		return ierrors.ErrStepLimit
+	}
+	if m.sample != nil && m.steps%heapInterval == 0 {
+		return m.checkHeap(0)
+	}
+	return nil
+}
+
+// Allocate charges the program for n bytes it's allocating, returning an error if they'd take the heap past its
+// limit. Backends charge for values that can grow faster than the steps that make them, like strings that are
+// concatenated with themselves, so that the heap is checked as soon as a program has allocated a lot.
+func (m *Meter) Allocate(n int) error {
+	if m.sample == nil {
+		return nil
+	}
+	m.charged += n
+	if m.charged < heapQuantum {
+		return nil
+	}
+	m.charged = 0
+	return m.checkHeap(uint64(n))
+}
+
+// checkHeap returns [ierrors.ErrHeapLimit] if the heap, with pending bytes more, would be larger than allowed.
+func (m *Meter) checkHeap(pending uint64) error {
+	metrics.Read(m.sample)
+	if m.sample[0].Value.Kind() == metrics.KindUint64 && m.sample[0].Value.Uint64()+pending > m.limits.Heap {
+		return ierrors.ErrHeapLimit
+	}
+	return nil
+}
+
+// Interrupted returns the context's error if it's been cancelled. Backends check it wherever a program
+// could run indefinitely: at the end of every loop iteration and at every call.
+func (m *Meter) Interrupted() error {
+	select {
+	case <-m.done:
+		return m.ctx.Err()
+	default:
+		return nil
+	}
+}
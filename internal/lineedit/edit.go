@@ -0,0 +1,202 @@
+package lineedit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// line is the state of a line while it is being edited.
+type line struct {
+	prompt string
+	buf    []rune
+	pos    int
+
+	// recalled is the index in the history of the line being shown, or the length of the history
+	// when it's the line being typed, which draft then holds while older lines are shown.
+	recalled int
+	draft    []rune
+}
+
+// edit reads keys from a terminal in raw mode, redrawing the line after each, until Enter is pressed.
+//
+// Left and Right (or Ctrl-B and Ctrl-F) move the cursor, Home and End (or Ctrl-A and Ctrl-E) move it to either end,
+// Backspace and Delete remove a character, Ctrl-K and Ctrl-U remove everything after or before the cursor,
+// Up and Down (or Ctrl-P and Ctrl-N) recall lines from the history, and Tab completes the word before the cursor.
+func (e *Editor) edit(prompt string) (string, error) {
+	l := &line{prompt: prompt, recalled: len(e.history)}
+	e.refresh(l)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(l.buf) > 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return string(l.buf), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(l.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			l.delete()
+		case keyBackspace, keyCtrlH:
+			if l.pos > 0 {
+				l.pos--
+				l.delete()
+			}
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			l.pos = max(l.pos-1, 0)
+		case keyCtrlF:
+			l.pos = min(l.pos+1, len(l.buf))
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.buf = slices.Delete(l.buf, 0, l.pos)
+			l.pos = 0
+		case keyCtrlP:
+			e.recall(l, l.recalled-1)
+		case keyCtrlN:
+			e.recall(l, l.recalled+1)
+		case keyTab:
+			e.completeWord(l)
+		case keyEscape:
+			e.escape(l)
+		default:
+			if unicode.IsPrint(r) {
+				l.buf = slices.Insert(l.buf, l.pos, r)
+				l.pos++
+			}
+		}
+		e.refresh(l)
+	}
+}
+
+// escape handles the keys that send escape sequences, of the forms ESC [ params final and ESC O final.
+func (e *Editor) escape(l *line) {
+	intro, _, err := e.in.ReadRune()
+	if err != nil || (intro != '[' && intro != 'O') {
+		return
+	}
+	var params []rune
+	final, _, err := e.in.ReadRune()
+	for err == nil && final >= '0' && final <= '?' {
+		params = append(params, final)
+		final, _, err = e.in.ReadRune()
+	}
+	if err != nil {
+		return
+	}
+
+	switch final {
+	case 'A':
+		e.recall(l, l.recalled-1)
+	case 'B':
+		e.recall(l, l.recalled+1)
+	case 'C':
+		l.pos = min(l.pos+1, len(l.buf))
+	case 'D':
+		l.pos = max(l.pos-1, 0)
+	case 'H':
+		l.pos = 0
+	case 'F':
+		l.pos = len(l.buf)
+	case '~':
+		switch string(params) {
+		case "1", "7":
+			l.pos = 0
+		case "4", "8":
+			l.pos = len(l.buf)
+		case "3":
+			l.delete()
+		}
+	}
+}
+
+// completeWord replaces the word before the cursor with its completion, or with as much of it as the candidates
+// agree on, listing the candidates below the line when they agree on no more than the word already has.
+func (e *Editor) completeWord(l *line) {
+	if e.complete == nil {
+		return
+	}
+	before := string(l.buf[:l.pos])
+	start, candidates := e.complete(before)
+	if len(candidates) == 0 || start < 0 || start > len(before) {
+		return
+	}
+
+	word := before[start:]
+	completion := candidates[0]
+	for _, c := range candidates[1:] {
+		completion = commonPrefix(completion, c)
+	}
+	if len(candidates) > 1 && len(completion) <= len(word) {
+		fmt.Fprint(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+		return
+	}
+
+	replaced := []rune(before[:start] + completion)
+	l.buf = append(replaced, l.buf[l.pos:]...)
+	l.pos = len(replaced)
+}
+
+// commonPrefix returns the longest prefix a and b share, made of whole runes.
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	for n > 0 && n < len(a) && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return a[:n]
+}
+
+// recall shows the line at index i in the history, keeping the line being typed to come back to.
+func (e *Editor) recall(l *line, i int) {
+	if i < 0 || i > len(e.history) || i == l.recalled {
+		return
+	}
+	if l.recalled == len(e.history) {
+		l.draft = l.buf
+	}
+	l.recalled = i
+	if i == len(e.history) {
+		l.buf = l.draft
+	} else {
+		l.buf = []rune(e.history[i])
+	}
+	l.pos = len(l.buf)
+}
+
+// delete removes the character under the cursor.
+func (l *line) delete() {
+	if l.pos < len(l.buf) {
+		l.buf = slices.Delete(l.buf, l.pos, l.pos+1)
+	}
+}
+
+// refresh redraws the line in place and puts the cursor back where it belongs.
+func (e *Editor) refresh(l *line) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
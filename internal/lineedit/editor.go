@@ -0,0 +1,149 @@
+// Package lineedit reads lines typed at a terminal, letting them be edited with the arrow keys and recalling the
+// lines typed before them, in the manner of readline.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrInterrupted is returned by [Editor.ReadLine] when Ctrl-C is pressed, abandoning the line being typed.
+var ErrInterrupted = errors.New("interrupted")
+
+// MaxHistory is the number of lines the history keeps, dropping the oldest.
+const MaxHistory = 1000
+
+// Control characters sent by the keys the editor understands.
+const (
+	keyCtrlA     = 0x01
+	keyCtrlB     = 0x02
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyCtrlF     = 0x06
+	keyCtrlH     = 0x08
+	keyTab       = 0x09
+	keyCtrlK     = 0x0b
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// Editor reads lines from a terminal. When its input isn't a terminal, lines are read as they come, without editing.
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	raw      func() (func(), error)
+	path     string
+	complete Completer
+
+	history []string
+}
+
+type Option func(*Editor)
+
+// Completer suggests how the word before the cursor could be completed when Tab is pressed.
+// Given the text before the cursor, it returns the byte offset in that text where the word starts,
+// and the words that could replace it.
+type Completer func(before string) (int, []string)
+
+// WithHistoryFile keeps the history in a file, one line per line, so that it lasts between sessions.
+// The file is read when the editor is made and every line added to the history is appended to it.
+// History that can't be read or written is skipped, since it is only a convenience.
+func WithHistoryFile(path string) Option {
+	return func(e *Editor) {
+		e.path = path
+	}
+}
+
+// New returns an editor that reads keys from in and writes prompts and echoes to out.
+func New(in io.Reader, out io.Writer, opts ...Option) *Editor {
+	e := &Editor{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(interface{ Fd() uintptr }); ok && isTerminal(int(f.Fd())) {
+		e.raw = func() (func(), error) { return makeRaw(int(f.Fd())) }
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.path != "" {
+		e.loadHistory()
+	}
+	return e
+}
+
+// SetCompleter completes words when Tab is pressed. A single candidate replaces the word, and several extend it
+// as far as they agree; when they don't agree any further, pressing Tab lists them.
+func (e *Editor) SetCompleter(complete Completer) {
+	e.complete = complete
+}
+
+// History returns the lines added to the history, oldest first.
+func (e *Editor) History() []string {
+	return slices.Clone(e.history)
+}
+
+// AddHistory adds a line to the history, unless it's blank or the same as the line before it.
+func (e *Editor) AddHistory(line string) {
+	if !e.remember(line) || e.path == "" {
+		return
+	}
+	f, err := os.OpenFile(e.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) //nolint:gosec // The user chooses the file.
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = fmt.Fprintln(f, line)
+}
+
+// remember adds a line to the history in memory, reporting whether it was added.
+func (e *Editor) remember(line string) bool {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return false
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > MaxHistory {
+		e.history = e.history[len(e.history)-MaxHistory:]
+	}
+	return true
+}
+
+func (e *Editor) loadHistory() {
+	data, err := os.ReadFile(e.path) //nolint:gosec // The user chooses the file.
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		e.remember(line)
+	}
+}
+
+// ReadLine writes the prompt and reads a line, without its line ending.
+// It returns [io.EOF] when the input ends, or Ctrl-D is pressed on an empty line,
+// and [ErrInterrupted] when Ctrl-C is pressed.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.raw == nil {
+		return e.readPlain(prompt)
+	}
+	restore, err := e.raw()
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore()
+	return e.edit(prompt)
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -0,0 +1,168 @@
+package lineedit_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/lineedit"
+)
+
+func TestEditor_ReadLine(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name    string
+		History []string
+		Keys    string
+		Line    string
+		Err     error
+	}
+
+	tests := []Test{
+		{Name: "typing", Keys: "print 1;\r", Line: "print 1;"},
+		{Name: "multibyte_runes", Keys: "föö\r", Line: "föö"},
+		{Name: "backspace", Keys: "prinx\x7ft\r", Line: "print"},
+		{Name: "left_and_insert", Keys: "pint\x1b[D\x1b[D\x1b[Dr\r", Line: "print"},
+		{Name: "right", Keys: "ac\x1b[D\x1b[Cb\r", Line: "acb"},
+		{Name: "home_and_end", Keys: "b\x1b[Ha\x1b[Fc\r", Line: "abc"},
+		{Name: "ctrl_a_and_ctrl_e", Keys: "b\x01a\x05c\r", Line: "abc"},
+		{Name: "delete", Keys: "abc\x01\x1b[3~\r", Line: "bc"},
+		{Name: "kill_to_end", Keys: "abcdef\x1b[D\x1b[D\x1b[D\x0b\r", Line: "abc"},
+		{Name: "kill_to_start", Keys: "abcdef\x1b[D\x1b[D\x15\r", Line: "ef"},
+		{Name: "up", History: []string{"one", "two"}, Keys: "\x1b[A\r", Line: "two"},
+		{Name: "up_twice", History: []string{"one", "two"}, Keys: "\x1b[A\x1b[A\x1b[A\r", Line: "one"},
+		{Name: "down_to_draft", History: []string{"one"}, Keys: "dra\x1b[A\x1b[Bft\r", Line: "draft"},
+		{Name: "edit_recalled", History: []string{"one"}, Keys: "\x1b[A\x7f\x7f\x7fnew\r", Line: "new"},
+		{Name: "ctrl_c", Keys: "abc\x03", Err: lineedit.ErrInterrupted},
+		{Name: "ctrl_d_on_empty_line", Keys: "\x04", Err: io.EOF},
+		{Name: "ctrl_d_deletes", Keys: "ab\x01\x04\r", Line: "b"},
+		{Name: "end_of_input", Keys: "abc", Line: "abc"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			editor := lineedit.NewTerminal(strings.NewReader(test.Keys), io.Discard)
+			for _, line := range test.History {
+				editor.AddHistory(line)
+			}
+
+			line, err := editor.ReadLine("> ")
+			if test.Err != nil {
+				require.ErrorIs(t, err, test.Err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Line, line)
+		})
+	}
+}
+
+func TestEditor_ReadLine_redraws(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	editor := lineedit.NewTerminal(strings.NewReader("ab\x1b[D\r"), &out)
+	_, err := editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "\r> \x1b[K\r> a\x1b[K\r> ab\x1b[K\r> ab\x1b[K\x1b[1D\r\n", out.String())
+}
+
+func TestEditor_ReadLine_notTerminal(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	editor := lineedit.New(strings.NewReader("one\r\ntwo"), &out)
+
+	line, err := editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "one", line)
+
+	line, err = editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "two", line)
+
+	_, err = editor.ReadLine("> ")
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "> > > ", out.String())
+}
+
+func TestEditor_AddHistory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".history")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\n"), 0o600))
+
+	editor := lineedit.New(strings.NewReader(""), io.Discard, lineedit.WithHistoryFile(path))
+	assert.Equal(t, []string{"one", "two"}, editor.History())
+
+	editor.AddHistory("two")
+	editor.AddHistory("  ")
+	editor.AddHistory("three")
+	assert.Equal(t, []string{"one", "two", "three"}, editor.History())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(data))
+
+	for i := range lineedit.MaxHistory {
+		editor.AddHistory(strings.Repeat("x", i+1))
+	}
+	history := editor.History()
+	assert.Len(t, history, lineedit.MaxHistory)
+	assert.Equal(t, "x", history[0])
+}
+
+func TestEditor_ReadLine_completion(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"print", "printer", "prune", "clock"}
+	complete := func(before string) (int, []string) {
+		start := strings.LastIndex(before, " ") + 1
+		var candidates []string
+		for _, w := range words {
+			if strings.HasPrefix(w, before[start:]) {
+				candidates = append(candidates, w)
+			}
+		}
+		return start, candidates
+	}
+
+	type Test struct {
+		Name   string
+		Keys   string
+		Line   string
+		Listed string
+	}
+
+	tests := []Test{
+		{Name: "single", Keys: "print cl\t(\r", Line: "print clock("},
+		{Name: "common_prefix", Keys: "prin\t\r", Line: "print"},
+		{Name: "lists_when_stuck", Keys: "pr\t\r", Line: "pr", Listed: "\r\nprint  printer  prune\r\n"},
+		{Name: "none", Keys: "x\t\r", Line: "x"},
+		{Name: "before_cursor", Keys: "cl)\x1b[D\t\r", Line: "clock)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			editor := lineedit.NewTerminal(strings.NewReader(test.Keys), &out)
+			editor.SetCompleter(complete)
+
+			line, err := editor.ReadLine("> ")
+			require.NoError(t, err)
+			assert.Equal(t, test.Line, line)
+			if test.Listed != "" {
+				assert.Contains(t, out.String(), test.Listed)
+			}
+		})
+	}
+}
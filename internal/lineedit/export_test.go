@@ -0,0 +1,10 @@
+package lineedit
+
+import "io"
+
+// NewTerminal returns an editor that edits lines read from in as if it were a terminal already in raw mode.
+func NewTerminal(in io.Reader, out io.Writer, opts ...Option) *Editor {
+	e := New(in, out, opts...)
+	e.raw = func() (func(), error) { return func() {}, nil }
+	return e
+}
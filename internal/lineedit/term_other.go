@@ -0,0 +1,14 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package lineedit
+
+import "errors"
+
+// isTerminal reports whether fd refers to a terminal, which it never does where raw mode isn't supported.
+func isTerminal(int) bool {
+	return false
+}
+
+func makeRaw(int) (func(), error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
@@ -0,0 +1,37 @@
+package loxtest
+
+import (
+	"testing/fstest"
+
+	"github.com/matt-hoiland/glox/internal/native"
+)
+
+// MemFS is a file system held in memory, for running programs that read and write files in tests.
+type MemFS struct {
+	fstest.MapFS
+}
+
+var _ native.FS = MemFS{}
+
+// NewMemFS returns a file system holding the given files, by name.
+func NewMemFS(files map[string]string) MemFS {
+	fsys := MemFS{MapFS: fstest.MapFS{}}
+	for name, data := range files {
+		fsys.MapFS[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	return fsys
+}
+
+func (fsys MemFS) WriteFile(name string, data []byte) error {
+	fsys.MapFS[name] = &fstest.MapFile{Data: data}
+	return nil
+}
+
+func (fsys MemFS) AppendFile(name string, data []byte) error {
+	f, ok := fsys.MapFS[name]
+	if !ok {
+		return fsys.WriteFile(name, data)
+	}
+	f.Data = append(f.Data, data...)
+	return nil
+}
@@ -0,0 +1,1420 @@
+// Package loxtest provides a suite of Lox programs, with their expected results, that every backend must pass.
+package loxtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
+)
+
+// Case is a program along with either the output it prints or the error it fails with.
+type Case struct {
+	Name          string
+	Source        string
+	Output        string
+	ExpectedError error
+	// Message, if set, is part of the message the expected error must have.
+	Message string
+	// Diagnostic, if set, is how the expected error renders against the source, which every backend must agree on.
+	Diagnostic string
+	// Files are the files the program can import or read, by name, for backends to serve from a [MemFS].
+	Files map[string]string
+}
+
+// Cases returns the suite.
+func Cases() []Case {
+	return []Case{
+		{
+			Name:   "success/negate_integer",
+			Source: `print (- 4);`,
+			Output: "-4\n",
+		},
+		{
+			Name:          "error/negate_string",
+			Source:        `print (-"Hello");`,
+			ExpectedError: ierrors.ErrNonNumericType,
+			Diagnostic: "1:8: error at '-': cannot apply minus operator: non-numeric type-error\n" +
+				" 1 | print (-\"Hello\");\n" +
+				"   |        ^",
+		},
+		{
+			Name:   "success/truthiness/nil_is_falsey",
+			Source: `print (!nil);`,
+			Output: "true\n",
+		},
+		{
+			Name: "success/assignment",
+			Source: `
+			var a = "hello";
+			a = "world";
+			print a;
+			`,
+			Output: "world\n",
+		},
+		{
+			Name: "success/lexical_scope",
+			Source: `
+				var a = 4;
+				var shadowed = "shadow";
+				{
+					a = 6;
+					var shadowed = "block";
+					print shadowed;
+				}
+				print a;
+				print shadowed;
+			`,
+			Output: Dedent(`
+				block
+				6
+				shadow
+			`),
+		},
+		{
+			Name: "success/nystrom/lexical_scope",
+			Source: `
+				var a = "global a";
+				var b = "global b";
+				var c = "global c";
+				{
+					var a = "outer a";
+					var b = "outer b";
+					{
+						var a = "inner a";
+						print a;
+						print b;
+						print c;
+					}
+					print a;
+					print b;
+					print c;
+				}
+				print a;
+				print b;
+				print c;
+			`,
+			Output: Dedent(`
+				inner a
+				outer b
+				global c
+				outer a
+				outer b
+				global c
+				global a
+				global b
+				global c
+			`),
+		},
+		{
+			Name: "success/if_statements",
+			Source: `
+				if (true) {
+					print "Hello";
+				}
+
+				if (false) {
+					print "Matt!";
+				} else {
+					print "World!";
+				}
+			`,
+			Output: Dedent(`
+				Hello
+				World!
+			`),
+		},
+		{
+			Name: "success/short_circuiting",
+			Source: `
+				print "hi" or 2;     // "hi".
+				print nil or "yes";  // "yes".
+				print nil and "bye"; // "nil".
+			`,
+			Output: Dedent(`
+				hi
+				yes
+				nil
+			`),
+		},
+		{
+			Name: "success/simple_while_loop",
+			Source: `
+				var i = 0;
+				while (i < 5) {
+					print "Hello";
+					i = i + 1;
+				}
+			`,
+			Output: Dedent(`
+				Hello
+				Hello
+				Hello
+				Hello
+				Hello
+			`),
+		},
+		{
+			Name: "success/for_loop/fibonacci",
+			Source: `
+				var a = 0;
+				var temp;
+
+				for (var b = 1; a < 10000; b = temp + b) {
+					print a;
+					temp = a;
+					a = b;
+				}
+			`,
+			Output: Dedent(`
+				0
+				1
+				1
+				2
+				3
+				5
+				8
+				13
+				21
+				34
+				55
+				89
+				144
+				233
+				377
+				610
+				987
+				1597
+				2584
+				4181
+				6765
+			`),
+		},
+		{
+			Name: "success/function_call/clock",
+			Source: `
+				var mils = clock();
+				if (mils > 0) {
+					print "success";
+				}
+			`,
+			Output: Dedent(`
+				success
+			`),
+		},
+		{
+			Name: "success/functions/count_to_three",
+			Source: `
+				fun count(n) {
+					if (n > 1) count(n - 1);
+					print n;
+				}
+
+				count(3);
+			`,
+			Output: Dedent(`
+				1
+				2
+				3
+			`),
+		},
+		{
+			Name: "success/classes/print_class_and_instance",
+			Source: `
+				class DevonshireCream {
+					serveOn() {
+						return "Scones";
+					}
+				}
+
+				print DevonshireCream;
+				print DevonshireCream();
+			`,
+			Output: Dedent(`
+				DevonshireCream
+				DevonshireCream instance
+			`),
+		},
+		{
+			Name: "success/classes/fields_and_methods",
+			Source: `
+				class Bacon {
+					eat() {
+						print "Crunch crunch crunch!";
+					}
+				}
+
+				var bacon = Bacon();
+				bacon.flavor = "smoky";
+				print bacon.flavor;
+				bacon.eat();
+			`,
+			Output: Dedent(`
+				smoky
+				Crunch crunch crunch!
+			`),
+		},
+		{
+			Name: "success/classes/this_in_bound_method",
+			Source: `
+				class Cake {
+					taste() {
+						var adjective = "delicious";
+						print "The " + this.flavor + " cake is " + adjective + "!";
+					}
+				}
+
+				var cake = Cake();
+				cake.flavor = "German chocolate";
+				var taste = cake.taste;
+				taste();
+			`,
+			Output: Dedent(`
+				The German chocolate cake is delicious!
+			`),
+		},
+		{
+			Name: "success/classes/initializer",
+			Source: `
+				class Person {
+					init(name, age) {
+						this.name = name;
+						this.age = age;
+					}
+
+					greet() {
+						print "Hello " + this.name + "!";
+					}
+				}
+
+				var me = Person("Matt", 32);
+				me.greet();
+				print me.age;
+			`,
+			Output: Dedent(`
+				Hello Matt!
+				32
+			`),
+		},
+		{
+			Name: "success/classes/initializer_returns_this",
+			Source: `
+				class Foo {
+					init() {
+						this.count = 0;
+						return;
+					}
+				}
+
+				var foo = Foo();
+				foo.count = 1;
+				print foo.init();
+				print foo.count;
+			`,
+			Output: Dedent(`
+				Foo instance
+				0
+			`),
+		},
+		{
+			Name: "success/classes/instance_identity",
+			Source: `
+				class Point {}
+				var a = Point();
+				var b = Point();
+				print a == a;
+				print a == b;
+			`,
+			Output: Dedent(`
+				true
+				false
+			`),
+		},
+		{
+			Name: "error/classes/undefined_property",
+			Source: `
+				class Empty {}
+				print Empty().missing;
+			`,
+			ExpectedError: ierrors.ErrUndefinedProperty,
+		},
+		{
+			Name:          "error/classes/property_on_non_instance",
+			Source:        `print "str".length;`,
+			ExpectedError: ierrors.ErrNotAnInstance,
+		},
+		{
+			Name:          "error/classes/this_outside_class",
+			Source:        `print this;`,
+			ExpectedError: resolver.ErrThisOutsideClass,
+		},
+		{
+			Name: "error/classes/return_value_from_initializer",
+			Source: `
+				class Foo {
+					init() {
+						return "something else";
+					}
+				}
+			`,
+			ExpectedError: resolver.ErrReturnFromInitializer,
+		},
+		{
+			Name: "success/inheritance/inherited_method",
+			Source: `
+				class Doughnut {
+					cook() {
+						print "Fry until golden brown.";
+					}
+				}
+
+				class BostonCream < Doughnut {}
+
+				BostonCream().cook();
+			`,
+			Output: Dedent(`
+				Fry until golden brown.
+			`),
+		},
+		{
+			Name: "success/inheritance/super_call",
+			Source: `
+				class Doughnut {
+					cook() {
+						print "Fry until golden brown.";
+					}
+				}
+
+				class BostonCream < Doughnut {
+					cook() {
+						super.cook();
+						print "Pipe full of custard and coat with chocolate.";
+					}
+				}
+
+				BostonCream().cook();
+			`,
+			Output: Dedent(`
+				Fry until golden brown.
+				Pipe full of custard and coat with chocolate.
+			`),
+		},
+		{
+			Name: "success/inheritance/super_binds_to_enclosing_class",
+			Source: `
+				class A {
+					method() {
+						print "A method";
+					}
+				}
+
+				class B < A {
+					method() {
+						print "B method";
+					}
+
+					test() {
+						super.method();
+					}
+				}
+
+				class C < B {}
+
+				C().test();
+			`,
+			Output: Dedent(`
+				A method
+			`),
+		},
+		{
+			Name: "success/inheritance/super_initializer",
+			Source: `
+				class Shape {
+					init(name) {
+						this.name = name;
+					}
+				}
+
+				class Square < Shape {
+					init(side) {
+						super.init("square");
+						this.side = side;
+					}
+
+					area() {
+						return this.side * this.side;
+					}
+				}
+
+				var sq = Square(3);
+				print sq.name;
+				print sq.area();
+			`,
+			Output: Dedent(`
+				square
+				9
+			`),
+		},
+		{
+			Name:          "error/inheritance/inherit_from_self",
+			Source:        `class Oops < Oops {}`,
+			ExpectedError: resolver.ErrInheritFromSelf,
+		},
+		{
+			Name: "error/inheritance/superclass_not_a_class",
+			Source: `
+				var NotAClass = "I am totally not a class";
+				class Subclass < NotAClass {}
+			`,
+			ExpectedError: ierrors.ErrSuperclassNotClass,
+		},
+		{
+			Name: "error/inheritance/super_outside_class",
+			Source: `
+				fun notAMethod() {
+					super.method();
+				}
+			`,
+			ExpectedError: resolver.ErrSuperOutsideClass,
+		},
+		{
+			Name: "error/inheritance/super_without_superclass",
+			Source: `
+				class Base {
+					method() {
+						super.method();
+					}
+				}
+			`,
+			ExpectedError: resolver.ErrSuperWithoutSuperclass,
+		},
+		{
+			Name: "error/inheritance/undefined_super_method",
+			Source: `
+				class A {}
+				class B < A {
+					method() {
+						super.missing();
+					}
+				}
+				B().method();
+			`,
+			ExpectedError: ierrors.ErrUndefinedProperty,
+		},
+		{
+			Name: "success/closures/counter",
+			Source: `
+				fun makeCounter() {
+					var i = 0;
+					fun count() {
+						i = i + 1;
+						return i;
+					}
+					return count;
+				}
+				var a = makeCounter();
+				var b = makeCounter();
+				print a();
+				print a();
+				print b();
+			`,
+			Output: Dedent(`
+				1
+				2
+				1
+			`),
+		},
+		{
+			Name: "success/closures/shared_variable",
+			Source: `
+				var get;
+				var set;
+				{
+					var value = "before";
+					fun getter() { return value; }
+					fun setter(v) { value = v; }
+					get = getter;
+					set = setter;
+				}
+				set("after");
+				print get();
+			`,
+			Output: "after\n",
+		},
+		{
+			Name: "success/closures/nested",
+			Source: `
+				fun outer() {
+					var x = "outer";
+					fun middle() {
+						fun inner() {
+							return x;
+						}
+						return inner;
+					}
+					return middle;
+				}
+				print outer()()();
+			`,
+			Output: "outer\n",
+		},
+		{
+			Name: "success/closures/method_captures_local_class",
+			Source: `
+				{
+					var greeting = "hi";
+					class Greeter {
+						greet() { return greeting + " from " + Greeter().name(); }
+						name() { return "greeter"; }
+					}
+					print Greeter().greet();
+				}
+			`,
+			Output: "hi from greeter\n",
+		},
+		{
+			Name: "success/functions/recursion",
+			Source: `
+				fun fib(n) {
+					if (n < 2) return n;
+					return fib(n - 1) + fib(n - 2);
+				}
+				print fib(15);
+			`,
+			Output: "610\n",
+		},
+		{
+			// Every call leaves hundreds of arguments on the stack while it recurses.
+			Name: "success/functions/deep_recursion_with_many_arguments",
+			Source: "fun g(" + numbered(200, "p%d") + ") { return 0; }\n" +
+				"fun f(n) {\n" +
+				"  if (n == 0) return 0;\n" +
+				"  return g(" + strings.Repeat("n, ", 199) + "g(" + strings.Repeat("n, ", 199) + "f(n - 1)));\n" +
+				"}\n" +
+				"print f(200);\n",
+			Output: "0\n",
+		},
+		{
+			// The stack grows while a closure has a local captured.
+			Name: "success/closures/capture_across_deep_recursion",
+			Source: "fun g(" + numbered(200, "p%d") + ") { return 0; }\n" +
+				"fun f(n) {\n" +
+				"  if (n == 0) return 0;\n" +
+				"  return g(" + strings.Repeat("n, ", 199) + "g(" + strings.Repeat("n, ", 199) + "f(n - 1)));\n" +
+				"}\n" +
+				"{\n" +
+				"  var x = \"before\";\n" +
+				"  fun set() { x = \"after\"; }\n" +
+				"  f(200);\n" +
+				"  set();\n" +
+				"  print x;\n" +
+				"}\n",
+			Output: "after\n",
+		},
+		{
+			Name: "success/functions/print_functions",
+			Source: `
+				fun f() {}
+				print f;
+				print clock;
+				print f == f;
+				print clock == clock;
+			`,
+			Output: Dedent(`
+				<fn: f>
+				<native fn: clock>
+				true
+				true
+			`),
+		},
+		{
+			Name: "success/classes/field_shadows_method",
+			Source: `
+				class Box {
+					value() { return "method"; }
+				}
+				fun field() { return "field"; }
+				var box = Box();
+				print box.value();
+				box.value = field;
+				print box.value();
+			`,
+			Output: Dedent(`
+				method
+				field
+			`),
+		},
+		{
+			Name: "success/classes/bound_method_remembers_receiver",
+			Source: `
+				class Named {
+					init(name) { this.name = name; }
+					say() { print this.name; }
+				}
+				var say = Named("bound").say;
+				say();
+			`,
+			Output: "bound\n",
+		},
+		{
+			Name: "success/lists/literals_and_indexing",
+			Source: `
+				var xs = [1, "two", [3]];
+				print xs;
+				print xs[1];
+				print xs[2][0];
+				print [];
+			`,
+			Output: Dedent(`
+				[1, two, [3]]
+				two
+				3
+				[]
+			`),
+		},
+		{
+			Name:   "success/lists/more_than_255_elements",
+			Source: "var xs = [" + numbered(300, "%d") + "];\nprint len(xs);\nprint xs[299];\n",
+			Output: "300\n299\n",
+		},
+		{
+			Name: "success/lists/index_assignment",
+			Source: `
+				var xs = [1, 2, 3];
+				var ys = xs;
+				print xs[0] = xs[1] + xs[2];
+				print ys;
+			`,
+			Output: Dedent(`
+				5
+				[5, 2, 3]
+			`),
+		},
+		{
+			Name: "success/lists/natives",
+			Source: `
+				var xs = [];
+				for (var i = 0; i < 4; i = i + 1) push(xs, i * i);
+				print len(xs);
+				print pop(xs);
+				print xs;
+				print slice(xs, 1, len(xs));
+				print len("héllo");
+			`,
+			Output: Dedent(`
+				4
+				9
+				[0, 1, 4]
+				[1, 4]
+				5
+			`),
+		},
+		{
+			Name:          "error/lists/index_out_of_range",
+			Source:        `var xs = [1, 2]; print xs[2];`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+		},
+		{
+			Name:          "error/lists/assign_out_of_range",
+			Source:        `var xs = []; xs[0] = 1;`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+		},
+		{
+			Name:          "error/lists/fractional_index",
+			Source:        `print [1, 2][0.5];`,
+			ExpectedError: ierrors.ErrIndexNotInteger,
+		},
+		{
+			Name:          "error/lists/index_non_list",
+			Source:        `print "abc"[0];`,
+			ExpectedError: ierrors.ErrNotIndexable,
+		},
+		{
+			Name:          "error/lists/pop_empty",
+			Source:        `pop([]);`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+		},
+		{
+			Name:          "error/lists/push_non_list",
+			Source:        `push(nil, 1);`,
+			ExpectedError: ierrors.ErrNonListType,
+		},
+		{
+			Name:          "error/lists/slice_backwards",
+			Source:        `slice([1, 2, 3], 2, 1);`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+			Message:       "slice end 1 is before start 2",
+		},
+		{
+			Name: "success/maps/literals_and_indexing",
+			Source: `
+				var m = {"name": "glox", 1: [true], nil: {}};
+				print m;
+				print m["name"];
+				print m[2 - 1][0];
+				m["name"] = "lox";
+				m[false] = 0;
+				print m;
+			`,
+			Output: Dedent(`
+				{name: glox, 1: [true], nil: {}}
+				glox
+				true
+				{name: lox, 1: [true], nil: {}, false: 0}
+			`),
+		},
+		{
+			Name:   "success/maps/more_than_255_entries",
+			Source: "var m = {" + numbered(300, "%[1]d: \"v%[1]d\"") + "};\nprint len(keys(m));\nprint m[299];\n",
+			Output: "300\nv299\n",
+		},
+		{
+			Name: "success/maps/natives",
+			Source: `
+				var m = {"a": 1, "b": 2, "c": 3};
+				print delete(m, "b");
+				print delete(m, "b");
+				print has(m, "a");
+				print has(m, "b");
+				print len(m);
+				var ks = keys(m);
+				var vs = values(m);
+				for (var i = 0; i < len(ks); i = i + 1) print [ks[i], vs[i]];
+			`,
+			Output: Dedent(`
+				true
+				false
+				true
+				false
+				2
+				[a, 1]
+				[c, 3]
+			`),
+		},
+		{
+			Name: "success/maps/brace_at_statement_start_is_block",
+			Source: `
+				{ print "block"; }
+				({"in": "parentheses"});
+			`,
+			Output: "block\n",
+		},
+		{
+			Name:          "error/maps/undefined_key",
+			Source:        `print {"a": 1}["b"];`,
+			ExpectedError: ierrors.ErrUndefinedKey,
+		},
+		{
+			Name:          "error/maps/unhashable_key",
+			Source:        `var m = {}; m[[]] = 1;`,
+			ExpectedError: ierrors.ErrUnhashableKey,
+		},
+		{
+			Name:          "error/maps/unhashable_key_in_literal",
+			Source:        `print {{}: 1};`,
+			ExpectedError: ierrors.ErrUnhashableKey,
+		},
+		{
+			Name:          "error/maps/nan_key",
+			Source:        `var m = {}; m[0/0] = 1;`,
+			ExpectedError: ierrors.ErrUnhashableKey,
+		},
+		{
+			Name:          "error/maps/nan_key_in_literal",
+			Source:        `var m = {0/0: 1};`,
+			ExpectedError: ierrors.ErrUnhashableKey,
+		},
+		{
+			Name:   "success/maps/nan_is_never_a_key",
+			Source: `print has({1: 2}, 0/0);`,
+			Output: "false\n",
+		},
+		{
+			Name:          "error/maps/keys_of_non_map",
+			Source:        `keys([]);`,
+			ExpectedError: ierrors.ErrNonMapType,
+		},
+		{
+			Name: "success/strings/split_and_join",
+			Source: `
+				var fields = split("a,b,,c", ",");
+				print len(fields);
+				print join(fields, " | ");
+				print split("héllo", "");
+				print join([1, true, nil], "-");
+			`,
+			Output: Dedent(`
+				4
+				a | b |  | c
+				[h, é, l, l, o]
+				1-true-nil
+			`),
+		},
+		{
+			Name: "success/strings/code_points",
+			Source: `
+				var s = "naïve café";
+				print len(s);
+				print substr(s, 2, 5);
+				print indexOf(s, "café");
+				print indexOf(s, "tea");
+				print ord("é");
+				print chr(233) + chr(128512);
+			`,
+			Output: Dedent(`
+				10
+				ïve
+				6
+				-1
+				233
+				é😀
+			`),
+		},
+		{
+			Name: "success/strings/transforms",
+			Source: `
+				print upper("héllo");
+				print lower("ÀB");
+				print "[" + trim("   padded  ") + "]";
+				print replace("a-b-c", "-", "+");
+				print startsWith("glox", "gl");
+				print endsWith("glox", "gl");
+			`,
+			Output: Dedent(`
+				HÉLLO
+				àb
+				[padded]
+				a+b+c
+				true
+				false
+			`),
+		},
+		{
+			Name:          "error/strings/substr_out_of_range",
+			Source:        `substr("abc", 2, 4);`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+			Message:       "index out of range: 4",
+		},
+		{
+			Name:          "error/strings/substr_start_out_of_range",
+			Source:        `substr("abc", 4, 3);`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+			Message:       "index out of range: 4",
+		},
+		{
+			Name:          "error/strings/substr_backwards",
+			Source:        `substr("abc", 2, 1);`,
+			ExpectedError: ierrors.ErrIndexOutOfRange,
+			Message:       "substr end 1 is before start 2",
+		},
+		{
+			Name:          "error/strings/split_non_string",
+			Source:        `split(1, ",");`,
+			ExpectedError: ierrors.ErrNonStringType,
+		},
+		{
+			Name:          "error/strings/chr_invalid",
+			Source:        `chr(55296);`,
+			ExpectedError: ierrors.ErrInvalidCodePoint,
+		},
+		{
+			Name:          "error/strings/ord_of_several_characters",
+			Source:        `ord("ab");`,
+			ExpectedError: ierrors.ErrInvalidCodePoint,
+		},
+		{
+			Name: "success/math/functions",
+			Source: `
+				print floor(-2.5);
+				print ceil(2.1);
+				print round(2.5);
+				print abs(-3);
+				print sqrt(16);
+				print pow(2, 10);
+				print min(3, -1);
+				print max(3, -1);
+				print round(sin(pi / 2) * 1000);
+				print log(1);
+				print log10(1000);
+				print atan2(0, -1) == pi;
+			`,
+			Output: Dedent(`
+				-3
+				3
+				3
+				3
+				4
+				1024
+				-1
+				3
+				1000
+				0
+				3
+				true
+			`),
+		},
+		{
+			Name: "success/math/constants",
+			Source: `
+				print toFixed(pi, 4);
+				print inf > 1000000;
+				print -inf;
+				print nan == nan;
+			`,
+			Output: Dedent(`
+				3.1416
+				true
+				-Inf
+				false
+			`),
+		},
+		{
+			Name: "success/math/random",
+			Source: `
+				seed(42);
+				var first = random();
+				seed(42);
+				print random() == first;
+				print first >= 0 and first < 1;
+			`,
+			Output: Dedent(`
+				true
+				true
+			`),
+		},
+		{
+			Name: "success/math/parse_number",
+			Source: `
+				print parseNumber("3.25") + 1;
+				print parseNumber("-12");
+				try { parseNumber("twelve"); } catch (e) { print e.message; }
+			`,
+			Output: Dedent(`
+				4.25
+				-12
+				invalid number: "twelve"
+			`),
+		},
+		{
+			Name:          "error/math/non_numeric",
+			Source:        `sqrt("four");`,
+			ExpectedError: ierrors.ErrNonNumericType,
+		},
+		{
+			Name:          "error/math/parse_number",
+			Source:        `parseNumber("");`,
+			ExpectedError: loxtype.ErrInvalidNumber,
+		},
+		{
+			Name:          "error/math/too_many_digits",
+			Source:        `toFixed(1, 200);`,
+			ExpectedError: ierrors.ErrArgumentRange,
+			Message:       "toFixed digits must be a whole number from 0 to 100, not 200",
+		},
+		{
+			Name:          "error/math/fractional_digits",
+			Source:        `toFixed(1, 1.5);`,
+			ExpectedError: ierrors.ErrArgumentRange,
+			Message:       "toFixed digits must be a whole number from 0 to 100, not 1.5",
+		},
+		{
+			Name: "success/exceptions/catch_thrown_value",
+			Source: `
+				try {
+					print "before";
+					throw [1, 2];
+					print "after";
+				} catch (e) {
+					print e.message;
+					print e.line;
+					print e.value[1];
+				}
+			`,
+			Output: Dedent(`
+				before
+				[1, 2]
+				4
+				2
+			`),
+		},
+		{
+			Name: "success/exceptions/catch_runtime_errors",
+			Source: `
+				try { -"one"; } catch (e) { print e.message; }
+				try { missing; } catch (e) { print e.message; }
+				try { [][0]; } catch (e) { print e.value; }
+			`,
+			Output: Dedent(`
+				cannot apply minus operator: non-numeric type-error
+				undefined variable: missing
+				index out of range: 0
+			`),
+		},
+		{
+			Name: "success/exceptions/unwind_calls",
+			Source: `
+				fun fail(n) {
+					if (n == 0) throw "bottom";
+					fail(n - 1);
+				}
+				fun add(a, b) { return a + b; }
+				try { print add(1, fail(20)); } catch (e) { print e; }
+				print add(1, 2);
+			`,
+			Output: "bottom\n3\n",
+		},
+		{
+			Name: "success/exceptions/finally",
+			Source: `
+				try { print "try"; } finally { print "finally"; }
+				try { throw "oops"; } catch (e) { print e; } finally { print "finally"; }
+				try {
+					try { throw "inner"; } finally { print "inner finally"; }
+				} catch (e) {
+					print "outer caught " + e.message;
+				}
+			`,
+			Output: Dedent(`
+				try
+				finally
+				oops
+				finally
+				inner finally
+				outer caught inner
+			`),
+		},
+		{
+			Name: "success/exceptions/finally_on_return",
+			Source: `
+				fun f() {
+					var local = "kept";
+					try {
+						try { return local; } finally { print "inner"; }
+					} finally {
+						print "outer";
+					}
+				}
+				print f();
+				fun g() {
+					try { return "try"; } finally { return "finally"; }
+				}
+				print g();
+			`,
+			Output: Dedent(`
+				inner
+				outer
+				kept
+				finally
+			`),
+		},
+		{
+			Name: "success/exceptions/rethrow",
+			Source: `
+				var first;
+				try {
+					try { throw "again"; } catch (e) { first = e; throw e; }
+				} catch (e) {
+					print e == first;
+					print e.line;
+				}
+			`,
+			Output: "true\n4\n",
+		},
+		{
+			Name: "success/exceptions/throw_out_of_loop",
+			Source: `
+				fun f() {
+					try {
+						while (true) {
+							for (var i = 0; i < 3; i = i + 1) {
+								if (i == 2) throw i;
+							}
+						}
+					} finally {
+						print "left loops";
+					}
+					print "unreachable";
+				}
+				try { f(); } catch (e) { print e; }
+				fun g() {
+					try { return "try"; } finally { throw "finally"; }
+				}
+				try { print g(); } catch (e) { print e; }
+			`,
+			Output: Dedent(`
+				left loops
+				2
+				finally
+			`),
+		},
+		{
+			Name:          "error/exceptions/uncaught",
+			Source:        `try { throw "oops"; } finally { print "finally"; }`,
+			ExpectedError: ierrors.ErrThrown,
+		},
+		{
+			Name:          "error/exceptions/rethrow_runtime_error",
+			Source:        `try { print -"one"; } catch (e) { throw e; }`,
+			ExpectedError: ierrors.ErrNonNumericType,
+		},
+		{
+			Name:          "error/exceptions/try_without_handler",
+			Source:        `try { print 1; }`,
+			ExpectedError: parser.ErrTryWithoutHandler,
+		},
+		{
+			Name: "error/exit_is_uncaught",
+			Source: `
+				try { exit(); } catch (e) { print "caught"; } finally { print "finally"; }
+			`,
+			ExpectedError: ierrors.ErrExit,
+			Message:       "exit status 0",
+		},
+		{
+			Name:          "error/exit_with_status",
+			Source:        `exit(3);`,
+			ExpectedError: ierrors.ErrExit,
+			Message:       "exit status 3",
+		},
+		{
+			Name:          "error/exit_status_not_integer",
+			Source:        `exit(1.5);`,
+			ExpectedError: ierrors.ErrArgumentRange,
+			Message:       "exit status must be a whole number from 0 to 255, not 1.5",
+		},
+		{
+			Name:          "error/exit_too_many_arguments",
+			Source:        `exit(1, 2);`,
+			ExpectedError: ierrors.ErrArgumentCount,
+			Message:       "expected 0 to 1 but got 2",
+		},
+		{
+			Name:          "error/limits/stack_overflow",
+			Source:        `fun f() { f(); } f();`,
+			ExpectedError: ierrors.ErrStackOverflow,
+			Diagnostic: "1:13: error at ')': stack overflow\n" +
+				" 1 | fun f() { f(); } f();\n" +
+				"   |             ^\n" +
+				"  at f (line 1)\n" +
+				"  ... repeated 255 more times\n" +
+				"  at <script> (line 1)",
+		},
+		{
+			Name: "error/limits/stack_overflow_is_uncaught",
+			Source: `
+				fun f() { f(); }
+				try { f(); } catch (e) { print "caught"; }
+			`,
+			ExpectedError: ierrors.ErrStackOverflow,
+		},
+		{
+			Name: "success/loops/break_and_continue",
+			Source: `
+				for (var i = 0; i < 10; i = i + 1) {
+					if (i == 1) continue;
+					if (i == 4) break;
+					print i;
+				}
+				var n = 0;
+				while (true) {
+					n = n + 1;
+					if (n < 3) continue;
+					break;
+				}
+				print n;
+			`,
+			Output: "0\n2\n3\n3\n",
+		},
+		{
+			Name: "success/loops/break_inner_loop",
+			Source: `
+				for (var a = 0; a < 2; a = a + 1) {
+					for (var b = 0; b < 3; b = b + 1) {
+						var product = a * b;
+						if (b == 1) break;
+						print [a, b];
+					}
+				}
+			`,
+			Output: "[0, 0]\n[1, 0]\n",
+		},
+		{
+			Name: "success/loops/closures_survive_break",
+			Source: `
+				var f;
+				while (true) {
+					var captured = "captured";
+					fun get() { return captured; }
+					f = get;
+					break;
+				}
+				print f();
+			`,
+			Output: "captured\n",
+		},
+		{
+			Name: "success/loops/leave_try_statements",
+			Source: `
+				for (var i = 0; i < 3; i = i + 1) {
+					try {
+						if (i == 0) continue;
+						if (i == 1) break;
+					} finally {
+						print i;
+					}
+				}
+				fun f() {
+					while (true) {
+						try { return "returned"; } finally { break; }
+					}
+					return "broke";
+				}
+				print f();
+			`,
+			Output: "0\n1\nbroke\n",
+		},
+		{
+			Name:          "error/loops/break_outside_loop",
+			Source:        `break;`,
+			ExpectedError: resolver.ErrBreakOutsideLoop,
+		},
+		{
+			Name:          "error/loops/continue_in_function_in_loop",
+			Source:        `while (true) { fun f() { continue; } }`,
+			ExpectedError: resolver.ErrContinueOutsideLoop,
+		},
+		{
+			Name:          "error/functions/argument_count",
+			Source:        `fun f(a, b) {} f(1);`,
+			ExpectedError: ierrors.ErrArgumentCount,
+		},
+		{
+			Name:          "error/classes/initializer_argument_count",
+			Source:        `class A {} A(1);`,
+			ExpectedError: ierrors.ErrArgumentCount,
+		},
+		{
+			Name:          "error/functions/not_callable",
+			Source:        `var a = 1; a();`,
+			ExpectedError: ierrors.ErrNotCallable,
+		},
+		{
+			Name:          "error/undefined_variable",
+			Source:        `print missing;`,
+			ExpectedError: environment.ErrUndefinedVariable,
+		},
+		{
+			Name:          "error/assign_undefined_variable",
+			Source:        `missing = 1;`,
+			ExpectedError: environment.ErrUndefinedVariable,
+		},
+		{
+			Name:          "error/add_mismatched_types",
+			Source:        `print 1 + "one";`,
+			ExpectedError: ierrors.ErrType,
+		},
+		{
+			Name:          "error/rendered_within_call",
+			Source:        "fun add(a, b) {\n\treturn a + b;\n}\nadd(1, \"one\");",
+			ExpectedError: ierrors.ErrType,
+			Diagnostic: "2:11: error at '+': operands to plus expression must be either string or numeric: type-error\n" +
+				" 2 | \treturn a + b;\n" +
+				"   | \t         ^\n" +
+				"  at add (line 2)\n" +
+				"  at <script> (line 4)",
+		},
+		{
+			Name:          "error/return_from_top_level",
+			Source:        `return 1;`,
+			ExpectedError: resolver.ErrReturnFromTopLevel,
+		},
+		{
+			Name:          "error/read_local_in_own_initializer",
+			Source:        `{ var a = 1; { var a = a; } }`,
+			ExpectedError: resolver.ErrReadInOwnInitializer,
+		},
+		{
+			Name: "success/imports/namespace",
+			Files: map[string]string{
+				"lib/greet.lox": `var greeting = "hello"; fun greet(name) { return greeting + ", " + name; }`,
+			},
+			Source: `
+				import "lib/greet.lox" as greet;
+				print greet.greet("world");
+				print greet.greeting;
+			`,
+			Output: "hello, world\nhello\n",
+		},
+		{
+			Name: "success/imports/once",
+			Files: map[string]string{
+				"a.lox":     `import "count.lox" as count;`,
+				"count.lox": `print "loading";`,
+			},
+			Source: `import "count.lox" as first; import "a.lox" as a; import "count.lox" as again;`,
+			Output: "loading\n",
+		},
+		{
+			Name: "success/imports/own_globals",
+			Files: map[string]string{
+				"lib.lox": `var name = "lib"; fun get() { return name; }`,
+			},
+			Source: `
+				var name = "script";
+				fun f() {
+					import "lib.lox" as lib;
+					return lib.get();
+				}
+				print f();
+				print name;
+			`,
+			Output: "lib\nscript\n",
+		},
+		{
+			Name: "success/imports/failed_import_runs_again",
+			Files: map[string]string{
+				"bad.lox": `print "loading"; throw "bad";`,
+			},
+			Source: `
+				for (var i = 0; i < 2; i = i + 1) {
+					try {
+						import "bad.lox" as bad;
+					} catch (e) {
+						print e.message;
+					}
+				}
+			`,
+			Output: "loading\nbad\nloading\nbad\n",
+		},
+	}
+}
+
+// numbered lists n items, separated by commas, each formatted with its index.
+func numbered(n int, format string) string {
+	items := make([]string, n)
+	for k := range items {
+		items[k] = fmt.Sprintf(format, k)
+	}
+	return strings.Join(items, ", ")
+}
+
+// Dedent drops the first line of s and the indentation of the rest,
+// so that expected output can be written indented within a raw string.
+func Dedent(s string) string {
+	var (
+		bob             strings.Builder
+		passedFirstLine bool
+	)
+	for line := range strings.Lines(s) {
+		if !passedFirstLine {
+			passedFirstLine = true
+			continue
+		}
+		bob.WriteString(strings.TrimLeft(line, "\t "))
+	}
+	return bob.String()
+}
@@ -1,8 +1,12 @@
 package loxtype
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/matt-hoiland/glox/internal/runes"
 )
@@ -42,6 +46,200 @@ func (b Boolean) String() string {
 	return "false"
 }
 
+// Error is what a catch clause receives, whether a program threw a value or failed while running.
+// It's also the Go error that carries a thrown value out of the backend when nothing catches it.
+type Error struct {
+	Message string
+	Line    int
+	// Value is the value thrown, or the message of a runtime error.
+	Value Type
+	// Err is the runtime error the program failed with, as the backend reported it, if it didn't throw a value.
+	Err error
+	// Origin is what the program failed with when the error was caught, so that rethrowing it fails the same way.
+	// It isn't unwrapped, since it may wrap the error itself.
+	Origin error
+}
+
+var (
+	_ Type  = (*Error)(nil)
+	_ error = (*Error)(nil)
+)
+
+func (e *Error) Equals(other Type) Boolean {
+	o, ok := other.(*Error)
+	return Boolean(ok && o == e)
+}
+
+func (*Error) IsTruthy() Boolean {
+	return true
+}
+
+func (e *Error) String() string {
+	return e.Message
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Get returns one of the error's properties: message, line, or value.
+func (e *Error) Get(name string) (Type, bool) {
+	switch name {
+	case "message":
+		return String(e.Message), true
+	case "line":
+		return Number(e.Line), true
+	case "value":
+		return e.Value, true
+	default:
+		return nil, false
+	}
+}
+
+// List is a mutable sequence of values. Lists are shared rather than copied, so they're only equal to themselves.
+type List struct {
+	Elements []Type
+}
+
+var _ Type = (*List)(nil)
+
+func (l *List) Equals(other Type) Boolean {
+	o, ok := other.(*List)
+	return Boolean(ok && o == l)
+}
+
+func (*List) IsTruthy() Boolean {
+	return true
+}
+
+func (l *List) String() string {
+	var builder strings.Builder
+	write(&builder, l, map[Type]bool{})
+	return builder.String()
+}
+
+// Map is a mutable collection of values by key, kept in the order their keys were first set.
+// Keys must be [Hashable], so that they're equal exactly when [Type.Equals] says they are.
+type Map struct {
+	keys   []Type
+	values map[Type]Type
+}
+
+var _ Type = (*Map)(nil)
+
+func NewMap() *Map {
+	return &Map{values: map[Type]Type{}}
+}
+
+// Hashable reports whether a value can be a key of a [Map]. NaN can't, since it never equals itself,
+// so a key set to it could never be found again.
+func Hashable(key Type) bool {
+	switch k := key.(type) {
+	case Number:
+		return !math.IsNaN(float64(k))
+	case Boolean, Nil, String:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *Map) Equals(other Type) Boolean {
+	o, ok := other.(*Map)
+	return Boolean(ok && o == m)
+}
+
+func (*Map) IsTruthy() Boolean {
+	return true
+}
+
+func (m *Map) String() string {
+	var builder strings.Builder
+	write(&builder, m, map[Type]bool{})
+	return builder.String()
+}
+
+// Get returns the value of a key, and whether the key is set at all.
+func (m *Map) Get(key Type) (Type, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set sets the value of a key, which must be [Hashable].
+func (m *Map) Set(key, value Type) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes a key and reports whether it was set.
+func (m *Map) Delete(key Type) bool {
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k Type) bool { return k == key })
+	return true
+}
+
+// Keys returns the keys in the order they were first set.
+func (m *Map) Keys() []Type {
+	return slices.Clone(m.keys)
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// write writes a value, writing lists and maps that contain themselves as "[...]" or "{...}" where they reappear.
+func write(builder *strings.Builder, value Type, seen map[Type]bool) {
+	switch v := value.(type) {
+	case *List:
+		if seen[v] {
+			builder.WriteString("[...]")
+			return
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		builder.WriteRune('[')
+		for i, element := range v.Elements {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			write(builder, element, seen)
+		}
+		builder.WriteRune(']')
+
+	case *Map:
+		if seen[v] {
+			builder.WriteString("{...}")
+			return
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		builder.WriteRune('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(key.String())
+			builder.WriteString(": ")
+			write(builder, v.values[key], seen)
+		}
+		builder.WriteRune('}')
+
+	default:
+		builder.WriteString(value.String())
+	}
+}
+
 type Nil struct{}
 
 var _ Type = Nil{}
@@ -65,12 +263,17 @@ type Number float64
 
 var _ Type = Number(0)
 
-func ParseNumber(text []runes.Rune) Number {
+// ErrInvalidNumber is returned when text doesn't represent a number.
+var ErrInvalidNumber = errors.New("invalid number")
+
+// ParseNumber parses text as a number, or fails with [ErrInvalidNumber] if it isn't one
+// or is too large in magnitude to represent.
+func ParseNumber(text []runes.Rune) (Number, error) {
 	f64, err := strconv.ParseFloat(string(text), 64)
 	if err != nil {
-		return Number(0)
+		return 0, fmt.Errorf("%w: %q", ErrInvalidNumber, string(text))
 	}
-	return Number(f64)
+	return Number(f64), nil
 }
 
 func (n Number) Add(right Number) Number {
@@ -1,9 +1,11 @@
 package loxtype_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/matt-hoiland/glox/internal/loxtype"
 	"github.com/matt-hoiland/glox/internal/runes"
@@ -15,6 +17,64 @@ func TestBoolean_String(t *testing.T) {
 	assert.Equal(t, "false", bf.String())
 }
 
+func TestList_String(t *testing.T) {
+	t.Parallel()
+
+	empty := &loxtype.List{}
+	assert.Equal(t, "[]", empty.String())
+
+	l := &loxtype.List{Elements: []loxtype.Type{loxtype.Number(1), loxtype.String("two"), empty, loxtype.Nil{}}}
+	assert.Equal(t, "[1, two, [], nil]", l.String())
+
+	l.Elements = append(l.Elements, l)
+	assert.Equal(t, "[1, two, [], nil, [...]]", l.String())
+}
+
+func TestList_Equals(t *testing.T) {
+	t.Parallel()
+
+	a, b := &loxtype.List{}, &loxtype.List{}
+	assert.True(t, bool(a.Equals(a)))
+	assert.False(t, bool(a.Equals(b)))
+	assert.False(t, bool(a.Equals(loxtype.Nil{})))
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	m := loxtype.NewMap()
+	assert.Equal(t, "{}", m.String())
+
+	m.Set(loxtype.String("b"), loxtype.Number(1))
+	m.Set(loxtype.Number(2), loxtype.Boolean(true))
+	m.Set(loxtype.Nil{}, m)
+	m.Set(loxtype.String("b"), loxtype.Number(3))
+	assert.Equal(t, "{b: 3, 2: true, nil: {...}}", m.String())
+	assert.Equal(t, 3, m.Len())
+
+	// Keys are found by value, just as Equals compares them.
+	value, ok := m.Get(loxtype.Number(4).Divide(2))
+	assert.True(t, ok)
+	assert.Equal(t, loxtype.Boolean(true), value)
+	_, ok = m.Get(loxtype.String("2"))
+	assert.False(t, ok)
+
+	assert.True(t, m.Delete(loxtype.Number(2)))
+	assert.False(t, m.Delete(loxtype.Number(2)))
+	assert.Equal(t, []loxtype.Type{loxtype.String("b"), loxtype.Nil{}}, m.Keys())
+}
+
+func TestHashable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, loxtype.Hashable(loxtype.String("")))
+	assert.True(t, loxtype.Hashable(loxtype.Number(0)))
+	assert.True(t, loxtype.Hashable(loxtype.Boolean(false)))
+	assert.True(t, loxtype.Hashable(loxtype.Nil{}))
+	assert.False(t, loxtype.Hashable(&loxtype.List{}))
+	assert.False(t, loxtype.Hashable(loxtype.NewMap()))
+}
+
 func TestNil_String(t *testing.T) {
 	assert.Equal(t, "nil", loxtype.Nil{}.String())
 }
@@ -25,15 +85,23 @@ func TestParseNumber(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		s := []runes.Rune("3.14")
-		n := loxtype.ParseNumber(s)
+		n, err := loxtype.ParseNumber(s)
+		require.NoError(t, err)
 		assert.InEpsilon(t, 3.14, float64(n), 0.001)
 	})
 
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
 		s := []runes.Rune("banana")
-		n := loxtype.ParseNumber(s)
-		assert.Zero(t, n)
+		_, err := loxtype.ParseNumber(s)
+		require.ErrorIs(t, err, loxtype.ErrInvalidNumber)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		t.Parallel()
+		s := []runes.Rune("1" + strings.Repeat("0", 400))
+		_, err := loxtype.ParseNumber(s)
+		require.ErrorIs(t, err, loxtype.ErrInvalidNumber)
 	})
 }
 
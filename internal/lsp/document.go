@@ -0,0 +1,169 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/resolver"
+	"github.com/matt-hoiland/glox/internal/scanner"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+// document is an open file, analyzed whenever its text changes.
+type document struct {
+	text string
+	// lines holds the offset at which each line starts.
+	lines []int
+
+	diagnostics []Diagnostic
+	bindings    []*resolver.Binding
+	symbols     []DocumentSymbol
+}
+
+// newDocument scans, parses, and resolves text. The resolver's errors are only reported for text without syntax
+// errors, since the statements the parser skips past can leave others where they don't belong.
+func newDocument(text string) *document {
+	d := &document{text: text, lines: []int{0}, diagnostics: []Diagnostic{}}
+	for i, c := range []byte(text) {
+		if c == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+
+	tokens, scanErr := scanner.New(text).ScanTokens()
+	stmts, parseErr := parser.New(tokens).Parse()
+	d.report(scanErr)
+	d.report(parseErr)
+
+	r := resolver.New(map[ast.Expr]int{}, resolver.WithBindings())
+	if err := r.Resolve(stmts); err != nil && len(d.diagnostics) == 0 {
+		d.report(err)
+	}
+	d.bindings = r.Bindings()
+	d.symbols = d.symbolsOf(stmts)
+	return d
+}
+
+// report adds diagnostics for an error, which is either an [ierrors.List] or a single [ierrors.Error].
+func (d *document) report(err error) {
+	var list ierrors.List
+	if errors.As(err, &list) {
+		for _, e := range list {
+			d.report(e)
+		}
+		return
+	}
+	var e *ierrors.Error
+	if !errors.As(err, &e) {
+		return
+	}
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range:    Range{Start: d.position(e.Offset), End: d.position(e.Offset + e.Length)},
+		Severity: DiagnosticSeverityError,
+		Source:   "glox",
+		Message:  e.Err.Error(),
+	})
+}
+
+// symbolsOf lists the functions, classes, and variables that statements declare, along with those they declare
+// within their bodies.
+func (d *document) symbolsOf(stmts []ast.Stmt) []DocumentSymbol {
+	var symbols []DocumentSymbol
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.FunctionStmt:
+			symbols = append(symbols, d.symbol(s.Name, SymbolKindFunction, d.symbolsOf(s.Body)))
+		case *ast.ClassStmt:
+			methods := make([]DocumentSymbol, len(s.Methods))
+			for k, m := range s.Methods {
+				methods[k] = d.symbol(m.Name, SymbolKindMethod, d.symbolsOf(m.Body))
+			}
+			symbols = append(symbols, d.symbol(s.Name, SymbolKindClass, methods))
+		case *ast.VarStmt:
+			symbols = append(symbols, d.symbol(s.Name, SymbolKindVariable, nil))
+		case *ast.BlockStmt:
+			symbols = append(symbols, d.symbolsOf(s.Statements)...)
+		}
+	}
+	return symbols
+}
+
+// symbol makes a symbol whose range is its name, since the syntax tree doesn't record where declarations end.
+func (d *document) symbol(name *token.Token, kind SymbolKind, children []DocumentSymbol) DocumentSymbol {
+	return DocumentSymbol{
+		Name:           name.Lexeme,
+		Kind:           kind,
+		Range:          d.tokenRange(name),
+		SelectionRange: d.tokenRange(name),
+		Children:       children,
+	}
+}
+
+// lookup finds the binding declared or referenced by the name at a position, and the token of that name.
+func (d *document) lookup(p Position) (*resolver.Binding, *token.Token) {
+	offset := d.offset(p)
+	touches := func(tok *token.Token) bool {
+		return tok.Offset <= offset && offset <= tok.Offset+tok.Length
+	}
+	for _, b := range d.bindings {
+		if touches(b.Name) {
+			return b, b.Name
+		}
+		for _, ref := range b.References {
+			if touches(ref) {
+				return b, ref
+			}
+		}
+	}
+	return nil, nil
+}
+
+// describe says what a binding is, for hovering over it.
+func describe(b *resolver.Binding) string {
+	scope := "local"
+	if b.Global {
+		scope = "global"
+	}
+	return fmt.Sprintf("%s %s, declared on line %d", scope, b.Name.Lexeme, b.Name.Line)
+}
+
+func (d *document) tokenRange(tok *token.Token) Range {
+	return Range{Start: d.position(tok.Offset), End: d.position(tok.Offset + tok.Length)}
+}
+
+// position converts a byte offset into the text to a position, counting characters in UTF-16 code units.
+func (d *document) position(offset int) Position {
+	offset = min(max(offset, 0), len(d.text))
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > offset }) - 1
+	character := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// offset converts a position to a byte offset into the text, clamping it to the line it's on.
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset, end := d.lines[p.Line], len(d.text)
+	if p.Line+1 < len(d.lines) {
+		end = d.lines[p.Line+1] - 1
+	}
+	for character := 0; offset < end && character < p.Character; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		character += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,119 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// JSON-RPC error codes, including those the Language Server Protocol adds.
+const (
+	CodeParseError           = -32700
+	CodeInvalidRequest       = -32600
+	CodeMethodNotFound       = -32601
+	CodeInvalidParams        = -32602
+	CodeInternalError        = -32603
+	CodeServerNotInitialized = -32002
+)
+
+// MaxContentLength is the length of the largest message the server reads, which bounds what a client can make it
+// allocate.
+const MaxContentLength = 64 << 20
+
+var ErrMissingContentLength = errors.New("missing Content-Length header")
+
+// Message is a JSON-RPC request, notification, or response. Requests have an ID and a method, notifications have
+// only a method, and responses have only an ID, along with either a result or an error.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// ResponseError is the error a response reports when a request fails.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// Conn reads and writes JSON-RPC messages over a stream, each preceded by a Content-Length header,
+// as the Language Server Protocol frames them. Messages may be written from several goroutines at once.
+type Conn struct {
+	r  *textproto.Reader
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// Read reads the next message. It returns [io.EOF] when the stream ends between messages,
+// and a [ResponseError] for a message that can be skipped but not understood.
+func (c *Conn) Read() (*Message, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMissingContentLength, err)
+	}
+	if length < 0 || length > MaxContentLength {
+		// The body of a message that's too long is skipped, so that the next can still be read.
+		if _, err = io.CopyN(io.Discard, c.r.R, int64(max(length, 0))); err != nil {
+			return nil, err
+		}
+		return nil, &ResponseError{Code: CodeInvalidRequest, Message: fmt.Sprintf("invalid Content-Length: %d", length)}
+	}
+
+	body := make([]byte, length)
+	if _, err = io.ReadFull(c.r.R, body); err != nil {
+		return nil, err
+	}
+	var m Message
+	if err = json.Unmarshal(body, &m); err != nil {
+		return nil, &ResponseError{Code: CodeParseError, Message: err.Error()}
+	}
+	return &m, nil
+}
+
+// Write writes a message, filling in the protocol version.
+func (c *Conn) Write(m *Message) error {
+	m.JSONRPC = "2.0"
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(body)
+	return err
+}
+
+// Notify writes a notification.
+func (c *Conn) Notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.Write(&Message{Method: method, Params: raw})
+}
@@ -0,0 +1,262 @@
+// Package lsp serves the Language Server Protocol over a stream, so that editors can show the errors in Lox files
+// as they're edited and navigate between the declarations of names and the references to them.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrExitWithoutShutdown is returned by [Server.Serve] when the client says to exit without first asking the server
+// to shut down, which the protocol treats as the server failing.
+var ErrExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server answers the requests of a single client, one at a time.
+type Server struct {
+	conn        *Conn
+	documents   map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// handler answers a request, or acts on a notification, whose params it's given still encoded.
+type handler func(s *Server, params json.RawMessage) (any, error)
+
+func handlers() map[string]handler {
+	return map[string]handler{
+		"initialize":                  (*Server).initialize,
+		"initialized":                 ignore,
+		"shutdown":                    (*Server).shutdownRequest,
+		"textDocument/didOpen":        (*Server).didOpen,
+		"textDocument/didChange":      (*Server).didChange,
+		"textDocument/didClose":       (*Server).didClose,
+		"textDocument/definition":     (*Server).definition,
+		"textDocument/references":     (*Server).references,
+		"textDocument/hover":          (*Server).hover,
+		"textDocument/documentSymbol": (*Server).documentSymbol,
+	}
+}
+
+// NewServer returns a server that reads the client's messages from in and writes its own to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{conn: NewConn(in, out), documents: map[string]*document{}}
+}
+
+// Serve answers messages until the client says to exit or closes the stream.
+func (s *Server) Serve() error {
+	table := handlers()
+	for {
+		m, err := s.conn.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if rerr := (*ResponseError)(nil); errors.As(err, &rerr) {
+			if err = s.conn.Write(&Message{ID: json.RawMessage("null"), Error: rerr}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if m.ID == nil {
+			// Notifications have no response to report failures in, so they're dropped.
+			if h, ok := table[m.Method]; ok && s.initialized && !s.shutdown {
+				_, _ = h(s, m.Params)
+			}
+			continue
+		}
+		if err = s.respond(m, table[m.Method]); err != nil {
+			return err
+		}
+	}
+}
+
+// respond answers a request with the result of its handler, or with an error if there's no handler for it,
+// it can't be answered yet or anymore, or the handler fails.
+func (s *Server) respond(m *Message, h handler) error {
+	var (
+		result any
+		err    error
+	)
+	switch {
+	case h == nil:
+		err = &ResponseError{Code: CodeMethodNotFound, Message: "method not found: " + m.Method}
+	case !s.initialized && m.Method != "initialize":
+		err = &ResponseError{Code: CodeServerNotInitialized, Message: "server not initialized"}
+	case s.shutdown:
+		err = &ResponseError{Code: CodeInvalidRequest, Message: "server is shutting down"}
+	default:
+		result, err = h(s, m.Params)
+	}
+
+	response := &Message{ID: m.ID}
+	if err != nil {
+		rerr := (*ResponseError)(nil)
+		if !errors.As(err, &rerr) {
+			rerr = &ResponseError{Code: CodeInternalError, Message: err.Error()}
+		}
+		response.Error = rerr
+		return s.conn.Write(response)
+	}
+	if response.Result, err = json.Marshal(result); err != nil {
+		return err
+	}
+	return s.conn.Write(response)
+}
+
+// decode decodes a request's params, failing as the protocol expects when they're malformed.
+func decode[T any](params json.RawMessage) (T, error) {
+	var v T
+	if err := json.Unmarshal(params, &v); err != nil {
+		return v, &ResponseError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return v, nil
+}
+
+func ignore(*Server, json.RawMessage) (any, error) {
+	return nil, nil //nolint:nilnil // Notifications have no result.
+}
+
+func (s *Server) initialize(json.RawMessage) (any, error) {
+	s.initialized = true
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:       TextDocumentSyncKindFull,
+			DefinitionProvider:     true,
+			ReferencesProvider:     true,
+			HoverProvider:          true,
+			DocumentSymbolProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "glox"},
+	}, nil
+}
+
+func (s *Server) shutdownRequest(json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil //nolint:nilnil // The result of shutdown is null.
+}
+
+func (s *Server) didOpen(params json.RawMessage) (any, error) {
+	p, err := decode[DidOpenTextDocumentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.update(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) didChange(params json.RawMessage) (any, error) {
+	p, err := decode[DidChangeTextDocumentParams](params)
+	if err != nil || len(p.ContentChanges) == 0 {
+		return nil, err
+	}
+	return nil, s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params json.RawMessage) (any, error) {
+	p, err := decode[DidCloseTextDocumentParams](params)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.documents, p.TextDocument.URI)
+	return nil, s.conn.Notify("textDocument/publishDiagnostics",
+		PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+// update analyzes a document's new text and publishes its diagnostics.
+func (s *Server) update(uri, text string) error {
+	d := newDocument(text)
+	s.documents[uri] = d
+	return s.conn.Notify("textDocument/publishDiagnostics",
+		PublishDiagnosticsParams{URI: uri, Diagnostics: d.diagnostics})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil, &ResponseError{Code: CodeInvalidParams, Message: fmt.Sprintf("document not open: %s", uri)}
+	}
+	return d, nil
+}
+
+// definition finds where the name at a position was declared. It has no result for names not declared in the file.
+func (s *Server) definition(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := d.lookup(p.Position)
+	if b == nil {
+		return nil, nil //nolint:nilnil // A null result means there's no definition.
+	}
+	return Location{URI: p.TextDocument.URI, Range: d.tokenRange(b.Name)}, nil
+}
+
+// references finds every reference to the name at a position, along with its declaration if asked.
+func (s *Server) references(params json.RawMessage) (any, error) {
+	p, err := decode[ReferenceParams](params)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	locations := []Location{}
+	b, _ := d.lookup(p.Position)
+	if b == nil {
+		return locations, nil
+	}
+	if p.Context.IncludeDeclaration {
+		locations = append(locations, Location{URI: p.TextDocument.URI, Range: d.tokenRange(b.Name)})
+	}
+	for _, ref := range b.References {
+		locations = append(locations, Location{URI: p.TextDocument.URI, Range: d.tokenRange(ref)})
+	}
+	return locations, nil
+}
+
+// hover says whether the name at a position is local or global, and where it was declared.
+func (s *Server) hover(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	b, tok := d.lookup(p.Position)
+	if b == nil {
+		return nil, nil //nolint:nilnil // A null result means there's nothing to show.
+	}
+	return Hover{Contents: MarkupContent{Kind: "plaintext", Value: describe(b)}, Range: d.tokenRange(tok)}, nil
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (any, error) {
+	p, err := decode[DocumentSymbolParams](params)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if d.symbols == nil {
+		return []DocumentSymbol{}, nil
+	}
+	return d.symbols, nil
+}
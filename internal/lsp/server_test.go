@@ -0,0 +1,356 @@
+package lsp_test
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/lsp"
+)
+
+const uri = "file:///script.lox"
+
+// client scripts a conversation with a server, reading what the server writes as it writes it,
+// so that the server never waits on the client to read a notification before reading the next request.
+type client struct {
+	t    *testing.T
+	conn *lsp.Conn
+	// raw writes to the server unframed, for sending malformed messages.
+	raw      io.Writer
+	messages chan *lsp.Message
+	served   chan error
+	id       int
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	c := &client{
+		t:        t,
+		conn:     lsp.NewConn(clientIn, clientOut),
+		raw:      clientOut,
+		messages: make(chan *lsp.Message, 16),
+		served:   make(chan error, 1),
+	}
+	go func() {
+		c.served <- lsp.NewServer(serverIn, serverOut).Serve()
+		serverOut.Close()
+	}()
+	go func() {
+		defer close(c.messages)
+		for {
+			m, err := c.conn.Read()
+			if err != nil {
+				return
+			}
+			c.messages <- m
+		}
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return c
+}
+
+// initialized is a client that has done the handshake that starts every conversation.
+func initialized(t *testing.T) *client {
+	t.Helper()
+	c := newClient(t)
+	var result lsp.InitializeResult
+	require.Nil(t, c.request("initialize", map[string]any{"capabilities": map[string]any{}}, &result))
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	require.NoError(c.t, c.conn.Notify(method, params))
+}
+
+// request sends a request and decodes the result of its response into result, returning the response's error.
+func (c *client) request(method string, params, result any) *lsp.ResponseError {
+	c.t.Helper()
+	c.id++
+	raw, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	id := json.RawMessage(strconv.Itoa(c.id))
+	require.NoError(c.t, c.conn.Write(&lsp.Message{ID: id, Method: method, Params: raw}))
+
+	m := c.next()
+	require.JSONEq(c.t, string(id), string(m.ID))
+	if m.Error == nil && result != nil {
+		require.NoError(c.t, json.Unmarshal(m.Result, result))
+	}
+	return m.Error
+}
+
+// diagnostics reads the diagnostics the server publishes next.
+func (c *client) diagnostics() lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+	m := c.next()
+	require.Equal(c.t, "textDocument/publishDiagnostics", m.Method)
+	var params lsp.PublishDiagnosticsParams
+	require.NoError(c.t, json.Unmarshal(m.Params, &params))
+	return params
+}
+
+func (c *client) next() *lsp.Message {
+	c.t.Helper()
+	m, ok := <-c.messages
+	require.True(c.t, ok, "server closed the connection")
+	return m
+}
+
+func (c *client) open(text string) lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "lox", Version: 1, Text: text},
+	})
+	return c.diagnostics()
+}
+
+func at(line, character int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func span(line, start, end int) lsp.Range {
+	return lsp.Range{Start: lsp.Position{Line: line, Character: start}, End: lsp.Position{Line: line, Character: end}}
+}
+
+const script = `var total = 0;
+fun add(n) {
+  var doubled = n * 2;
+  total = total + doubled;
+  return helper(doubled);
+}
+fun helper(x) { return x; }
+print add(1);
+`
+
+func TestServer_lifecycle(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t)
+	rerr := c.request("textDocument/hover", at(0, 0), nil)
+	require.NotNil(t, rerr)
+	assert.Equal(t, lsp.CodeServerNotInitialized, rerr.Code)
+
+	var result lsp.InitializeResult
+	require.Nil(t, c.request("initialize", map[string]any{}, &result))
+	assert.Equal(t, lsp.TextDocumentSyncKindFull, result.Capabilities.TextDocumentSync)
+	assert.True(t, result.Capabilities.DefinitionProvider)
+	assert.True(t, result.Capabilities.ReferencesProvider)
+	assert.True(t, result.Capabilities.HoverProvider)
+	assert.True(t, result.Capabilities.DocumentSymbolProvider)
+
+	rerr = c.request("textDocument/nope", map[string]any{}, nil)
+	require.NotNil(t, rerr)
+	assert.Equal(t, lsp.CodeMethodNotFound, rerr.Code)
+
+	require.Nil(t, c.request("shutdown", nil, nil))
+	c.notify("exit", nil)
+	require.NoError(t, <-c.served)
+}
+
+func TestServer_invalidContentLength(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	_, err := io.WriteString(c.raw, "Content-Length: -1\r\n\r\n")
+	require.NoError(t, err)
+
+	m := c.next()
+	assert.JSONEq(t, "null", string(m.ID))
+	require.NotNil(t, m.Error)
+	assert.Equal(t, lsp.CodeInvalidRequest, m.Error.Code)
+	assert.Equal(t, "invalid Content-Length: -1", m.Error.Message)
+
+	// The server carries on with the next message.
+	var hover *lsp.Hover
+	rerr := c.request("textDocument/hover", at(0, 0), &hover)
+	require.NotNil(t, rerr)
+	assert.Equal(t, lsp.CodeInvalidParams, rerr.Code)
+}
+
+func TestServer_exitWithoutShutdown(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	c.notify("exit", nil)
+	require.ErrorIs(t, <-c.served, lsp.ErrExitWithoutShutdown)
+}
+
+func TestServer_diagnostics(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name        string
+		Text        string
+		Diagnostics []lsp.Diagnostic
+	}
+
+	diagnostic := func(r lsp.Range, message string) lsp.Diagnostic {
+		return lsp.Diagnostic{Range: r, Severity: lsp.DiagnosticSeverityError, Source: "glox", Message: message}
+	}
+
+	tests := []Test{
+		{Name: "valid", Text: script, Diagnostics: []lsp.Diagnostic{}},
+		{
+			Name: "scanner_and_parser",
+			Text: "var a = @;\nprint (1 +;\n",
+			Diagnostics: []lsp.Diagnostic{
+				diagnostic(span(0, 8, 9), "unexpected rune"),
+				diagnostic(span(0, 9, 10), "expect expression"),
+				diagnostic(span(1, 10, 11), "expect expression"),
+			},
+		},
+		{
+			Name:        "resolver",
+			Text:        "print 1;\nreturn 2;\n",
+			Diagnostics: []lsp.Diagnostic{diagnostic(span(1, 0, 6), "can't return from top-level code")},
+		},
+		{
+			Name:        "utf16_columns",
+			Text:        "print \"😀\" + ;",
+			Diagnostics: []lsp.Diagnostic{diagnostic(span(0, 13, 14), "expect expression")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			c := initialized(t)
+			params := c.open(test.Text)
+			assert.Equal(t, uri, params.URI)
+			assert.Equal(t, test.Diagnostics, params.Diagnostics)
+		})
+	}
+}
+
+func TestServer_didChange(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	require.Len(t, c.open("print ;").Diagnostics, 1)
+
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.TextDocumentIdentifier{URI: uri},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: "print 1;"}},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	c.notify("textDocument/didClose", lsp.DidCloseTextDocumentParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+	rerr := c.request("textDocument/hover", at(0, 0), nil)
+	require.NotNil(t, rerr)
+	assert.Equal(t, lsp.CodeInvalidParams, rerr.Code)
+}
+
+func TestServer_definition(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name     string
+		Position lsp.TextDocumentPositionParams
+		Range    *lsp.Range
+	}
+
+	tests := []Test{
+		{Name: "local", Position: at(3, 20), Range: ptr(span(2, 6, 13))},
+		{Name: "global", Position: at(3, 2), Range: ptr(span(0, 4, 9))},
+		{Name: "parameter", Position: at(2, 16), Range: ptr(span(1, 8, 9))},
+		{Name: "declared_later", Position: at(4, 9), Range: ptr(span(6, 4, 10))},
+		{Name: "declaration_itself", Position: at(1, 5), Range: ptr(span(1, 4, 7))},
+		{Name: "end_of_name", Position: at(7, 9), Range: ptr(span(1, 4, 7))},
+		{Name: "not_a_name", Position: at(7, 1)},
+	}
+
+	c := initialized(t)
+	require.Empty(t, c.open(script).Diagnostics)
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var location *lsp.Location
+			require.Nil(t, c.request("textDocument/definition", test.Position, &location))
+			if test.Range == nil {
+				assert.Nil(t, location)
+				return
+			}
+			require.NotNil(t, location)
+			assert.Equal(t, lsp.Location{URI: uri, Range: *test.Range}, *location)
+		})
+	}
+}
+
+func TestServer_references(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	require.Empty(t, c.open(script).Diagnostics)
+
+	var locations []lsp.Location
+	params := lsp.ReferenceParams{TextDocumentPositionParams: at(3, 2)}
+	require.Nil(t, c.request("textDocument/references", params, &locations))
+	assert.Equal(t, []lsp.Location{{URI: uri, Range: span(3, 2, 7)}, {URI: uri, Range: span(3, 10, 15)}}, locations)
+
+	params.Context.IncludeDeclaration = true
+	require.Nil(t, c.request("textDocument/references", params, &locations))
+	assert.Len(t, locations, 3)
+	assert.Equal(t, span(0, 4, 9), locations[0].Range)
+
+	params = lsp.ReferenceParams{TextDocumentPositionParams: at(6, 5)}
+	require.Nil(t, c.request("textDocument/references", params, &locations))
+	assert.Equal(t, []lsp.Location{{URI: uri, Range: span(4, 9, 15)}}, locations)
+}
+
+func TestServer_hover(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	require.Empty(t, c.open(script).Diagnostics)
+
+	var hover *lsp.Hover
+	require.Nil(t, c.request("textDocument/hover", at(3, 20), &hover))
+	require.NotNil(t, hover)
+	assert.Equal(t, "local doubled, declared on line 3", hover.Contents.Value)
+	assert.Equal(t, span(3, 18, 25), hover.Range)
+
+	require.Nil(t, c.request("textDocument/hover", at(7, 7), &hover))
+	require.NotNil(t, hover)
+	assert.Equal(t, "global add, declared on line 2", hover.Contents.Value)
+
+	hover = nil
+	require.Nil(t, c.request("textDocument/hover", at(7, 0), &hover))
+	assert.Nil(t, hover)
+}
+
+func TestServer_documentSymbol(t *testing.T) {
+	t.Parallel()
+
+	c := initialized(t)
+	require.Empty(t, c.open(script+"class A { m() { var inner; } }\n").Diagnostics)
+
+	var symbols []lsp.DocumentSymbol
+	params := lsp.DocumentSymbolParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}}
+	require.Nil(t, c.request("textDocument/documentSymbol", params, &symbols))
+
+	symbol := func(name string, kind lsp.SymbolKind, r lsp.Range, children ...lsp.DocumentSymbol) lsp.DocumentSymbol {
+		return lsp.DocumentSymbol{Name: name, Kind: kind, Range: r, SelectionRange: r, Children: children}
+	}
+	assert.Equal(t, []lsp.DocumentSymbol{
+		symbol("total", lsp.SymbolKindVariable, span(0, 4, 9)),
+		symbol("add", lsp.SymbolKindFunction, span(1, 4, 7), symbol("doubled", lsp.SymbolKindVariable, span(2, 6, 13))),
+		symbol("helper", lsp.SymbolKindFunction, span(6, 4, 10)),
+		symbol("A", lsp.SymbolKindClass, span(8, 6, 7),
+			symbol("m", lsp.SymbolKindMethod, span(8, 10, 11), symbol("inner", lsp.SymbolKindVariable, span(8, 20, 25)))),
+	}, symbols)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
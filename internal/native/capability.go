@@ -0,0 +1,95 @@
+package native
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// Capability is a set of built-in functions that reach beyond the program into the host running it.
+// Hosts grant only those they trust a program with, so that untrusted programs can be run safely.
+type Capability uint8
+
+const (
+	// CapabilityTime reads the clock.
+	CapabilityTime Capability = 1 << iota
+	// CapabilityIO reads and writes the standard streams beyond what print does.
+	CapabilityIO
+	// CapabilityFS reads and writes files.
+	CapabilityFS
+	// CapabilityProcess controls the process running the program, such as by exiting it.
+	CapabilityProcess
+	// CapabilityEnv reads environment variables.
+	CapabilityEnv
+
+	// NoCapabilities grants only the functions that work on values.
+	NoCapabilities Capability = 0
+	// AllCapabilities grants everything, as the command line does.
+	AllCapabilities = CapabilityTime | CapabilityIO | CapabilityFS | CapabilityProcess | CapabilityEnv
+)
+
+func timeFunctions() []*Function {
+	return []*Function{
+		{
+			Name:  "clock",
+			Arity: 0,
+			Impl: func([]loxtype.Type) (loxtype.Type, error) {
+				return loxtype.Number(time.Now().UnixMilli()), nil
+			},
+		},
+	}
+}
+
+// maxExitCode is the highest exit status that processes can report.
+const maxExitCode = 255
+
+func processFunctions() []*Function {
+	return []*Function{
+		{
+			// exit unwinds the program rather than exiting the process, so that the host decides what exiting means.
+			// It exits with status 0 unless given another.
+			Name:     "exit",
+			Arity:    1,
+			Optional: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				if len(args) == 0 {
+					return nil, &ierrors.ExitError{Code: 0}
+				}
+				code, err := number(args[0], "exit with status")
+				if err != nil {
+					return nil, err
+				}
+				if code != loxtype.Number(math.Trunc(float64(code))) || code < 0 || code > maxExitCode {
+					return nil, fmt.Errorf("%w: exit status must be a whole number from 0 to %d, not %s",
+						ierrors.ErrArgumentRange, maxExitCode, code)
+				}
+				return nil, &ierrors.ExitError{Code: int(code)}
+			},
+		},
+	}
+}
+
+func envFunctions() []*Function {
+	return []*Function{
+		{
+			// getenv returns the value of an environment variable, or nil if it isn't set.
+			Name:  "getenv",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				name, err := str(args[0], "read the environment variable")
+				if err != nil {
+					return nil, err
+				}
+				value, ok := os.LookupEnv(string(name))
+				if !ok {
+					return loxtype.Nil{}, nil
+				}
+				return loxtype.String(value), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package native
+
+import (
+	"fmt"
+	"math"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// GetIndex returns the element of a list or the value of a map's key.
+// Backends index with it and [SetIndex], so that they fail alike.
+func GetIndex(object, index loxtype.Type) (loxtype.Type, error) {
+	switch o := object.(type) {
+	case *loxtype.List:
+		n, err := bound(index, len(o.Elements)-1)
+		if err != nil {
+			return nil, err
+		}
+		return o.Elements[n], nil
+
+	case *loxtype.Map:
+		if !loxtype.Hashable(index) {
+			return nil, fmt.Errorf("%w: %s", ierrors.ErrUnhashableKey, index)
+		}
+		value, ok := o.Get(index)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ierrors.ErrUndefinedKey, index)
+		}
+		return value, nil
+	}
+
+	return nil, ierrors.ErrNotIndexable
+}
+
+// SetIndex replaces the element of a list, or sets the value of a map's key.
+func SetIndex(object, index, value loxtype.Type) error {
+	switch o := object.(type) {
+	case *loxtype.List:
+		n, err := bound(index, len(o.Elements)-1)
+		if err != nil {
+			return err
+		}
+		o.Elements[n] = value
+		return nil
+
+	case *loxtype.Map:
+		if !loxtype.Hashable(index) {
+			return fmt.Errorf("%w: %s", ierrors.ErrUnhashableKey, index)
+		}
+		o.Set(index, value)
+		return nil
+	}
+
+	return ierrors.ErrNotIndexable
+}
+
+// bound checks that a value is an integer from 0 through upper and returns it as an int.
+func bound(value loxtype.Type, upper int) (int, error) {
+	n, ok := value.(loxtype.Number)
+	if !ok || n != loxtype.Number(math.Trunc(float64(n))) {
+		return 0, fmt.Errorf("%w: %s", ierrors.ErrIndexNotInteger, value)
+	}
+	if n < 0 || n > loxtype.Number(upper) {
+		return 0, fmt.Errorf("%w: %s", ierrors.ErrIndexOutOfRange, n)
+	}
+	return int(n), nil
+}
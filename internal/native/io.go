@@ -0,0 +1,180 @@
+package native
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// FS is the file system that programs read and write files through. Reading is as for [fs.FS],
+// so that any of those can be read from, though names needn't be valid for one unless the file system demands it.
+type FS interface {
+	fs.FS
+	// WriteFile replaces the contents of the named file, creating it if it doesn't exist.
+	WriteFile(name string, data []byte) error
+	// AppendFile adds to the end of the named file, creating it if it doesn't exist.
+	AppendFile(name string, data []byte) error
+}
+
+// OSFS is the file system of the operating system, with names relative to the working directory.
+type OSFS struct{}
+
+var _ FS = OSFS{}
+
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (OSFS) WriteFile(name string, data []byte) error {
+	return os.WriteFile(name, data, 0o666) //nolint:gosec // Scripts write files as the user running them.
+}
+
+func (OSFS) AppendFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o666) //nolint:gosec // As for WriteFile.
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return errors.Join(err, f.Close())
+}
+
+// Host is what the functions of the I/O and file system capabilities reach beyond the program through.
+// Any left unset are those of the process.
+type Host struct {
+	FS     FS
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func (h Host) withDefaults() Host {
+	if h.FS == nil {
+		h.FS = OSFS{}
+	}
+	if h.Stdin == nil {
+		h.Stdin = os.Stdin
+	}
+	if h.Stdout == nil {
+		h.Stdout = os.Stdout
+	}
+	if h.Stderr == nil {
+		h.Stderr = os.Stderr
+	}
+	return h
+}
+
+func ioFunctions(host *Host) []*Function {
+	// Lines are read through a buffer shared by every call, which mustn't lose what it reads ahead between them.
+	stdin := bufio.NewReader(host.Stdin)
+	readLine := func() (loxtype.Type, error) {
+		line, err := stdin.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			return loxtype.Nil{}, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return loxtype.String(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")), nil
+	}
+
+	return []*Function{
+		{
+			// readLine returns the next line of the standard input without its line ending, or nil at its end.
+			Name:  "readLine",
+			Arity: 0,
+			Impl: func([]loxtype.Type) (loxtype.Type, error) {
+				return readLine()
+			},
+		},
+		{
+			// input writes a prompt to the standard output and returns the line read in reply, as readLine does.
+			Name:  "input",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				if _, err := fmt.Fprint(host.Stdout, args[0]); err != nil {
+					return nil, err
+				}
+				return readLine()
+			},
+		},
+		{
+			// printErr prints a value to the standard error as print does to the standard output.
+			Name:  "printErr",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				if _, err := fmt.Fprintln(host.Stderr, args[0]); err != nil {
+					return nil, err
+				}
+				return loxtype.Nil{}, nil
+			},
+		},
+	}
+}
+
+func fsFunctions(host *Host) []*Function {
+	readFile := func(arg loxtype.Type) (string, error) {
+		name, err := str(arg, "read the file")
+		if err != nil {
+			return "", err
+		}
+		data, err := fs.ReadFile(host.FS, string(name))
+		return string(data), err
+	}
+	writeFile := func(name string, write func(name string, data []byte) error) *Function {
+		return &Function{
+			Name:  name,
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				ss, err := strs(args, "write")
+				if err != nil {
+					return nil, err
+				}
+				if err = write(ss[0], []byte(ss[1])); err != nil {
+					return nil, err
+				}
+				return loxtype.Nil{}, nil
+			},
+		}
+	}
+
+	return []*Function{
+		{
+			// readFile returns the whole contents of a file.
+			Name:  "readFile",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				data, err := readFile(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return loxtype.String(data), nil
+			},
+		},
+		{
+			// readLines returns the lines of a file without their line endings, for looping over.
+			Name:  "readLines",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				data, err := readFile(args[0])
+				if err != nil {
+					return nil, err
+				}
+				l := &loxtype.List{}
+				for line := range strings.Lines(data) {
+					line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+					l.Elements = append(l.Elements, loxtype.String(line))
+				}
+				return l, nil
+			},
+		},
+		// writeFile replaces the contents of a file, and appendFile adds to them.
+		writeFile("writeFile", host.FS.WriteFile),
+		writeFile("appendFile", host.FS.AppendFile),
+	}
+}
@@ -0,0 +1,91 @@
+package native
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+func list(value loxtype.Type, name string) (*loxtype.List, error) {
+	l, ok := value.(*loxtype.List)
+	if !ok {
+		return nil, fmt.Errorf("cannot %s %s: %w", name, value, ierrors.ErrNonListType)
+	}
+	return l, nil
+}
+
+func lists() []*Function {
+	return []*Function{
+		{
+			Name:  "len",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				switch v := args[0].(type) {
+				case loxtype.String:
+					return loxtype.Number(utf8.RuneCountInString(string(v))), nil
+				case *loxtype.Map:
+					return loxtype.Number(v.Len()), nil
+				}
+				l, err := list(args[0], "take the length of")
+				if err != nil {
+					return nil, err
+				}
+				return loxtype.Number(len(l.Elements)), nil
+			},
+		},
+		{
+			Name:  "push",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				l, err := list(args[0], "push onto")
+				if err != nil {
+					return nil, err
+				}
+				l.Elements = append(l.Elements, args[1])
+				return loxtype.Nil{}, nil
+			},
+		},
+		{
+			Name:  "pop",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				l, err := list(args[0], "pop from")
+				if err != nil {
+					return nil, err
+				}
+				if len(l.Elements) == 0 {
+					return nil, fmt.Errorf("%w: cannot pop from an empty list", ierrors.ErrIndexOutOfRange)
+				}
+				last := l.Elements[len(l.Elements)-1]
+				l.Elements[len(l.Elements)-1] = nil
+				l.Elements = l.Elements[:len(l.Elements)-1]
+				return last, nil
+			},
+		},
+		{
+			// slice copies the elements from start up to but not including end.
+			Name:  "slice",
+			Arity: 3,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				l, err := list(args[0], "slice")
+				if err != nil {
+					return nil, err
+				}
+				end, err := bound(args[2], len(l.Elements))
+				if err != nil {
+					return nil, err
+				}
+				start, err := bound(args[1], len(l.Elements))
+				if err != nil {
+					return nil, err
+				}
+				if end < start {
+					return nil, fmt.Errorf("%w: slice end %d is before start %d", ierrors.ErrIndexOutOfRange, end, start)
+				}
+				return &loxtype.List{Elements: append([]loxtype.Type(nil), l.Elements[start:end]...)}, nil
+			},
+		},
+	}
+}
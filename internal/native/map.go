@@ -0,0 +1,72 @@
+package native
+
+import (
+	"fmt"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+func mapOf(value loxtype.Type, name string) (*loxtype.Map, error) {
+	m, ok := value.(*loxtype.Map)
+	if !ok {
+		return nil, fmt.Errorf("cannot %s %s: %w", name, value, ierrors.ErrNonMapType)
+	}
+	return m, nil
+}
+
+func maps() []*Function {
+	return []*Function{
+		{
+			// keys lists the keys in the order they were first set.
+			Name:  "keys",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				m, err := mapOf(args[0], "list the keys of")
+				if err != nil {
+					return nil, err
+				}
+				return &loxtype.List{Elements: m.Keys()}, nil
+			},
+		},
+		{
+			Name:  "values",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				m, err := mapOf(args[0], "list the values of")
+				if err != nil {
+					return nil, err
+				}
+				values := m.Keys()
+				for i, key := range values {
+					values[i], _ = m.Get(key)
+				}
+				return &loxtype.List{Elements: values}, nil
+			},
+		},
+		{
+			Name:  "has",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				m, err := mapOf(args[0], "look up keys in")
+				if err != nil {
+					return nil, err
+				}
+				_, ok := m.Get(args[1])
+				return loxtype.Boolean(ok), nil
+			},
+		},
+		{
+			// delete reports whether the key was set.
+			Name:  "delete",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				m, err := mapOf(args[0], "delete keys from")
+				if err != nil {
+					return nil, err
+				}
+				return loxtype.Boolean(m.Delete(args[1])), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package native
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/runes"
+)
+
+// maxDigits is the most digits toFixed writes after the decimal point.
+const maxDigits = 100
+
+func number(value loxtype.Type, name string) (loxtype.Number, error) {
+	n, ok := value.(loxtype.Number)
+	if !ok {
+		return 0, fmt.Errorf("cannot %s %s: %w", name, value, ierrors.ErrNonNumericType)
+	}
+	return n, nil
+}
+
+// Constant is a built-in value that every backend defines as a global.
+type Constant struct {
+	Name  string
+	Value loxtype.Type
+}
+
+// Constants returns the built-in values.
+func Constants() []Constant {
+	return []Constant{
+		{Name: "pi", Value: loxtype.Number(math.Pi)},
+		{Name: "inf", Value: loxtype.Number(math.Inf(1))},
+		{Name: "nan", Value: loxtype.Number(math.NaN())},
+	}
+}
+
+//nolint:funlen // One entry per function.
+func mathFunctions() []*Function {
+	// Every program gets a generator of its own, seeded randomly until it calls seed.
+	source := rand.NewPCG(uint64(time.Now().UnixNano()), rand.Uint64()) //nolint:gosec // Simulations, not secrets.
+	rng := rand.New(source)                                             //nolint:gosec // Likewise.
+
+	return []*Function{
+		unary("floor", math.Floor),
+		unary("ceil", math.Ceil),
+		unary("round", math.Round),
+		unary("abs", math.Abs),
+		unary("sqrt", math.Sqrt),
+		unary("sin", math.Sin),
+		unary("cos", math.Cos),
+		unary("tan", math.Tan),
+		unary("asin", math.Asin),
+		unary("acos", math.Acos),
+		unary("atan", math.Atan),
+		unary("exp", math.Exp),
+		unary("log", math.Log),
+		unary("log2", math.Log2),
+		unary("log10", math.Log10),
+		binary("pow", math.Pow),
+		binary("atan2", math.Atan2),
+		binary("min", math.Min),
+		binary("max", math.Max),
+		{
+			// random returns a number from 0 up to but not including 1.
+			Name:  "random",
+			Arity: 0,
+			Impl: func([]loxtype.Type) (loxtype.Type, error) {
+				return loxtype.Number(rng.Float64()), nil
+			},
+		},
+		{
+			// seed makes the numbers random returns from then on the same for the same seed.
+			Name:  "seed",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				n, err := number(args[0], "seed with")
+				if err != nil {
+					return nil, err
+				}
+				source.Seed(math.Float64bits(float64(n)), 0)
+				return loxtype.Nil{}, nil
+			},
+		},
+		{
+			// toFixed formats a number with the given number of digits after the decimal point.
+			Name:  "toFixed",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				n, err := number(args[0], "format")
+				if err != nil {
+					return nil, err
+				}
+				digits, err := number(args[1], "format with digits")
+				if err != nil {
+					return nil, err
+				}
+				if digits != loxtype.Number(math.Trunc(float64(digits))) || digits < 0 || digits > maxDigits {
+					return nil, fmt.Errorf("%w: toFixed digits must be a whole number from 0 to %d, not %s",
+						ierrors.ErrArgumentRange, maxDigits, digits)
+				}
+				return loxtype.String(strconv.FormatFloat(float64(n), 'f', int(digits), 64)), nil
+			},
+		},
+		{
+			Name:  "parseNumber",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				s, err := str(args[0], "parse")
+				if err != nil {
+					return nil, err
+				}
+				n, err := loxtype.ParseNumber([]runes.Rune(string(s)))
+				if err != nil {
+					return nil, err
+				}
+				return n, nil
+			},
+		},
+	}
+}
+
+// unary returns a function of one number.
+func unary(name string, fn func(float64) float64) *Function {
+	return &Function{
+		Name:  name,
+		Arity: 1,
+		Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+			x, err := number(args[0], "take the "+name+" of")
+			if err != nil {
+				return nil, err
+			}
+			return loxtype.Number(fn(float64(x))), nil
+		},
+	}
+}
+
+// binary returns a function of two numbers.
+func binary(name string, fn func(float64, float64) float64) *Function {
+	return &Function{
+		Name:  name,
+		Arity: 2,
+		Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+			x, err := number(args[0], "take the "+name+" of")
+			if err != nil {
+				return nil, err
+			}
+			y, err := number(args[1], "take the "+name+" of")
+			if err != nil {
+				return nil, err
+			}
+			return loxtype.Number(fn(float64(x), float64(y))), nil
+		},
+	}
+}
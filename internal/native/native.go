@@ -0,0 +1,84 @@
+// Package native provides the built-in functions and values that every backend defines as globals.
+package native
+
+import (
+	"fmt"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// Function is a built-in function implemented in Go.
+// It knows nothing of the backend calling it, so it can be shared by all of them.
+type Function struct {
+	Name  string
+	Arity int
+	// Optional is how many of the last arguments callers may leave out, which the implementation isn't given.
+	Optional int
+	Impl     func(args []loxtype.Type) (loxtype.Type, error)
+}
+
+var _ loxtype.Type = (*Function)(nil)
+
+func (f *Function) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*Function)
+	return loxtype.Boolean(ok && o == f)
+}
+
+func (*Function) IsTruthy() loxtype.Boolean { return true }
+func (f *Function) String() string          { return fmt.Sprintf("<native fn: %s>", f.Name) }
+
+// CheckArity returns [ierrors.ErrArgumentCount] unless the function can be called with argc arguments.
+func (f *Function) CheckArity(argc int) error {
+	switch {
+	case argc <= f.Arity && argc >= f.Arity-f.Optional:
+		return nil
+	case f.Optional == 0:
+		return fmt.Errorf("%w: expected %d but got %d", ierrors.ErrArgumentCount, f.Arity, argc)
+	default:
+		return fmt.Errorf("%w: expected %d to %d but got %d", ierrors.ErrArgumentCount, f.Arity-f.Optional, f.Arity, argc)
+	}
+}
+
+// Call checks nothing and runs the implementation; callers are responsible for checking the arity, see
+// [Function.CheckArity].
+func (f *Function) Call(args []loxtype.Type) (loxtype.Type, error) {
+	return f.Impl(args)
+}
+
+// library registers every set of built-in functions under the capability it needs,
+// or [NoCapabilities] if it only works on values.
+var library = []struct {
+	capability Capability
+	functions  func(host *Host) []*Function
+}{
+	{NoCapabilities, values(lists)},
+	{NoCapabilities, values(maps)},
+	{NoCapabilities, values(stringFunctions)},
+	{NoCapabilities, values(mathFunctions)},
+	{CapabilityTime, values(timeFunctions)},
+	{CapabilityIO, ioFunctions},
+	{CapabilityFS, fsFunctions},
+	{CapabilityProcess, values(processFunctions)},
+	{CapabilityEnv, values(envFunctions)},
+}
+
+// values adapts a set of functions that has no need of the host.
+func values(functions func() []*Function) func(*Host) []*Function {
+	return func(*Host) []*Function {
+		return functions()
+	}
+}
+
+// Functions returns fresh copies of the built-in functions: those that only work on values,
+// which every program has, and those of the capabilities granted, which reach the host through host.
+func Functions(granted Capability, host Host) []*Function {
+	host = host.withDefaults()
+	var fns []*Function
+	for _, set := range library {
+		if set.capability == NoCapabilities || granted&set.capability != 0 {
+			fns = append(fns, set.functions(&host)...)
+		}
+	}
+	return fns
+}
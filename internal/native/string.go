@@ -0,0 +1,199 @@
+package native
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/runes"
+)
+
+func str(value loxtype.Type, name string) (loxtype.String, error) {
+	s, ok := value.(loxtype.String)
+	if !ok {
+		return "", fmt.Errorf("cannot %s %s: %w", name, value, ierrors.ErrNonStringType)
+	}
+	return s, nil
+}
+
+// strs checks that every value is a string.
+func strs(values []loxtype.Type, name string) ([]string, error) {
+	ss := make([]string, len(values))
+	for k, value := range values {
+		s, err := str(value, name)
+		if err != nil {
+			return nil, err
+		}
+		ss[k] = string(s)
+	}
+	return ss, nil
+}
+
+// stringFunctions work on strings as sequences of code points, just as the scanner reads source code,
+// so that indexes count characters rather than bytes.
+//
+//nolint:funlen // One entry per function.
+func stringFunctions() []*Function {
+	return []*Function{
+		{
+			// substr returns the characters from start up to but not including end.
+			Name:  "substr",
+			Arity: 3,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				s, err := str(args[0], "take a substring of")
+				if err != nil {
+					return nil, err
+				}
+				text := []runes.Rune(string(s))
+				end, err := bound(args[2], len(text))
+				if err != nil {
+					return nil, err
+				}
+				start, err := bound(args[1], len(text))
+				if err != nil {
+					return nil, err
+				}
+				if end < start {
+					return nil, fmt.Errorf("%w: substr end %d is before start %d", ierrors.ErrIndexOutOfRange, end, start)
+				}
+				return loxtype.String(string(text[start:end])), nil
+			},
+		},
+		{
+			// indexOf returns the index of the first character of the first occurrence of a substring, or -1.
+			Name:  "indexOf",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				ss, err := strs(args, "search")
+				if err != nil {
+					return nil, err
+				}
+				i := strings.Index(ss[0], ss[1])
+				if i < 0 {
+					return loxtype.Number(-1), nil
+				}
+				return loxtype.Number(utf8.RuneCountInString(ss[0][:i])), nil
+			},
+		},
+		{
+			// split returns the substrings between every occurrence of a separator,
+			// or every character if the separator is empty.
+			Name:  "split",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				ss, err := strs(args, "split")
+				if err != nil {
+					return nil, err
+				}
+				parts := strings.Split(ss[0], ss[1])
+				l := &loxtype.List{Elements: make([]loxtype.Type, len(parts))}
+				for k, part := range parts {
+					l.Elements[k] = loxtype.String(part)
+				}
+				return l, nil
+			},
+		},
+		{
+			// join concatenates the elements of a list, which are printed as print would, with a separator between.
+			Name:  "join",
+			Arity: 2,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				l, err := list(args[0], "join")
+				if err != nil {
+					return nil, err
+				}
+				sep, err := str(args[1], "join with")
+				if err != nil {
+					return nil, err
+				}
+				parts := make([]string, len(l.Elements))
+				for k, element := range l.Elements {
+					parts[k] = element.String()
+				}
+				return loxtype.String(strings.Join(parts, string(sep))), nil
+			},
+		},
+		mapString("upper", "uppercase", strings.ToUpper),
+		mapString("lower", "lowercase", strings.ToLower),
+		mapString("trim", "trim", strings.TrimSpace),
+		{
+			// replace replaces every occurrence of a substring.
+			Name:  "replace",
+			Arity: 3,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				ss, err := strs(args, "replace within")
+				if err != nil {
+					return nil, err
+				}
+				return loxtype.String(strings.ReplaceAll(ss[0], ss[1], ss[2])), nil
+			},
+		},
+		testStrings("startsWith", strings.HasPrefix),
+		testStrings("endsWith", strings.HasSuffix),
+		{
+			// chr returns the character of a code point.
+			Name:  "chr",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				n, ok := args[0].(loxtype.Number)
+				if !ok {
+					return nil, fmt.Errorf("cannot make a character of %s: %w", args[0], ierrors.ErrNonNumericType)
+				}
+				if n != loxtype.Number(math.Trunc(float64(n))) || n < 0 || n > utf8.MaxRune ||
+					!utf8.ValidRune(rune(n)) {
+					return nil, fmt.Errorf("%w: %s", ierrors.ErrInvalidCodePoint, n)
+				}
+				return loxtype.String(string(rune(n))), nil
+			},
+		},
+		{
+			// ord returns the code point of a single character.
+			Name:  "ord",
+			Arity: 1,
+			Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+				s, err := str(args[0], "take the code point of")
+				if err != nil {
+					return nil, err
+				}
+				text := []runes.Rune(string(s))
+				if len(text) != 1 {
+					return nil, fmt.Errorf("%w: %q isn't a single character", ierrors.ErrInvalidCodePoint, string(s))
+				}
+				return loxtype.Number(text[0]), nil
+			},
+		},
+	}
+}
+
+// mapString returns a function that transforms a string.
+func mapString(name, verb string, transform func(string) string) *Function {
+	return &Function{
+		Name:  name,
+		Arity: 1,
+		Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+			s, err := str(args[0], verb)
+			if err != nil {
+				return nil, err
+			}
+			return loxtype.String(transform(string(s))), nil
+		},
+	}
+}
+
+// testStrings returns a function that tests a string against another.
+func testStrings(name string, test func(s, other string) bool) *Function {
+	return &Function{
+		Name:  name,
+		Arity: 2,
+		Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+			ss, err := strs(args, "test")
+			if err != nil {
+				return nil, err
+			}
+			return loxtype.Boolean(test(ss[0], ss[1])), nil
+		},
+	}
+}
@@ -18,7 +18,8 @@ func (p *Parser) expression() (ast.Expr, error) {
 
 // assignment implements the production:
 //
-//	assignment -> IDENTIFIER "=" assignment
+//	assignment -> ( call "." )? IDENTIFIER "=" assignment
+//	            | call "[" expression "]" "=" assignment
 //	            | logic_or ;
 func (p *Parser) assignment() (ast.Expr, error) {
 	expr, err := p.or()
@@ -33,12 +34,17 @@ func (p *Parser) assignment() (ast.Expr, error) {
 			return nil, err
 		}
 
-		if varExpr, ok := expr.(*ast.VariableExpr); ok {
-			name := varExpr.Name
-			return ast.NewAssignExpr(name, value), nil
+		switch target := expr.(type) {
+		case *ast.VariableExpr:
+			return ast.NewAssignExpr(target.Name, value), nil
+		case *ast.GetExpr:
+			return ast.NewSetExpr(target.Object, target.Name, value), nil
+		case *ast.IndexExpr:
+			return ast.NewSetIndexExpr(target.Object, target.Bracket, target.Index, value), nil
 		}
 
-		return nil, ierrors.New(equals, nil)
+		// The parser isn't confused about where it is, so there's no need to synchronize.
+		p.report(ierrors.New(equals, ErrInvalidAssignmentTarget))
 	}
 	return expr, nil
 }
@@ -187,7 +193,7 @@ func (p *Parser) unary() (ast.Expr, error) {
 
 // call implements the productions:
 //
-//	call      -> primary ( "(" arguments? ")" )* ;
+//	call      -> primary ( "(" arguments? ")" | "." IDENTIFIER | "[" expression "]" )* ;
 //	arguments -> expression ( "," expression )* ;
 func (p *Parser) call() (ast.Expr, error) {
 	var (
@@ -204,6 +210,16 @@ func (p *Parser) call() (ast.Expr, error) {
 			if expr, err = p.finishCall(expr); err != nil {
 				return nil, err
 			}
+		} else if p.match(token.TypeDot) {
+			var name *token.Token
+			if name, err = p.consume(token.TypeIdentifier, errors.New("expect property name after '.'")); err != nil {
+				return nil, err
+			}
+			expr = ast.NewGetExpr(expr, name)
+		} else if p.match(token.TypeLeftBracket) {
+			if expr, err = p.finishIndex(expr); err != nil {
+				return nil, err
+			}
 		} else {
 			break
 		}
@@ -240,24 +256,56 @@ func (p *Parser) finishCall(callee ast.Expr) (ast.Expr, error) {
 	return ast.NewCallExpr(callee, paren, arguments), nil
 }
 
+func (p *Parser) finishIndex(object ast.Expr) (ast.Expr, error) {
+	index, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	bracket, err := p.consume(token.TypeRightBracket, errors.New("expect ']' after index"))
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewIndexExpr(object, bracket, index), nil
+}
+
 // primary implements the production:
 //
-//	primary -> "true" | "false" | "nil"
+//	primary -> "true" | "false" | "nil" | "this"
 //	         | NUMBER | STRING
 //	         | "(" expression ")"
-//	         | IDENTIFIER ;
+//	         | "[" arguments? "]"
+//	         | "{" ( entry ( "," entry )* )? "}"
+//	         | IDENTIFIER
+//	         | "super" "." IDENTIFIER ;
+//	entry   -> expression ":" expression ;
 func (p *Parser) primary() (ast.Expr, error) {
 	if p.match(token.TypeFalse) {
-		return ast.NewLiteralExpr(loxtype.Boolean(false)), nil
+		return ast.NewLiteralExpr(loxtype.Boolean(false), p.previous()), nil
 	}
 	if p.match(token.TypeTrue) {
-		return ast.NewLiteralExpr(loxtype.Boolean(true)), nil
+		return ast.NewLiteralExpr(loxtype.Boolean(true), p.previous()), nil
 	}
 	if p.match(token.TypeNil) {
-		return ast.NewLiteralExpr(loxtype.Nil{}), nil
+		return ast.NewLiteralExpr(loxtype.Nil{}, p.previous()), nil
+	}
+	if p.match(token.TypeSuper) {
+		keyword := p.previous()
+		if _, err := p.consume(token.TypeDot, errors.New("expect '.' after 'super'")); err != nil {
+			return nil, err
+		}
+		method, err := p.consume(token.TypeIdentifier, errors.New("expect superclass method name"))
+		if err != nil {
+			return nil, err
+		}
+		return ast.NewSuperExpr(keyword, method), nil
+	}
+	if p.match(token.TypeThis) {
+		return ast.NewThisExpr(p.previous()), nil
 	}
 	if p.match(token.TypeNumber, token.TypeString) {
-		return ast.NewLiteralExpr(p.previous().Literal), nil
+		return ast.NewLiteralExpr(p.previous().Literal, p.previous()), nil
 	}
 	if p.match(token.TypeIdentifier) {
 		return ast.NewVariableExpr(p.previous()), nil
@@ -272,6 +320,65 @@ func (p *Parser) primary() (ast.Expr, error) {
 		}
 		return ast.NewGroupingExpr(expression), nil
 	}
+	if p.match(token.TypeLeftBracket) {
+		return p.finishList(p.previous())
+	}
+	if p.match(token.TypeLeftBrace) {
+		return p.finishMap(p.previous())
+	}
+
+	return nil, ierrors.New(p.peek(), ErrExpectExpression)
+}
+
+func (p *Parser) finishList(bracket *token.Token) (ast.Expr, error) {
+	var elements []ast.Expr
+
+	if !p.check(token.TypeRightBracket) {
+		for {
+			element, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+			if !p.match(token.TypeComma) {
+				break
+			}
+		}
+	}
+
+	if _, err := p.consume(token.TypeRightBracket, errors.New("expect ']' after list elements")); err != nil {
+		return nil, err
+	}
+
+	return ast.NewListExpr(bracket, elements), nil
+}
+
+func (p *Parser) finishMap(brace *token.Token) (ast.Expr, error) {
+	var keys, values []ast.Expr
+
+	if !p.check(token.TypeRightBrace) {
+		for {
+			key, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if _, err = p.consume(token.TypeColon, errors.New("expect ':' after map key")); err != nil {
+				return nil, err
+			}
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			keys, values = append(keys, key), append(values, value)
+			if !p.match(token.TypeComma) {
+				break
+			}
+		}
+	}
+
+	if _, err := p.consume(token.TypeRightBrace, errors.New("expect '}' after map entries")); err != nil {
+		return nil, err
+	}
 
-	return nil, ErrUnimplemented
+	return ast.NewMapExpr(brace, keys, values), nil
 }
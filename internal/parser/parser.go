@@ -11,14 +11,18 @@ import (
 var (
 	ErrNoVariableName            = errors.New("expect variable name")
 	ErrUnimplemented             = errors.New("unimplemented")
+	ErrExpectExpression          = errors.New("expect expression")
+	ErrInvalidAssignmentTarget   = errors.New("invalid assignment target")
 	ErrMissingOpeningParenthesis = errors.New("expect '(' after 'if', 'while', or 'for'")
 	ErrUnterminatedExpression    = errors.New("expect ')' after expression")
 	ErrUnterminatedStatement     = errors.New("expect ';' after expression")
 	ErrUnterminatedBlock         = errors.New("expect '}' after block")
+	ErrTryWithoutHandler         = errors.New("expect 'catch' or 'finally' after try block")
 )
 
 type Parser struct {
 	tokens   []*token.Token
+	errs     ierrors.List
 	current  int
 	replMode bool
 }
@@ -42,16 +46,20 @@ func New(tokens []*token.Token, opts ...Option) *Parser {
 	return p
 }
 
+// Parse parses every declaration in the token stream.
+// It recovers from syntax errors so that all of them can be reported at once,
+// in which case the returned error is an [ierrors.List] and the statements are incomplete.
 func (p *Parser) Parse() ([]ast.Stmt, error) {
 	var statements []ast.Stmt
 	for !p.isAtEnd() {
 		stmt, err := p.declaration()
 		if err != nil {
-			return nil, err
+			p.report(err)
+			continue
 		}
 		statements = append(statements, stmt)
 	}
-	return statements, nil
+	return statements, p.errs.Err()
 }
 
 // advance consumes the current token and returns it.
@@ -108,6 +116,16 @@ func (p *Parser) previous() *token.Token {
 	return p.tokens[p.current-1]
 }
 
+// report records an error without halting the parse.
+func (p *Parser) report(err error) {
+	var e *ierrors.Error
+	if !errors.As(err, &e) {
+		e = ierrors.New(p.peek(), err)
+	}
+	p.errs = append(p.errs, e)
+}
+
+// synchronize discards tokens until it reaches what is likely the start of the next statement.
 func (p *Parser) synchronize() {
 	p.advance()
 
@@ -117,14 +135,19 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().Type {
-		case token.TypeClass,
+		case token.TypeBreak,
+			token.TypeClass,
+			token.TypeContinue,
 			token.TypeFun,
 			token.TypeVar,
 			token.TypeFor,
 			token.TypeIf,
+			token.TypeImport,
 			token.TypeWhile,
 			token.TypePrint,
-			token.TypeReturn:
+			token.TypeReturn,
+			token.TypeThrow,
+			token.TypeTry:
 			return
 		default:
 			break
@@ -3,9 +3,11 @@ package parser_test
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/matt-hoiland/glox/internal/ast"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/parser"
 	"github.com/matt-hoiland/glox/internal/scanner"
 )
@@ -22,3 +24,93 @@ func TestParser_Parse(t *testing.T) {
 		t.Log(p.Print(s))
 	}
 }
+
+func TestParser_Parse_indexAssignment(t *testing.T) {
+	t.Parallel()
+
+	tokens, scanErr := scanner.New(`xs[0] = [xs[1]];`).ScanTokens()
+	require.NoError(t, scanErr)
+	stmts, err := parser.New(tokens).Parse()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	stmt, ok := stmts[0].(*ast.ExpressionStmt)
+	require.True(t, ok)
+	set, ok := stmt.Expression.(*ast.SetIndexExpr)
+	require.True(t, ok)
+	assert.Equal(t, "]", set.Bracket.Lexeme)
+	assert.Equal(t, 5, set.Bracket.Column)
+
+	list, ok := set.Value.(*ast.ListExpr)
+	require.True(t, ok)
+	require.Len(t, list.Elements, 1)
+	assert.IsType(t, &ast.IndexExpr{}, list.Elements[0])
+}
+
+func TestParser_Parse_tryClauses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name       string
+		Code       string
+		HasCatch   bool
+		HasFinally bool
+	}{
+		{Name: "catch", Code: `try {} catch (e) {}`, HasCatch: true},
+		{Name: "finally", Code: `try {} finally {}`, HasFinally: true},
+		{Name: "both", Code: `try {} catch (e) {} finally {}`, HasCatch: true, HasFinally: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			tokens, scanErr := scanner.New(test.Code).ScanTokens()
+			require.NoError(t, scanErr)
+			stmts, err := parser.New(tokens).Parse()
+			require.NoError(t, err)
+			require.Len(t, stmts, 1)
+
+			stmt, ok := stmts[0].(*ast.TryStmt)
+			require.True(t, ok)
+			assert.Equal(t, test.HasCatch, stmt.CatchName != nil)
+			// An empty finally clause is still a finally clause.
+			assert.Equal(t, test.HasFinally, stmt.FinallyBody != nil)
+		})
+	}
+}
+
+func TestParser_Parse_reportsEveryError(t *testing.T) {
+	t.Parallel()
+
+	code := `
+		var = 1;
+		print "fine";
+		print (1 + ;
+		print "also fine";
+		1 + 2 = 3;
+		{
+			print ;
+		}
+		print "still fine";
+	`
+	tokens, scanErr := scanner.New(code).ScanTokens()
+	require.NoError(t, scanErr)
+
+	stmts, err := parser.New(tokens).Parse()
+
+	var list ierrors.List
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 4)
+	assert.ErrorIs(t, list[0], parser.ErrNoVariableName)
+	assert.Equal(t, 2, list[0].Line)
+	assert.ErrorIs(t, list[1], parser.ErrExpectExpression)
+	assert.Equal(t, 4, list[1].Line)
+	assert.ErrorIs(t, list[2], parser.ErrInvalidAssignmentTarget)
+	assert.Equal(t, 6, list[2].Line)
+	assert.ErrorIs(t, list[3], parser.ErrExpectExpression)
+	assert.Equal(t, 8, list[3].Line)
+
+	// Everything that could be parsed is still returned.
+	assert.Len(t, stmts, 5)
+}
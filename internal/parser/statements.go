@@ -5,13 +5,15 @@ import (
 	"fmt"
 
 	"github.com/matt-hoiland/glox/internal/ast"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
 	"github.com/matt-hoiland/glox/internal/token"
 )
 
 // declaration implements the production:
 //
-//	declaration -> funDecl
+//	declaration -> classDecl
+//	             | funDecl
 //	             | varDecl
 //	             | statement ;
 func (p *Parser) declaration() (ast.Stmt, error) {
@@ -27,6 +29,8 @@ func (p *Parser) declaration() (ast.Stmt, error) {
 	}()
 
 	switch {
+	case p.match(token.TypeClass):
+		stmt, err = p.classDeclaration()
 	case p.match(token.TypeFun):
 		stmt, err = p.function(function)
 	case p.match(token.TypeVar):
@@ -45,11 +49,52 @@ const (
 	method   funcKind = "method"
 )
 
+// classDeclaration implements the production:
+//
+//	classDecl -> "class" IDENTIFIER ( "<" IDENTIFIER )? "{" function* "}" ;
+func (p *Parser) classDeclaration() (ast.Stmt, error) {
+	var (
+		name       *token.Token
+		superclass *ast.VariableExpr
+		methods    []*ast.FunctionStmt
+		err        error
+	)
+
+	if name, err = p.consume(token.TypeIdentifier, errors.New("expect class name")); err != nil {
+		return nil, err
+	}
+
+	if p.match(token.TypeLess) {
+		if _, err = p.consume(token.TypeIdentifier, errors.New("expect superclass name")); err != nil {
+			return nil, err
+		}
+		superclass = ast.NewVariableExpr(p.previous())
+	}
+
+	if _, err = p.consume(token.TypeLeftBrace, errors.New("expect '{' before class body")); err != nil {
+		return nil, err
+	}
+
+	for !p.check(token.TypeRightBrace) && !p.isAtEnd() {
+		var m *ast.FunctionStmt
+		if m, err = p.function(method); err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+
+	if _, err = p.consume(token.TypeRightBrace, errors.New("expect '}' after class body")); err != nil {
+		return nil, err
+	}
+
+	return ast.NewClassStmt(name, superclass, methods), nil
+}
+
 // function implements the productions:
 //
 //	funDecl  -> "fun" function ;
 //	function -> IDENTIFIER "(" parameters? ")" block ;
-func (p *Parser) function(kind funcKind) (ast.Stmt, error) {
+func (p *Parser) function(kind funcKind) (*ast.FunctionStmt, error) {
 	var (
 		name   *token.Token
 		params []*token.Token
@@ -126,26 +171,49 @@ func (p *Parser) varDeclaration() (ast.Stmt, error) {
 // statement implements the production:
 //
 //	statement -> exprStmt
+//	           | breakStmt
+//	           | continueStmt
 //	           | forStmt
 //	           | ifStmt
+//	           | importStmt
 //	           | printStmt
 //	           | returnStmt
+//	           | throwStmt
+//	           | tryStmt
 //	           | whileStmt
 //	           | block ;
+//
+// A "{" that starts a statement always starts a block, never a map literal,
+// so a map that begins an expression statement has to be wrapped in parentheses.
 func (p *Parser) statement() (ast.Stmt, error) {
 	switch {
+	case p.match(token.TypeBreak):
+		return p.breakStatement()
+
+	case p.match(token.TypeContinue):
+		return p.continueStatement()
+
 	case p.match(token.TypeFor):
 		return p.forStatement()
 
 	case p.match(token.TypeIf):
 		return p.ifStatement()
 
+	case p.match(token.TypeImport):
+		return p.importStatement()
+
 	case p.match(token.TypePrint):
 		return p.printStatement()
 
 	case p.match(token.TypeReturn):
 		return p.returnStatement()
 
+	case p.match(token.TypeThrow):
+		return p.throwStatement()
+
+	case p.match(token.TypeTry):
+		return p.tryStatement()
+
 	case p.match(token.TypeWhile):
 		return p.whileStatement()
 
@@ -179,9 +247,33 @@ func (p *Parser) expressionStatement() (ast.Stmt, error) {
 	return ast.NewExpressionStmt(value), nil
 }
 
+// breakStatement implements the production:
+//
+//	breakStmt -> "break" ";" ;
+func (p *Parser) breakStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.TypeSemicolon, errors.New("expect ';' after 'break'")); err != nil {
+		return nil, err
+	}
+	return ast.NewBreakStmt(keyword), nil
+}
+
+// continueStatement implements the production:
+//
+//	continueStmt -> "continue" ";" ;
+func (p *Parser) continueStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.TypeSemicolon, errors.New("expect ';' after 'continue'")); err != nil {
+		return nil, err
+	}
+	return ast.NewContinueStmt(keyword), nil
+}
+
 // forStatement implements the production:
 //
 //	forStmt -> "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement ;
+//
+// It desugars into a while loop, which keeps the increment apart from the body so that continuing still runs it.
 func (p *Parser) forStatement() (ast.Stmt, error) {
 	var (
 		initializer ast.Stmt
@@ -229,14 +321,10 @@ func (p *Parser) forStatement() (ast.Stmt, error) {
 		return nil, err
 	}
 
-	if increment != nil {
-		body = ast.NewBlockStmt([]ast.Stmt{body, ast.NewExpressionStmt(increment)})
-	}
-
 	if condition == nil {
-		condition = ast.NewLiteralExpr(loxtype.Boolean(true))
+		condition = ast.NewLiteralExpr(loxtype.Boolean(true), nil)
 	}
-	body = ast.NewWhileStmt(condition, body)
+	body = ast.NewWhileStmt(condition, body, increment)
 
 	if initializer != nil {
 		body = ast.NewBlockStmt([]ast.Stmt{initializer, body})
@@ -275,6 +363,31 @@ func (p *Parser) ifStatement() (ast.Stmt, error) {
 	return ast.NewIfStmt(condition, thenBranch, elseBranch), nil
 }
 
+// importStatement implements the production:
+//
+//	importStmt -> "import" STRING "as" IDENTIFIER ";" ;
+//
+// "as" is only special here, so it remains free for naming variables.
+func (p *Parser) importStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	path, err := p.consume(token.TypeString, errors.New("expect module path after 'import'"))
+	if err != nil {
+		return nil, err
+	}
+	if !p.check(token.TypeIdentifier) || p.peek().Lexeme != "as" {
+		return nil, ierrors.New(p.peek(), errors.New("expect 'as' after module path"))
+	}
+	p.advance()
+	name, err := p.consume(token.TypeIdentifier, errors.New("expect module name after 'as'"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = p.consume(token.TypeSemicolon, errors.New("expect ';' after import")); err != nil {
+		return nil, err
+	}
+	return ast.NewImportStmt(keyword, path, name), nil
+}
+
 // printStatement implements the production:
 //
 //	printStmt -> "print" expression ";" ;
@@ -314,6 +427,81 @@ func (p *Parser) returnStatement() (ast.Stmt, error) {
 	return ast.NewReturnStmt(keyword, value), nil
 }
 
+// throwStatement implements the production:
+//
+//	throwStmt -> "throw" expression ";" ;
+func (p *Parser) throwStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = p.consume(token.TypeSemicolon, errors.New("expect ';' after thrown value")); err != nil {
+		return nil, err
+	}
+
+	return ast.NewThrowStmt(keyword, value), nil
+}
+
+// tryStatement implements the production:
+//
+//	tryStmt -> "try" block ( "catch" "(" IDENTIFIER ")" block )? ( "finally" block )? ;
+//
+// A try statement must have a catch clause, a finally clause, or both.
+func (p *Parser) tryStatement() (ast.Stmt, error) {
+	var (
+		keyword                      = p.previous()
+		name                         *token.Token
+		body, catchBody, finallyBody []ast.Stmt
+		err                          error
+	)
+
+	if _, err = p.consume(token.TypeLeftBrace, errors.New("expect '{' after 'try'")); err != nil {
+		return nil, err
+	}
+	if body, err = p.block(); err != nil {
+		return nil, err
+	}
+
+	hasCatch := p.match(token.TypeCatch)
+	if hasCatch {
+		if _, err = p.consume(token.TypeLeftParen, errors.New("expect '(' after 'catch'")); err != nil {
+			return nil, err
+		}
+		if name, err = p.consume(token.TypeIdentifier, errors.New("expect error name")); err != nil {
+			return nil, err
+		}
+		if _, err = p.consume(token.TypeRightParen, errors.New("expect ')' after error name")); err != nil {
+			return nil, err
+		}
+		if _, err = p.consume(token.TypeLeftBrace, errors.New("expect '{' before catch body")); err != nil {
+			return nil, err
+		}
+		if catchBody, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(token.TypeFinally) {
+		if _, err = p.consume(token.TypeLeftBrace, errors.New("expect '{' after 'finally'")); err != nil {
+			return nil, err
+		}
+		if finallyBody, err = p.block(); err != nil {
+			return nil, err
+		}
+		// FinallyBody is nil only when there is no finally clause at all.
+		if finallyBody == nil {
+			finallyBody = []ast.Stmt{}
+		}
+	} else if !hasCatch {
+		return nil, ierrors.New(p.peek(), ErrTryWithoutHandler)
+	}
+
+	return ast.NewTryStmt(keyword, body, name, catchBody, finallyBody), nil
+}
+
 // whileStatement implements the production:
 //
 //	whileStmt -> "while" "(" expression ")" statement ;
@@ -337,7 +525,7 @@ func (p *Parser) whileStatement() (ast.Stmt, error) {
 		return nil, err
 	}
 
-	return ast.NewWhileStmt(condition, body), nil
+	return ast.NewWhileStmt(condition, body, nil), nil
 }
 
 // block implements the production:
@@ -349,7 +537,8 @@ func (p *Parser) block() ([]ast.Stmt, error) {
 	for !p.check(token.TypeRightBrace) && !p.isAtEnd() {
 		decl, err := p.declaration()
 		if err != nil {
-			return nil, err
+			p.report(err)
+			continue
 		}
 		stmts = append(stmts, decl)
 	}
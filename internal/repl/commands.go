@@ -0,0 +1,124 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+var (
+	ErrUnknownCommand  = errors.New("unknown command")
+	ErrMissingArgument = errors.New("missing argument")
+)
+
+// command is a colon command, which looks into the session or manages it instead of running code.
+type command struct {
+	name string
+	// arg names the command's argument, if it takes one.
+	arg  string
+	help string
+	run  func(r *REPL, ctx context.Context, arg string) error
+}
+
+func commands() []command {
+	return []command{
+		{name: "help", help: "list the commands", run: (*REPL).help},
+		{name: "env", help: "show the global variables and their values", run: (*REPL).env},
+		{name: "type", arg: "expression", help: "show the type of an expression's value", run: (*REPL).typeOf},
+		{name: "ast", arg: "code", help: "show the statements code parses into", run: (*REPL).syntax},
+		{name: "tokens", arg: "code", help: "show the tokens code scans into", run: (*REPL).tokens},
+		{name: "load", arg: "file", help: "run a file in the session", run: (*REPL).load},
+		{name: "reset", help: "start the session afresh", run: (*REPL).resetCommand},
+	}
+}
+
+// command runs a line that starts with a colon, naming the command, followed by its argument, if any.
+func (r *REPL) command(ctx context.Context, line string) error {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	for _, c := range commands() {
+		if c.name != name {
+			continue
+		}
+		if c.arg != "" && arg == "" {
+			return fmt.Errorf("%w: :%s needs %s", ErrMissingArgument, c.name, c.arg)
+		}
+		return c.run(r, ctx, arg)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, line)
+}
+
+func (r *REPL) help(context.Context, string) error {
+	for _, c := range commands() {
+		usage := ":" + c.name
+		if c.arg != "" {
+			usage += " <" + c.arg + ">"
+		}
+		fmt.Fprintf(r.out, "%-20s %s\n", usage, c.help)
+	}
+	return nil
+}
+
+func (r *REPL) env(context.Context, string) error {
+	for _, name := range r.interpreter.Globals() {
+		value, err := r.interpreter.Global(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "%s = %s\n", name, value)
+	}
+	return nil
+}
+
+// typeOf evaluates code as the REPL would, then names the type of its value.
+func (r *REPL) typeOf(ctx context.Context, code string) error {
+	value, err := r.interpreter.EvalContext(ctx, code)
+	if err != nil {
+		return ierrors.WithSource(err, "", code)
+	}
+	fmt.Fprintln(r.out, interpreter.TypeName(value))
+	return nil
+}
+
+func (r *REPL) syntax(_ context.Context, code string) error {
+	tokens, err := scanner.New(code).ScanTokens()
+	if err != nil {
+		return ierrors.WithSource(err, "", code)
+	}
+	stmts, err := parser.New(tokens, parser.InREPLMode()).Parse()
+	if err != nil {
+		return ierrors.WithSource(err, "", code)
+	}
+	for _, s := range stmts {
+		fmt.Fprintln(r.out, ast.Print(s))
+	}
+	return nil
+}
+
+// tokens lists tokens with their positions, types, and lexemes, which are shown quoted to make whitespace visible.
+func (r *REPL) tokens(_ context.Context, code string) error {
+	tokens, err := scanner.New(code).ScanTokens()
+	for _, tok := range tokens {
+		fmt.Fprintf(r.out, "%d:%d %s %q\n", tok.Line, tok.Column, tok.Type, tok.Lexeme)
+	}
+	if err != nil {
+		return ierrors.WithSource(err, "", code)
+	}
+	return nil
+}
+
+func (r *REPL) load(ctx context.Context, filename string) error {
+	return r.interpreter.LoadContext(ctx, filename)
+}
+
+func (r *REPL) resetCommand(context.Context, string) error {
+	r.reset()
+	return nil
+}
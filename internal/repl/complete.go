@@ -0,0 +1,56 @@
+package repl
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/runes"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+// Complete completes the identifier before the cursor from the keywords and the global variables, or, after a dot,
+// from the properties of what comes before the dot, so long as that is a variable or a chain of its properties.
+func (r *REPL) Complete(before string) (int, []string) {
+	text := []rune(before)
+	start := len(text)
+	for start > 0 && runes.Rune(text[start-1]).IsAlphaNumeric() {
+		start--
+	}
+	word := string(text[start:])
+	offset := len(before) - len(word)
+
+	var names []string
+	if start > 0 && text[start-1] == '.' {
+		value, err := r.interpreter.Eval(path(text[:start-1]))
+		if err != nil {
+			return offset, nil
+		}
+		names = interpreter.Properties(value)
+	} else {
+		if word == "" {
+			return offset, nil
+		}
+		names = append(scanner.Keywords(), r.interpreter.Globals()...)
+		slices.Sort(names)
+		names = slices.Compact(names)
+	}
+
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+	return offset, candidates
+}
+
+// path returns the identifiers joined by dots that the text ends with, which are empty when it doesn't end with
+// any, so that evaluating them has no effects and is an error when there's no such path.
+func path(text []rune) string {
+	start := len(text)
+	for start > 0 && (runes.Rune(text[start-1]).IsAlphaNumeric() || text[start-1] == '.') {
+		start--
+	}
+	return string(text[start:])
+}
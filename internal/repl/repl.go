@@ -0,0 +1,180 @@
+// Package repl runs the interactive prompt, which reads statements that may span several lines
+// and runs each on the tree-walking interpreter as soon as it is complete.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/lineedit"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+// Continuation is the prompt for the lines after the first of an incomplete statement,
+// as wide as the prompt for the first line so that they line up.
+const Continuation = "   ... "
+
+type REPL struct {
+	interpreter *interpreter.Interpreter
+	opts        []interpreter.Option
+	editor      *lineedit.Editor
+	out, errOut io.Writer
+
+	// session holds every line entered so far, since functions defined on earlier lines
+	// can report errors that need to quote them.
+	session strings.Builder
+	line    int
+}
+
+// New returns a REPL that reads lines with the editor and runs them on an interpreter made with the given options,
+// writing what they print and the values of expressions to out, and errors to errOut.
+// The editor completes words with [REPL.Complete].
+func New(editor *lineedit.Editor, out, errOut io.Writer, opts ...interpreter.Option) *REPL {
+	r := &REPL{opts: opts, editor: editor, out: out, errOut: errOut}
+	r.reset()
+	editor.SetCompleter(r.Complete)
+	return r
+}
+
+// reset starts the session afresh, forgetting every line entered so far and everything they declared.
+func (r *REPL) reset() {
+	r.interpreter = interpreter.New(r.out, r.opts...)
+	r.session.Reset()
+	r.line = 0
+}
+
+// Run reads and runs statements until the input ends. Errors in them are reported and the session carries on,
+// except when a statement exits, which Run returns as an [ierrors.ExitError], as it does errors reading input.
+// Interrupting the process stops the statement running, rather than the session.
+func (r *REPL) Run(ctx context.Context) error {
+	for {
+		stmts, cmd, err := r.read()
+		switch {
+		case errors.Is(err, lineedit.ErrInterrupted):
+			continue
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, new(ierrors.List)):
+			fmt.Fprintln(r.errOut, r.renderer().Render(err))
+			continue
+		case err != nil:
+			return err
+		}
+
+		if cmd != "" {
+			err = r.interruptible(ctx, func(ctx context.Context) error { return r.command(ctx, cmd) })
+		} else {
+			err = r.interruptible(ctx, func(ctx context.Context) error { return r.execute(ctx, stmts) })
+		}
+		if err != nil {
+			if errors.Is(err, ierrors.ErrExit) {
+				return err
+			}
+			fmt.Fprintln(r.errOut, r.renderer().Render(err))
+		}
+	}
+}
+
+// read reads lines until they make up whole statements, or until they have a syntax error that more lines
+// can't fix, and parses them. A first line that starts with a colon is a command, which read returns as it is.
+func (r *REPL) read() ([]ast.Stmt, string, error) {
+	var (
+		input  strings.Builder
+		first  = r.line + 1
+		offset = r.session.Len()
+	)
+	for {
+		prompt := fmt.Sprintf("#%3d > ", r.line+1)
+		if input.Len() > 0 {
+			prompt = Continuation
+		}
+		line, err := r.editor.ReadLine(prompt)
+		if err != nil {
+			// Input that ends partway through a statement is parsed anyway to report what's missing,
+			// at the end of its last line.
+			if errors.Is(err, io.EOF) && input.Len() > 0 {
+				stmts, parseErr := parse(strings.TrimSuffix(input.String(), "\n"), first, offset)
+				return stmts, "", parseErr
+			}
+			return nil, "", err
+		}
+		r.editor.AddHistory(line)
+		if input.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			return nil, strings.TrimSpace(line), nil
+		}
+		r.line++
+		r.session.WriteString(line + "\n")
+		input.WriteString(line + "\n")
+
+		stmts, err := parse(input.String(), first, offset)
+		if err == nil || !incomplete(err, r.session.Len()) {
+			return stmts, "", err
+		}
+	}
+}
+
+// interruptible runs f until it finishes or the process is interrupted.
+func (r *REPL) interruptible(ctx context.Context, f func(context.Context) error) error {
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	err := f(runCtx)
+	if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+		return lineedit.ErrInterrupted
+	}
+	return err
+}
+
+// execute runs statements, printing the value of a lone expression.
+func (r *REPL) execute(ctx context.Context, stmts []ast.Stmt) error {
+	if len(stmts) == 1 {
+		if exprStmt, ok := stmts[0].(*ast.ExpressionStmt); ok {
+			value, err := r.interpreter.EvaluateContext(ctx, exprStmt.Expression)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(r.out, value.String())
+			return nil
+		}
+	}
+	return r.interpreter.InterpretContext(ctx, stmts)
+}
+
+func (r *REPL) renderer() ierrors.Renderer {
+	return ierrors.Renderer{Source: r.session.String()}
+}
+
+// parse parses input that starts at the given line and offset of the session.
+func parse(input string, line, offset int) ([]ast.Stmt, error) {
+	s := scanner.New(input, scanner.WithStartingLine(line), scanner.WithStartingOffset(offset))
+	tokens, scanErr := s.ScanTokens()
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return parser.New(tokens, parser.InREPLMode()).Parse()
+}
+
+// incomplete reports whether every syntax error in err runs into the end of the input, at the given offset,
+// as they do when a bracket isn't closed, a semicolon is missing, or a string isn't terminated,
+// so that more input might complete the statement.
+func incomplete(err error, end int) bool {
+	var list ierrors.List
+	if !errors.As(err, &list) {
+		return false
+	}
+	for _, e := range list {
+		if e.Offset+e.Length < end {
+			return false
+		}
+	}
+	return true
+}
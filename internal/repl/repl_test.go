@@ -0,0 +1,256 @@
+package repl_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/lineedit"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/repl"
+)
+
+func TestREPL_Run(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name   string
+		Input  string
+		Output string
+		Errors string
+	}
+
+	tests := []Test{
+		{
+			Name:   "expression",
+			Input:  "1 + 2\n",
+			Output: "#  1 > 3\n#  2 > ",
+		},
+		{
+			Name:   "statements_across_lines",
+			Input:  "fun twice(a) {\n  return a * 2;\n}\ntwice(21)\n",
+			Output: "#  1 >    ...    ... #  4 > 42\n#  5 > ",
+		},
+		{
+			Name:   "unclosed_parenthesis",
+			Input:  "print (1 +\n2);\n",
+			Output: "#  1 >    ... 3\n#  3 > ",
+		},
+		{
+			Name:   "missing_semicolon",
+			Input:  "var a = 1\n;\na\n",
+			Output: "#  1 >    ... #  3 > 1\n#  4 > ",
+		},
+		{
+			Name:   "multiline_string",
+			Input:  "print \"one\ntwo\";\n",
+			Output: "#  1 >    ... one\ntwo\n#  3 > ",
+		},
+		{
+			Name:   "syntax_error",
+			Input:  "print (1 + ;\n1\n",
+			Output: "#  1 > #  2 > 1\n#  3 > ",
+			Errors: "1:12: error at ';': expect expression\n" +
+				" 1 | print (1 + ;\n" +
+				"   |            ^\n",
+		},
+		{
+			Name:   "runtime_error_quotes_earlier_line",
+			Input:  "fun f() {\n  return -\"a\";\n}\nf();\n",
+			Output: "#  1 >    ...    ... #  4 > #  5 > ",
+			Errors: "2:10: error at '-': cannot apply minus operator: non-numeric type-error\n" +
+				" 2 |   return -\"a\";\n" +
+				"   |          ^\n" +
+				"  at f (line 2)\n" +
+				"  at <script> (line 4)\n",
+		},
+		{
+			Name:   "input_ends_partway",
+			Input:  "print 1 +",
+			Output: "#  1 >    ... #  2 > ",
+			Errors: "1:10: error at end: expect expression\n" +
+				" 1 | print 1 +\n" +
+				"   |          ^\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			var out, errOut bytes.Buffer
+			r := repl.New(lineedit.New(strings.NewReader(test.Input), &out), &out, &errOut)
+
+			require.NoError(t, r.Run(t.Context()))
+			assert.Equal(t, test.Output, out.String())
+			assert.Equal(t, test.Errors, errOut.String())
+		})
+	}
+}
+
+func TestREPL_Run_exit(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := repl.New(
+		lineedit.New(strings.NewReader("exit();\nprint 1;\n"), &out), &out, &out,
+		interpreter.WithCapabilities(native.CapabilityProcess),
+	)
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ierrors.ErrExit)
+	assert.Equal(t, "#  1 > ", out.String())
+}
+
+func TestREPL_Run_history(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	editor := lineedit.New(strings.NewReader("fun f() {\n}\n\nprint 1;\n"), &out)
+	r := repl.New(editor, &out, &out)
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Equal(t, []string{"fun f() {", "}", "print 1;"}, editor.History())
+}
+
+func TestREPL_Run_commands(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"lib.lox": "fun square(x) {\n  return x * x;\n}\n"})
+
+	type Test struct {
+		Name   string
+		Input  string
+		Output string
+		Errors string
+	}
+
+	tests := []Test{
+		{
+			Name:   "type",
+			Input:  ":type 1 + 2\n:type \"a\"\n:type len\nclass P {}\n:type P\n:type P()\n",
+			Output: "#  1 > number\n#  1 > string\n#  1 > native function\n#  1 > #  2 > class\n#  2 > instance of P\n#  2 > ",
+		},
+		{
+			Name:   "ast",
+			Input:  ":ast if (a) print -1; else b = c.d;\n",
+			Output: "#  1 > if (a) print (- 1); else (= b (. d c));\n#  1 > ",
+		},
+		{
+			Name:  "tokens",
+			Input: ":tokens print \"a b\";\n",
+			Output: "#  1 > 1:1 TypePrint \"print\"\n1:7 TypeString \"\\\"a b\\\"\"\n1:12 TypeSemicolon \";\"\n" +
+				"1:13 TypeEOF \"\"\n#  1 > ",
+		},
+		{
+			Name:   "load",
+			Input:  ":load lib.lox\nsquare(3)\n",
+			Output: "#  1 > #  1 > 9\n#  2 > ",
+		},
+		{
+			Name:   "reset",
+			Input:  "var a = 1;\n:reset\na\n",
+			Output: "#  1 > #  2 > #  1 > #  2 > ",
+			Errors: "1:1: error at 'a': undefined variable: a\n" +
+				" 1 | a\n" +
+				"   | ^\n",
+		},
+		{
+			Name:   "syntax_error",
+			Input:  ":ast print (1 +;\n",
+			Output: "#  1 > #  1 > ",
+			Errors: "1:11: error at ';': expect expression\n" +
+				" 1 | print (1 +;\n" +
+				"   |           ^\n",
+		},
+		{
+			Name:   "unknown",
+			Input:  ":nope\n",
+			Output: "#  1 > #  1 > ",
+			Errors: "error: unknown command: :nope\n",
+		},
+		{
+			Name:   "missing_argument",
+			Input:  ":load\n",
+			Output: "#  1 > #  1 > ",
+			Errors: "error: missing argument: :load needs file\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			var out, errOut bytes.Buffer
+			r := repl.New(lineedit.New(strings.NewReader(test.Input), &out), &out, &errOut,
+				interpreter.WithCapabilities(native.CapabilityFS),
+				interpreter.WithHost(native.Host{FS: fsys}),
+			)
+
+			require.NoError(t, r.Run(t.Context()))
+			assert.Equal(t, test.Output, out.String())
+			assert.Equal(t, test.Errors, errOut.String())
+		})
+	}
+}
+
+func TestREPL_Run_env(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := repl.New(lineedit.New(strings.NewReader("var a = [1];\nfun f() {}\n:env\n"), &out), &out, &out)
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Contains(t, out.String(), "a = [1]\n")
+	assert.Contains(t, out.String(), "\nf = <fn: f>\n")
+	assert.Contains(t, out.String(), "\nlen = <native fn: len>\n")
+}
+
+func TestREPL_Complete(t *testing.T) {
+	t.Parallel()
+
+	setup := "class A { init() { this.field = 1; } method() {} }\n" +
+		"class B < A { other() {} }\n" +
+		"var b = B();\n" +
+		"var printer = 1;\n" +
+		"var total_2 = 2;\n"
+
+	type Test struct {
+		Name       string
+		Before     string
+		Start      int
+		Candidates []string
+	}
+
+	tests := []Test{
+		{Name: "keywords_and_globals", Before: "pri", Start: 0, Candidates: []string{"print", "printer"}},
+		{Name: "natives", Before: "print clo", Start: 6, Candidates: []string{"clock"}},
+		{Name: "after_operator", Before: "1+tot", Start: 2, Candidates: []string{"total_2"}},
+		{Name: "after_multibyte_text", Before: "\"é\" + tot", Start: 7, Candidates: []string{"total_2"}},
+		{Name: "nothing_typed", Before: "print ", Start: 6},
+		{Name: "properties", Before: "b.", Start: 2, Candidates: []string{"field", "init", "method", "other"}},
+		{Name: "property_prefix", Before: "print b.m", Start: 8, Candidates: []string{"method"}},
+		{Name: "call_before_dot", Before: "B().", Start: 4},
+		{Name: "undefined_before_dot", Before: "nope.", Start: 5},
+		{Name: "no_properties", Before: "printer.", Start: 8},
+	}
+
+	var out bytes.Buffer
+	r := repl.New(
+		lineedit.New(strings.NewReader(setup), &out), &out, &out,
+		interpreter.WithCapabilities(native.CapabilityTime),
+	)
+	require.NoError(t, r.Run(t.Context()))
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			start, candidates := r.Complete(test.Before)
+			assert.Equal(t, test.Start, start)
+			assert.Equal(t, test.Candidates, candidates)
+		})
+	}
+}
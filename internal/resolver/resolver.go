@@ -0,0 +1,588 @@
+//nolint:nilnil // It's the only way for now.
+
+// Package resolver statically resolves the variables of a program to the scopes that declare them.
+package resolver
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/matt-hoiland/glox/internal/ast"
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/token"
+)
+
+var (
+	ErrBreakOutsideLoop       = errors.New("can't use 'break' outside of a loop")
+	ErrContinueOutsideLoop    = errors.New("can't use 'continue' outside of a loop")
+	ErrInheritFromSelf        = errors.New("a class can't inherit from itself")
+	ErrReadInOwnInitializer   = errors.New("can't read local variable in its own initializer")
+	ErrRedeclaration          = errors.New("redeclaration of scoped variable")
+	ErrReturnFromInitializer  = errors.New("can't return a value from an initializer")
+	ErrReturnFromTopLevel     = errors.New("can't return from top-level code")
+	ErrSuperOutsideClass      = errors.New("can't use 'super' outside of a class")
+	ErrSuperWithoutSuperclass = errors.New("can't use 'super' in a class with no superclass")
+	ErrThisOutsideClass       = errors.New("can't use 'this' outside of a class")
+)
+
+type functionType string
+
+const (
+	none        functionType = "NONE"
+	function    functionType = "FUNCTION"
+	method      functionType = "METHOD"
+	initializer functionType = "INITIALIZER"
+)
+
+type classType string
+
+const (
+	noClass  classType = "NONE"
+	class    classType = "CLASS"
+	subclass classType = "SUBCLASS"
+)
+
+// Binding is a name a program declares, along with every reference to it.
+type Binding struct {
+	Name *token.Token
+	// Global is true for names declared at the top level of the program.
+	Global     bool
+	References []*token.Token
+}
+
+// Resolver checks a program for misplaced declarations and records, for every variable it references,
+// how many scopes lie between the reference and the declaration.
+type Resolver struct {
+	locals map[ast.Expr]int
+	scopes []map[string]bool
+	// declarations parallels scopes when bindings are being recorded, holding the bindings each scope declares.
+	declarations []map[string]*Binding
+	bindings     []*Binding
+	// unresolved holds references to names no enclosing scope had declared yet,
+	// which are globals declared later in the program, or not at all.
+	unresolved      []*token.Token
+	currentFunction functionType
+	currentClass    classType
+	// loopDepth counts the loops around the statement being resolved, within the current function.
+	loopDepth int
+}
+
+var (
+	_ ast.StmtVisitor = (*Resolver)(nil)
+	_ ast.ExprVisitor = (*Resolver)(nil)
+)
+
+type Option func(*Resolver)
+
+// WithBindings records every name the program declares and the references that resolve to it,
+// for [Resolver.Bindings] to return, as tools that navigate programs need.
+func WithBindings() Option {
+	return func(r *Resolver) {
+		r.declarations = []map[string]*Binding{{}}
+	}
+}
+
+// New creates a resolver that records the distances it resolves into locals.
+// References that aren't recorded are to globals.
+func New(locals map[ast.Expr]int, opts ...Option) *Resolver {
+	r := &Resolver{
+		locals: locals,
+		scopes: []map[string]bool{
+			{}, // global scope
+		},
+		currentFunction: none,
+		currentClass:    noClass,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve resolves the statements of a program.
+func (r *Resolver) Resolve(stmts []ast.Stmt) error {
+	return r.resolveStmts(stmts)
+}
+
+// Bindings returns the names the program declared, in the order it declared them, if the resolver was made
+// [WithBindings]. References that were resolved before their global was declared, as they are within functions,
+// are given to the last global of that name. Each binding's references are in the order they appear in the source.
+func (r *Resolver) Bindings() []*Binding {
+	if r.declarations == nil {
+		return nil
+	}
+	globals := r.declarations[0]
+	for _, ref := range r.unresolved {
+		if b, ok := globals[ref.Lexeme]; ok {
+			b.References = append(b.References, ref)
+		}
+	}
+	r.unresolved = nil
+	for _, b := range r.bindings {
+		slices.SortFunc(b.References, func(a, b *token.Token) int { return cmp.Compare(a.Offset, b.Offset) })
+	}
+	return r.bindings
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, map[string]bool{})
+	if r.declarations != nil {
+		r.declarations = append(r.declarations, map[string]*Binding{})
+	}
+}
+
+func (r *Resolver) currentScope() map[string]bool {
+	return r.scopes[len(r.scopes)-1]
+}
+
+func (r *Resolver) declare(name *token.Token) error {
+	if len(r.scopes) == 0 {
+		return ierrors.New(name, errors.New("no scope"))
+	}
+
+	if _, declared := r.currentScope()[name.Lexeme]; declared {
+		return ierrors.New(name, ErrRedeclaration)
+	}
+	r.currentScope()[name.Lexeme] = false
+	if r.declarations != nil {
+		b := &Binding{Name: name, Global: len(r.scopes) == 1}
+		r.declarations[len(r.declarations)-1][name.Lexeme] = b
+		r.bindings = append(r.bindings, b)
+	}
+	return nil
+}
+
+func (r *Resolver) define(name *token.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	r.currentScope()[name.Lexeme] = true
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[0 : len(r.scopes)-1]
+	if r.declarations != nil {
+		r.declarations = r.declarations[:len(r.declarations)-1]
+	}
+}
+
+func (r *Resolver) resolveFunction(s *ast.FunctionStmt, ft functionType) error {
+	enclosingFunction, enclosingLoopDepth := r.currentFunction, r.loopDepth
+	r.currentFunction, r.loopDepth = ft, 0
+
+	r.beginScope()
+	for _, param := range s.Params {
+		if err := r.declare(param); err != nil {
+			return err
+		}
+		r.define(param)
+	}
+	if err := r.resolveStmts(s.Body); err != nil {
+		return err
+	}
+	r.endScope()
+
+	r.currentFunction, r.loopDepth = enclosingFunction, enclosingLoopDepth
+	return nil
+}
+
+func (r *Resolver) resolveLocal(e ast.Expr, name *token.Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			r.locals[e] = len(r.scopes) - 1 - i
+			r.reference(i, name)
+			return
+		}
+	}
+	if r.declarations != nil {
+		r.unresolved = append(r.unresolved, name)
+	}
+}
+
+// reference records a reference to the binding the scope at the given depth declares,
+// unless it's one the resolver binds itself, such as "this".
+func (r *Resolver) reference(depth int, name *token.Token) {
+	if r.declarations == nil {
+		return
+	}
+	if b, ok := r.declarations[depth][name.Lexeme]; ok {
+		b.References = append(b.References, name)
+	}
+}
+
+func (r *Resolver) resolveStmts(stmts []ast.Stmt) error {
+	for _, stmt := range stmts {
+		if err := r.resolveStmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) resolveStmt(s ast.Stmt) error {
+	if _, err := s.Accept(nil, r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Resolver) resolveExpr(e ast.Expr) error {
+	if _, err := e.Accept(nil, r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBlockStmt(_ *environment.Environment, s *ast.BlockStmt) (ast.Completion, error) {
+	r.beginScope()
+	if err := r.resolveStmts(s.Statements); err != nil {
+		return ast.Completion{}, err
+	}
+	r.endScope()
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitBreakStmt(_ *environment.Environment, s *ast.BreakStmt) (ast.Completion, error) {
+	if r.loopDepth == 0 {
+		return ast.Completion{}, ierrors.New(s.Keyword, ErrBreakOutsideLoop)
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitClassStmt(_ *environment.Environment, s *ast.ClassStmt) (ast.Completion, error) {
+	enclosingClass := r.currentClass
+	r.currentClass = class
+
+	if err := r.declare(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	r.define(s.Name)
+
+	if s.Superclass != nil {
+		if s.Name.Lexeme == s.Superclass.Name.Lexeme {
+			return ast.Completion{}, ierrors.New(s.Superclass.Name, ErrInheritFromSelf)
+		}
+		r.currentClass = subclass
+		if err := r.resolveExpr(s.Superclass); err != nil {
+			return ast.Completion{}, err
+		}
+
+		r.beginScope()
+		r.currentScope()["super"] = true
+	}
+
+	r.beginScope()
+	r.currentScope()["this"] = true
+
+	for _, m := range s.Methods {
+		declaration := method
+		if m.Name.Lexeme == "init" {
+			declaration = initializer
+		}
+		if err := r.resolveFunction(m, declaration); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+
+	r.endScope()
+
+	if s.Superclass != nil {
+		r.endScope()
+	}
+
+	r.currentClass = enclosingClass
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitContinueStmt(_ *environment.Environment, s *ast.ContinueStmt) (ast.Completion, error) {
+	if r.loopDepth == 0 {
+		return ast.Completion{}, ierrors.New(s.Keyword, ErrContinueOutsideLoop)
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitExpressionStmt(_ *environment.Environment, s *ast.ExpressionStmt) (ast.Completion, error) {
+	if err := r.resolveExpr(s.Expression); err != nil {
+		return ast.Completion{}, err
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitFunctionStmt(_ *environment.Environment, s *ast.FunctionStmt) (ast.Completion, error) {
+	if err := r.declare(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	r.define(s.Name)
+
+	if err := r.resolveFunction(s, function); err != nil {
+		return ast.Completion{}, err
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitIfStmt(_ *environment.Environment, s *ast.IfStmt) (ast.Completion, error) {
+	if err := r.resolveExpr(s.Condition); err != nil {
+		return ast.Completion{}, err
+	}
+	if err := r.resolveStmt(s.ThenBranch); err != nil {
+		return ast.Completion{}, err
+	}
+	if s.ElseBranch != nil {
+		if err := r.resolveStmt(s.ElseBranch); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitImportStmt(_ *environment.Environment, s *ast.ImportStmt) (ast.Completion, error) {
+	if err := r.declare(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	r.define(s.Name)
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitPrintStmt(_ *environment.Environment, s *ast.PrintStmt) (ast.Completion, error) {
+	if err := r.resolveExpr(s.Expression); err != nil {
+		return ast.Completion{}, err
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitReturnStmt(_ *environment.Environment, s *ast.ReturnStmt) (ast.Completion, error) {
+	if r.currentFunction == none {
+		return ast.Completion{}, ierrors.New(s.Keyword, ErrReturnFromTopLevel)
+	}
+	if s.Value != nil {
+		if r.currentFunction == initializer {
+			return ast.Completion{}, ierrors.New(s.Keyword, ErrReturnFromInitializer)
+		}
+		if err := r.resolveExpr(s.Value); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitThrowStmt(_ *environment.Environment, s *ast.ThrowStmt) (ast.Completion, error) {
+	if err := r.resolveExpr(s.Value); err != nil {
+		return ast.Completion{}, err
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitTryStmt(_ *environment.Environment, s *ast.TryStmt) (ast.Completion, error) {
+	r.beginScope()
+	if err := r.resolveStmts(s.Body); err != nil {
+		return ast.Completion{}, err
+	}
+	r.endScope()
+
+	// The error is bound in the same scope as the catch clause's body, just as parameters are.
+	if s.CatchName != nil {
+		r.beginScope()
+		if err := r.declare(s.CatchName); err != nil {
+			return ast.Completion{}, err
+		}
+		r.define(s.CatchName)
+		if err := r.resolveStmts(s.CatchBody); err != nil {
+			return ast.Completion{}, err
+		}
+		r.endScope()
+	}
+
+	if s.FinallyBody != nil {
+		r.beginScope()
+		if err := r.resolveStmts(s.FinallyBody); err != nil {
+			return ast.Completion{}, err
+		}
+		r.endScope()
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitVarStmt(_ *environment.Environment, s *ast.VarStmt) (ast.Completion, error) {
+	if err := r.declare(s.Name); err != nil {
+		return ast.Completion{}, err
+	}
+	if s.Initializer != nil {
+		if err := r.resolveExpr(s.Initializer); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	r.define(s.Name)
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitWhileStmt(_ *environment.Environment, s *ast.WhileStmt) (ast.Completion, error) {
+	if err := r.resolveExpr(s.Condition); err != nil {
+		return ast.Completion{}, err
+	}
+	r.loopDepth++
+	if err := r.resolveStmt(s.Body); err != nil {
+		return ast.Completion{}, err
+	}
+	r.loopDepth--
+	if s.Increment != nil {
+		if err := r.resolveExpr(s.Increment); err != nil {
+			return ast.Completion{}, err
+		}
+	}
+	return ast.Completion{}, nil
+}
+
+func (r *Resolver) VisitAssignExpr(_ *environment.Environment, e *ast.AssignExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Value); err != nil {
+		return nil, err
+	}
+	r.resolveLocal(e, e.Name)
+	return nil, nil
+}
+
+func (r *Resolver) VisitBinaryExpr(_ *environment.Environment, e *ast.BinaryExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Left); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Right); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitCallExpr(_ *environment.Environment, e *ast.CallExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Callee); err != nil {
+		return nil, err
+	}
+
+	for _, expr := range e.Arguments {
+		if err := r.resolveExpr(expr); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Resolver) VisitGetExpr(_ *environment.Environment, e *ast.GetExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Object); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitGroupingExpr(_ *environment.Environment, e *ast.GroupingExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Expression); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitIndexExpr(_ *environment.Environment, e *ast.IndexExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Object); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Index); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitListExpr(_ *environment.Environment, e *ast.ListExpr) (loxtype.Type, error) {
+	for _, element := range e.Elements {
+		if err := r.resolveExpr(element); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitLiteralExpr(*environment.Environment, *ast.LiteralExpr) (loxtype.Type, error) {
+	return nil, nil
+}
+
+func (r *Resolver) VisitLogicalExpr(_ *environment.Environment, e *ast.LogicalExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Left); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Right); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitMapExpr(_ *environment.Environment, e *ast.MapExpr) (loxtype.Type, error) {
+	for k := range e.Keys {
+		if err := r.resolveExpr(e.Keys[k]); err != nil {
+			return nil, err
+		}
+		if err := r.resolveExpr(e.Values[k]); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitSetExpr(_ *environment.Environment, e *ast.SetExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Value); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Object); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitSetIndexExpr(_ *environment.Environment, e *ast.SetIndexExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Value); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Object); err != nil {
+		return nil, err
+	}
+	if err := r.resolveExpr(e.Index); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitSuperExpr(_ *environment.Environment, e *ast.SuperExpr) (loxtype.Type, error) {
+	switch r.currentClass {
+	case noClass:
+		return nil, ierrors.New(e.Keyword, ErrSuperOutsideClass)
+	case class:
+		return nil, ierrors.New(e.Keyword, ErrSuperWithoutSuperclass)
+	case subclass:
+		break
+	}
+	r.resolveLocal(e, e.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitThisExpr(_ *environment.Environment, e *ast.ThisExpr) (loxtype.Type, error) {
+	if r.currentClass == noClass {
+		return nil, ierrors.New(e.Keyword, ErrThisOutsideClass)
+	}
+	r.resolveLocal(e, e.Keyword)
+	return nil, nil
+}
+
+func (r *Resolver) VisitUnaryExpr(_ *environment.Environment, e *ast.UnaryExpr) (loxtype.Type, error) {
+	if err := r.resolveExpr(e.Right); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (r *Resolver) VisitVariableExpr(_ *environment.Environment, e *ast.VariableExpr) (loxtype.Type, error) {
+	if defined, ok := r.currentScope()[e.Name.Lexeme]; ok && !defined {
+		return nil, ierrors.New(e.Name, ErrReadInOwnInitializer)
+	}
+
+	r.resolveLocal(e, e.Name)
+	return nil, nil
+}
@@ -2,22 +2,40 @@ package scanner
 
 import "github.com/matt-hoiland/glox/internal/token"
 
+// Keywords returns the reserved words, in sorted order.
+func Keywords() []string {
+	return []string{
+		"and", "break", "catch", "class", "continue", "else", "false", "finally", "for", "fun", "if", "import",
+		"nil", "or", "print", "return", "super", "this", "throw", "true", "try", "var", "while",
+	}
+}
+
 func keywords(key string) (token.Type, bool) {
 	switch key {
 	case "and":
 		return token.TypeAnd, true
+	case "break":
+		return token.TypeBreak, true
+	case "catch":
+		return token.TypeCatch, true
 	case "class":
 		return token.TypeClass, true
+	case "continue":
+		return token.TypeContinue, true
 	case "else":
 		return token.TypeElse, true
 	case "false":
 		return token.TypeFalse, true
+	case "finally":
+		return token.TypeFinally, true
 	case "for":
 		return token.TypeFor, true
 	case "fun":
 		return token.TypeFun, true
 	case "if":
 		return token.TypeIf, true
+	case "import":
+		return token.TypeImport, true
 	case "nil":
 		return token.TypeNil, true
 	case "or":
@@ -30,8 +48,12 @@ func keywords(key string) (token.Type, bool) {
 		return token.TypeSuper, true
 	case "this":
 		return token.TypeThis, true
+	case "throw":
+		return token.TypeThrow, true
 	case "true":
 		return token.TypeTrue, true
+	case "try":
+		return token.TypeTry, true
 	case "var":
 		return token.TypeVar, true
 	case "while":
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"errors"
+	"unicode/utf8"
 
 	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
@@ -17,9 +18,23 @@ var (
 type Scanner struct {
 	source  []runes.Rune
 	tokens  []*token.Token
+	errs    ierrors.List
 	start   int
 	current int
 	line    int
+
+	// offset is the byte offset of the current rune, and lineStart is the index of the first rune on the current line.
+	offset    int
+	lineStart int
+
+	// These record where the lexeme being scanned begins.
+	startLine   int
+	startColumn int
+	startOffset int
+
+	// keepComments says to attach comments to the tokens that follow them, which comments holds until then.
+	keepComments bool
+	comments     []*token.Comment
 }
 
 type Option func(*Scanner)
@@ -30,6 +45,22 @@ func WithStartingLine(line int) Option {
 	}
 }
 
+// WithStartingOffset shifts the byte offsets of every token,
+// which is useful when the source is a fragment of some larger text.
+func WithStartingOffset(offset int) Option {
+	return func(s *Scanner) {
+		s.offset = offset
+	}
+}
+
+// WithComments keeps the comments in the source as trivia on the tokens that follow them,
+// for tools that have to reproduce the source rather than run it.
+func WithComments() Option {
+	return func(s *Scanner) {
+		s.keepComments = true
+	}
+}
+
 func New(source string, opts ...Option) *Scanner {
 	s := &Scanner{
 		source: []runes.Rune(source),
@@ -41,27 +72,39 @@ func New(source string, opts ...Option) *Scanner {
 	return s
 }
 
+// ScanTokens scans the whole source, skipping past any bad input so that every error can be reported at once.
+// The returned error, if any, is an [ierrors.List].
 func (s *Scanner) ScanTokens() ([]*token.Token, error) {
 	for !s.isAtEnd() {
 		// We are at the beginning of the next lexeme.
-		s.start = s.current
+		s.mark()
 		if err := s.scanToken(); err != nil {
-			return s.tokens, err
+			s.errs = append(s.errs, err)
 		}
 	}
 
-	s.tokens = append(s.tokens, &token.Token{
+	s.mark()
+	s.addToken(&token.Token{
 		Type:    token.TypeEOF,
 		Lexeme:  "",
 		Literal: nil,
 		Line:    s.line,
+		Column:  s.startColumn,
+		Offset:  s.startOffset,
 	})
-	return s.tokens, nil
+	return s.tokens, s.errs.Err()
+}
+
+// addToken adds a scanned token, giving it the comments kept since the last one.
+func (s *Scanner) addToken(tok *token.Token) {
+	tok.Comments, s.comments = s.comments, nil
+	s.tokens = append(s.tokens, tok)
 }
 
 func (s *Scanner) advance() runes.Rune {
 	r := s.source[s.current]
 	s.current++
+	s.offset += utf8.RuneLen(rune(r))
 	return r
 }
 
@@ -78,7 +121,7 @@ func (s *Scanner) emitIdentifier() *token.Token {
 	return s.emitToken(tokenType)
 }
 
-func (s *Scanner) emitNumber() *token.Token {
+func (s *Scanner) emitNumber() (*token.Token, *ierrors.Error) {
 	for s.peek().IsDigit() {
 		s.advance()
 	}
@@ -93,19 +136,22 @@ func (s *Scanner) emitNumber() *token.Token {
 		}
 	}
 
-	return s.emitToken(token.TypeNumber, loxtype.ParseNumber(s.source[s.start:s.current]))
+	n, err := loxtype.ParseNumber(s.source[s.start:s.current])
+	if err != nil {
+		return nil, s.newError(" at '"+string(s.source[s.start:s.current])+"'", err)
+	}
+	return s.emitToken(token.TypeNumber, n), nil
 }
 
-func (s *Scanner) emitString() (*token.Token, error) {
+func (s *Scanner) emitString() (*token.Token, *ierrors.Error) {
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		if s.advance() == '\n' {
+			s.newline()
 		}
-		s.advance()
 	}
 
 	if s.isAtEnd() {
-		return nil, &ierrors.Error{Line: s.line, Err: ErrUnterminatedString}
+		return nil, s.newError("", ErrUnterminatedString)
 	}
 
 	s.advance()
@@ -117,6 +163,9 @@ func (s *Scanner) emitToken(tokenType token.Type, literal ...loxtype.Type) *toke
 		Type:   tokenType,
 		Lexeme: string(s.source[s.start:s.current]),
 		Line:   s.line,
+		Column: s.startColumn,
+		Offset: s.startOffset,
+		Length: s.offset - s.startOffset,
 	}
 	if len(literal) > 0 {
 		token.Literal = literal[0]
@@ -128,6 +177,14 @@ func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 }
 
+// mark records the current position as the beginning of the next lexeme.
+func (s *Scanner) mark() {
+	s.start = s.current
+	s.startLine = s.line
+	s.startColumn = s.current - s.lineStart + 1
+	s.startOffset = s.offset
+}
+
 func (s *Scanner) match(expected runes.Rune) bool {
 	if s.isAtEnd() {
 		return false
@@ -135,10 +192,28 @@ func (s *Scanner) match(expected runes.Rune) bool {
 	if s.source[s.current] != expected {
 		return false
 	}
-	s.current++
+	s.advance()
 	return true
 }
 
+// newError reports err at the lexeme being scanned.
+func (s *Scanner) newError(where string, err error) *ierrors.Error {
+	return &ierrors.Error{
+		Line:   s.startLine,
+		Column: s.startColumn,
+		Offset: s.startOffset,
+		Length: s.offset - s.startOffset,
+		Where:  where,
+		Err:    err,
+	}
+}
+
+// newline must be called after consuming a line break.
+func (s *Scanner) newline() {
+	s.line++
+	s.lineStart = s.current
+}
+
 func (s *Scanner) ifMatchEqualSign(t, f token.Type) token.Type {
 	if s.match('=') {
 		return t
@@ -160,11 +235,11 @@ func (s *Scanner) peekNext() runes.Rune {
 	return s.source[s.current+1]
 }
 
-func (s *Scanner) scanToken() error {
+func (s *Scanner) scanToken() *ierrors.Error {
 	var (
 		r   = s.advance()
 		tok *token.Token
-		err error
+		err *ierrors.Error
 	)
 
 	switch r {
@@ -176,6 +251,12 @@ func (s *Scanner) scanToken() error {
 		tok = s.emitToken(token.TypeLeftBrace)
 	case '}':
 		tok = s.emitToken(token.TypeRightBrace)
+	case '[':
+		tok = s.emitToken(token.TypeLeftBracket)
+	case ']':
+		tok = s.emitToken(token.TypeRightBracket)
+	case ':':
+		tok = s.emitToken(token.TypeColon)
 	case ',':
 		tok = s.emitToken(token.TypeComma)
 	case '.':
@@ -204,7 +285,7 @@ func (s *Scanner) scanToken() error {
 		// Ignore whitespace.
 		return nil
 	case '\n':
-		s.line++
+		s.newline()
 		return nil
 	case '"':
 		if tok, err = s.emitString(); err != nil {
@@ -214,15 +295,17 @@ func (s *Scanner) scanToken() error {
 
 	switch {
 	case r.IsDigit():
-		tok = s.emitNumber()
+		if tok, err = s.emitNumber(); err != nil {
+			return err
+		}
 	case r.IsAlpha():
 		tok = s.emitIdentifier()
 	}
 
 	if tok == nil {
-		return &ierrors.Error{Line: s.line, Err: ErrUnexpectedRune}
+		return s.newError(" at '"+string(r)+"'", ErrUnexpectedRune)
 	}
-	s.tokens = append(s.tokens, tok)
+	s.addToken(tok)
 	return nil
 }
 
@@ -232,6 +315,13 @@ func (s *Scanner) consumeComment() *token.Token {
 		for s.peek() != '\n' && !s.isAtEnd() {
 			s.advance()
 		}
+		if s.keepComments {
+			s.comments = append(s.comments, &token.Comment{
+				Text:   string(s.source[s.start:s.current]),
+				Line:   s.startLine,
+				Offset: s.startOffset,
+			})
+		}
 		return nil
 	}
 	return s.emitToken(token.TypeSlash)
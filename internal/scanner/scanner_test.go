@@ -1,11 +1,14 @@
 package scanner_test
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
 	"github.com/matt-hoiland/glox/internal/loxtype"
 	"github.com/matt-hoiland/glox/internal/runes"
 	"github.com/matt-hoiland/glox/internal/scanner"
@@ -27,19 +30,22 @@ func TestScanner_ScanTokens(t *testing.T) {
 			Name:   "success/empty_source",
 			Source: ``,
 			Tokens: []*token.Token{
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 1},
 			},
 		},
 		{
 			Name:   "success/assign_string_to_variable",
 			Source: `var language = "lox";`,
 			Tokens: []*token.Token{
-				{Type: token.TypeVar, Lexeme: `var`, Line: 1},
-				{Type: token.TypeIdentifier, Lexeme: `language`, Line: 1},
-				{Type: token.TypeEqual, Lexeme: `=`, Line: 1},
-				{Type: token.TypeString, Lexeme: `"lox"`, Literal: loxtype.String([]runes.Rune("lox")), Line: 1},
-				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeVar, Lexeme: `var`, Line: 1, Column: 1, Offset: 0, Length: 3},
+				{Type: token.TypeIdentifier, Lexeme: `language`, Line: 1, Column: 5, Offset: 4, Length: 8},
+				{Type: token.TypeEqual, Lexeme: `=`, Line: 1, Column: 14, Offset: 13, Length: 1},
+				{
+					Type: token.TypeString, Lexeme: `"lox"`, Literal: loxtype.String([]runes.Rune("lox")),
+					Line: 1, Column: 16, Offset: 15, Length: 5,
+				},
+				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1, Column: 21, Offset: 20, Length: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 22, Offset: 21},
 			},
 		},
 		{
@@ -52,12 +58,15 @@ func TestScanner_ScanTokens(t *testing.T) {
 			Name:   "success/assign_number_to_variable",
 			Source: `var pi = 873.32;`,
 			Tokens: []*token.Token{
-				{Type: token.TypeVar, Lexeme: `var`, Line: 1},
-				{Type: token.TypeIdentifier, Lexeme: `pi`, Line: 1},
-				{Type: token.TypeEqual, Lexeme: `=`, Line: 1},
-				{Type: token.TypeNumber, Lexeme: `873.32`, Literal: loxtype.Number(873.32), Line: 1},
-				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeVar, Lexeme: `var`, Line: 1, Column: 1, Offset: 0, Length: 3},
+				{Type: token.TypeIdentifier, Lexeme: `pi`, Line: 1, Column: 5, Offset: 4, Length: 2},
+				{Type: token.TypeEqual, Lexeme: `=`, Line: 1, Column: 8, Offset: 7, Length: 1},
+				{
+					Type: token.TypeNumber, Lexeme: `873.32`, Literal: loxtype.Number(873.32),
+					Line: 1, Column: 10, Offset: 9, Length: 6,
+				},
+				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1, Column: 16, Offset: 15, Length: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 17, Offset: 16},
 			},
 		},
 		{
@@ -71,40 +80,45 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Literal: loxtype.String([]runes.Rune(`First line
 			Second line`)),
 					Line: 2, // The line number corresponds to the last character.
+					// The column and offset correspond to the first character.
+					Column: 1, Offset: 0, Length: 27,
 				},
-				{Type: token.TypeEOF, Line: 2},
+				{Type: token.TypeEOF, Line: 2, Column: 16, Offset: 27},
 			},
 		},
 		{
 			Name:   "success/single_character_punctuation",
-			Source: `(){}=;*+-/.,!`,
+			Source: `(){}[]=;*+-/.,!:`,
 			Tokens: []*token.Token{
-				{Type: token.TypeLeftParen, Lexeme: `(`, Line: 1},
-				{Type: token.TypeRightParen, Lexeme: `)`, Line: 1},
-				{Type: token.TypeLeftBrace, Lexeme: `{`, Line: 1},
-				{Type: token.TypeRightBrace, Lexeme: `}`, Line: 1},
-				{Type: token.TypeEqual, Lexeme: `=`, Line: 1},
-				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1},
-				{Type: token.TypeStar, Lexeme: `*`, Line: 1},
-				{Type: token.TypePlus, Lexeme: `+`, Line: 1},
-				{Type: token.TypeMinus, Lexeme: `-`, Line: 1},
-				{Type: token.TypeSlash, Lexeme: `/`, Line: 1},
-				{Type: token.TypeDot, Lexeme: `.`, Line: 1},
-				{Type: token.TypeComma, Lexeme: `,`, Line: 1},
-				{Type: token.TypeBang, Lexeme: `!`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeLeftParen, Lexeme: `(`, Line: 1, Column: 1, Offset: 0, Length: 1},
+				{Type: token.TypeRightParen, Lexeme: `)`, Line: 1, Column: 2, Offset: 1, Length: 1},
+				{Type: token.TypeLeftBrace, Lexeme: `{`, Line: 1, Column: 3, Offset: 2, Length: 1},
+				{Type: token.TypeRightBrace, Lexeme: `}`, Line: 1, Column: 4, Offset: 3, Length: 1},
+				{Type: token.TypeLeftBracket, Lexeme: `[`, Line: 1, Column: 5, Offset: 4, Length: 1},
+				{Type: token.TypeRightBracket, Lexeme: `]`, Line: 1, Column: 6, Offset: 5, Length: 1},
+				{Type: token.TypeEqual, Lexeme: `=`, Line: 1, Column: 7, Offset: 6, Length: 1},
+				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 1, Column: 8, Offset: 7, Length: 1},
+				{Type: token.TypeStar, Lexeme: `*`, Line: 1, Column: 9, Offset: 8, Length: 1},
+				{Type: token.TypePlus, Lexeme: `+`, Line: 1, Column: 10, Offset: 9, Length: 1},
+				{Type: token.TypeMinus, Lexeme: `-`, Line: 1, Column: 11, Offset: 10, Length: 1},
+				{Type: token.TypeSlash, Lexeme: `/`, Line: 1, Column: 12, Offset: 11, Length: 1},
+				{Type: token.TypeDot, Lexeme: `.`, Line: 1, Column: 13, Offset: 12, Length: 1},
+				{Type: token.TypeComma, Lexeme: `,`, Line: 1, Column: 14, Offset: 13, Length: 1},
+				{Type: token.TypeBang, Lexeme: `!`, Line: 1, Column: 15, Offset: 14, Length: 1},
+				{Type: token.TypeColon, Lexeme: `:`, Line: 1, Column: 16, Offset: 15, Length: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 17, Offset: 16},
 			},
 		},
 		{
 			Name:   "success/double_character_punctuation",
 			Source: `= <= >= == !=`,
 			Tokens: []*token.Token{
-				{Type: token.TypeEqual, Lexeme: `=`, Line: 1},
-				{Type: token.TypeLessEqual, Lexeme: `<=`, Line: 1},
-				{Type: token.TypeGreaterEqual, Lexeme: `>=`, Line: 1},
-				{Type: token.TypeEqualEqual, Lexeme: `==`, Line: 1},
-				{Type: token.TypeBangEqual, Lexeme: `!=`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeEqual, Lexeme: `=`, Line: 1, Column: 1, Offset: 0, Length: 1},
+				{Type: token.TypeLessEqual, Lexeme: `<=`, Line: 1, Column: 3, Offset: 2, Length: 2},
+				{Type: token.TypeGreaterEqual, Lexeme: `>=`, Line: 1, Column: 6, Offset: 5, Length: 2},
+				{Type: token.TypeEqualEqual, Lexeme: `==`, Line: 1, Column: 9, Offset: 8, Length: 2},
+				{Type: token.TypeBangEqual, Lexeme: `!=`, Line: 1, Column: 12, Offset: 11, Length: 2},
+				{Type: token.TypeEOF, Line: 1, Column: 14, Offset: 13},
 			},
 		},
 		{
@@ -116,28 +130,44 @@ func TestScanner_ScanTokens(t *testing.T) {
 				}
 			`,
 			Tokens: []*token.Token{
-				{Type: token.TypeFun, Lexeme: `fun`, Line: 3},
-				{Type: token.TypeIdentifier, Lexeme: `add_two`, Line: 3},
-				{Type: token.TypeLeftParen, Lexeme: `(`, Line: 3},
-				{Type: token.TypeIdentifier, Lexeme: `n`, Line: 3},
-				{Type: token.TypeRightParen, Lexeme: `)`, Line: 3},
-				{Type: token.TypeLeftBrace, Lexeme: `{`, Line: 3},
-				{Type: token.TypeReturn, Lexeme: `return`, Line: 4},
-				{Type: token.TypeIdentifier, Lexeme: `n`, Line: 4},
-				{Type: token.TypePlus, Lexeme: `+`, Line: 4},
-				{Type: token.TypeNumber, Lexeme: `2`, Literal: loxtype.Number(2), Line: 4},
-				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 4},
-				{Type: token.TypeRightBrace, Lexeme: `}`, Line: 5},
-				{Type: token.TypeEOF, Line: 6},
+				{Type: token.TypeFun, Lexeme: `fun`, Line: 3, Column: 5, Offset: 62, Length: 3},
+				{Type: token.TypeIdentifier, Lexeme: `add_two`, Line: 3, Column: 9, Offset: 66, Length: 7},
+				{Type: token.TypeLeftParen, Lexeme: `(`, Line: 3, Column: 16, Offset: 73, Length: 1},
+				{Type: token.TypeIdentifier, Lexeme: `n`, Line: 3, Column: 17, Offset: 74, Length: 1},
+				{Type: token.TypeRightParen, Lexeme: `)`, Line: 3, Column: 18, Offset: 75, Length: 1},
+				{Type: token.TypeLeftBrace, Lexeme: `{`, Line: 3, Column: 20, Offset: 77, Length: 1},
+				{Type: token.TypeReturn, Lexeme: `return`, Line: 4, Column: 6, Offset: 84, Length: 6},
+				{Type: token.TypeIdentifier, Lexeme: `n`, Line: 4, Column: 13, Offset: 91, Length: 1},
+				{Type: token.TypePlus, Lexeme: `+`, Line: 4, Column: 15, Offset: 93, Length: 1},
+				{
+					Type: token.TypeNumber, Lexeme: `2`, Literal: loxtype.Number(2),
+					Line: 4, Column: 17, Offset: 95, Length: 1,
+				},
+				{Type: token.TypeSemicolon, Lexeme: `;`, Line: 4, Column: 18, Offset: 96, Length: 1},
+				{Type: token.TypeRightBrace, Lexeme: `}`, Line: 5, Column: 5, Offset: 102, Length: 1},
+				{Type: token.TypeEOF, Line: 6, Column: 4, Offset: 107},
 			},
 		},
 		{
 			Name:   "success/number_literal",
 			Source: `4.`,
 			Tokens: []*token.Token{
-				{Type: token.TypeNumber, Lexeme: `4`, Literal: loxtype.Number(4), Line: 1},
-				{Type: token.TypeDot, Lexeme: `.`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeNumber, Lexeme: `4`, Literal: loxtype.Number(4), Line: 1, Column: 1, Offset: 0, Length: 1},
+				{Type: token.TypeDot, Lexeme: `.`, Line: 1, Column: 2, Offset: 1, Length: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 3, Offset: 2},
+			},
+		},
+		{
+			Name:   "success/multibyte_runes",
+			Source: `"héllo" x`,
+			Tokens: []*token.Token{
+				{
+					Type: token.TypeString, Lexeme: `"héllo"`, Literal: loxtype.String("héllo"),
+					Line: 1, Column: 1, Offset: 0, Length: 8,
+				},
+				// Columns count runes while offsets count bytes.
+				{Type: token.TypeIdentifier, Lexeme: `x`, Line: 1, Column: 9, Offset: 9, Length: 1},
+				{Type: token.TypeEOF, Line: 1, Column: 10, Offset: 10},
 			},
 		},
 		{
@@ -145,12 +175,17 @@ func TestScanner_ScanTokens(t *testing.T) {
 			Source: `'`,
 			Err:    scanner.ErrUnexpectedRune,
 		},
+		{
+			Name:   "error/number_out_of_range",
+			Source: strings.Repeat("9", 400),
+			Err:    loxtype.ErrInvalidNumber,
+		},
 		{
 			Name:   "success/nil_literal",
 			Source: `nil`,
 			Tokens: []*token.Token{
-				{Type: token.TypeNil, Lexeme: `nil`, Line: 1},
-				{Type: token.TypeEOF, Line: 1},
+				{Type: token.TypeNil, Lexeme: `nil`, Line: 1, Column: 1, Offset: 0, Length: 3},
+				{Type: token.TypeEOF, Line: 1, Column: 4, Offset: 3},
 			},
 		},
 	}
@@ -169,3 +204,62 @@ func TestScanner_ScanTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestScanner_ScanTokens_reportsEveryError(t *testing.T) {
+	t.Parallel()
+
+	source := "var a = 'b;\nvar c = @;\nprint \"unterminated;"
+	tokens, err := scanner.New(source).ScanTokens()
+
+	var list ierrors.List
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 3)
+	assert.ErrorIs(t, list[0], scanner.ErrUnexpectedRune)
+	assert.Equal(t, 1, list[0].Line)
+	assert.ErrorIs(t, list[1], scanner.ErrUnexpectedRune)
+	assert.Equal(t, 2, list[1].Line)
+	assert.ErrorIs(t, list[2], scanner.ErrUnterminatedString)
+	assert.Equal(t, 3, list[2].Line)
+
+	// Scanning carries on past the bad runes.
+	require.NotEmpty(t, tokens)
+	assert.Equal(t, token.TypeEOF, tokens[len(tokens)-1].Type)
+	assert.Equal(t, token.TypePrint, tokens[len(tokens)-2].Type)
+}
+
+func TestScanner_ScanTokens_withComments(t *testing.T) {
+	t.Parallel()
+
+	source := "// leading\nprint 1; // trailing\n// last\n// lines\n"
+
+	tokens, err := scanner.New(source).ScanTokens()
+	require.NoError(t, err)
+	for _, tok := range tokens {
+		assert.Nil(t, tok.Comments, "comments are dropped by default")
+	}
+
+	tokens, err = scanner.New(source, scanner.WithComments()).ScanTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 4)
+	assert.Equal(t, []*token.Comment{{Text: "// leading", Line: 1, Offset: 0}}, tokens[0].Comments)
+	assert.Nil(t, tokens[1].Comments)
+	assert.Nil(t, tokens[2].Comments)
+	assert.Equal(t, []*token.Comment{
+		{Text: "// trailing", Line: 2, Offset: 20},
+		{Text: "// last", Line: 3, Offset: 32},
+		{Text: "// lines", Line: 4, Offset: 40},
+	}, tokens[3].Comments)
+	assert.Equal(t, token.TypeEOF, tokens[3].Type)
+}
+
+func TestKeywords(t *testing.T) {
+	t.Parallel()
+
+	keywords := scanner.Keywords()
+	assert.True(t, slices.IsSorted(keywords))
+	for _, keyword := range keywords {
+		tokens, err := scanner.New(keyword).ScanTokens()
+		require.NoError(t, err)
+		assert.NotEqual(t, token.TypeIdentifier, tokens[0].Type, keyword)
+	}
+}
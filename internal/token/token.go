@@ -11,6 +11,25 @@ type Token struct {
 	Lexeme  string
 	Literal loxtype.Type
 	Line    int
+
+	// Column is the 1-based position, counted in runes, of the token's first character within its line.
+	Column int
+	// Offset is the position, counted in bytes, of the token's first character within the source.
+	Offset int
+	// Length is the length of the token's lexeme in bytes.
+	Length int
+
+	// Comments holds the comments between the previous token and this one, if the scanner was asked to keep them.
+	Comments []*Comment
+}
+
+// Comment is a line comment, kept as trivia on the token that follows it.
+type Comment struct {
+	// Text is the comment as written, from its slashes to the end of its line.
+	Text string
+	Line int
+	// Offset is the position, counted in bytes, of the comment's first slash within the source.
+	Offset int
 }
 
 func NewToken(tokenType Type, lexeme string, literal loxtype.Type, line int) *Token {
@@ -9,6 +9,9 @@ const (
 	TypeRightParen
 	TypeLeftBrace
 	TypeRightBrace
+	TypeLeftBracket
+	TypeRightBracket
+	TypeColon
 	TypeComma
 	TypeDot
 	TypeMinus
@@ -34,19 +37,26 @@ const (
 
 	// Keywords.
 	TypeAnd
+	TypeBreak
+	TypeCatch
 	TypeClass
+	TypeContinue
 	TypeElse
 	TypeFalse
+	TypeFinally
 	TypeFun
 	TypeFor
 	TypeIf
+	TypeImport
 	TypeNil
 	TypeOr
 	TypePrint
 	TypeReturn
 	TypeSuper
 	TypeThis
+	TypeThrow
 	TypeTrue
+	TypeTry
 	TypeVar
 	TypeWhile
 
@@ -12,46 +12,56 @@ func _() {
 	_ = x[TypeRightParen-1]
 	_ = x[TypeLeftBrace-2]
 	_ = x[TypeRightBrace-3]
-	_ = x[TypeComma-4]
-	_ = x[TypeDot-5]
-	_ = x[TypeMinus-6]
-	_ = x[TypePlus-7]
-	_ = x[TypeSemicolon-8]
-	_ = x[TypeSlash-9]
-	_ = x[TypeStar-10]
-	_ = x[TypeBang-11]
-	_ = x[TypeBangEqual-12]
-	_ = x[TypeEqual-13]
-	_ = x[TypeEqualEqual-14]
-	_ = x[TypeGreater-15]
-	_ = x[TypeGreaterEqual-16]
-	_ = x[TypeLess-17]
-	_ = x[TypeLessEqual-18]
-	_ = x[TypeIdentifier-19]
-	_ = x[TypeString-20]
-	_ = x[TypeNumber-21]
-	_ = x[TypeAnd-22]
-	_ = x[TypeClass-23]
-	_ = x[TypeElse-24]
-	_ = x[TypeFalse-25]
-	_ = x[TypeFun-26]
-	_ = x[TypeFor-27]
-	_ = x[TypeIf-28]
-	_ = x[TypeNil-29]
-	_ = x[TypeOr-30]
-	_ = x[TypePrint-31]
-	_ = x[TypeReturn-32]
-	_ = x[TypeSuper-33]
-	_ = x[TypeThis-34]
-	_ = x[TypeTrue-35]
-	_ = x[TypeVar-36]
-	_ = x[TypeWhile-37]
-	_ = x[TypeEOF-38]
+	_ = x[TypeLeftBracket-4]
+	_ = x[TypeRightBracket-5]
+	_ = x[TypeColon-6]
+	_ = x[TypeComma-7]
+	_ = x[TypeDot-8]
+	_ = x[TypeMinus-9]
+	_ = x[TypePlus-10]
+	_ = x[TypeSemicolon-11]
+	_ = x[TypeSlash-12]
+	_ = x[TypeStar-13]
+	_ = x[TypeBang-14]
+	_ = x[TypeBangEqual-15]
+	_ = x[TypeEqual-16]
+	_ = x[TypeEqualEqual-17]
+	_ = x[TypeGreater-18]
+	_ = x[TypeGreaterEqual-19]
+	_ = x[TypeLess-20]
+	_ = x[TypeLessEqual-21]
+	_ = x[TypeIdentifier-22]
+	_ = x[TypeString-23]
+	_ = x[TypeNumber-24]
+	_ = x[TypeAnd-25]
+	_ = x[TypeBreak-26]
+	_ = x[TypeCatch-27]
+	_ = x[TypeClass-28]
+	_ = x[TypeContinue-29]
+	_ = x[TypeElse-30]
+	_ = x[TypeFalse-31]
+	_ = x[TypeFinally-32]
+	_ = x[TypeFun-33]
+	_ = x[TypeFor-34]
+	_ = x[TypeIf-35]
+	_ = x[TypeImport-36]
+	_ = x[TypeNil-37]
+	_ = x[TypeOr-38]
+	_ = x[TypePrint-39]
+	_ = x[TypeReturn-40]
+	_ = x[TypeSuper-41]
+	_ = x[TypeThis-42]
+	_ = x[TypeThrow-43]
+	_ = x[TypeTrue-44]
+	_ = x[TypeTry-45]
+	_ = x[TypeVar-46]
+	_ = x[TypeWhile-47]
+	_ = x[TypeEOF-48]
 }
 
-const _Type_name = "TypeLeftParenTypeRightParenTypeLeftBraceTypeRightBraceTypeCommaTypeDotTypeMinusTypePlusTypeSemicolonTypeSlashTypeStarTypeBangTypeBangEqualTypeEqualTypeEqualEqualTypeGreaterTypeGreaterEqualTypeLessTypeLessEqualTypeIdentifierTypeStringTypeNumberTypeAndTypeClassTypeElseTypeFalseTypeFunTypeForTypeIfTypeNilTypeOrTypePrintTypeReturnTypeSuperTypeThisTypeTrueTypeVarTypeWhileTypeEOF"
+const _Type_name = "TypeLeftParenTypeRightParenTypeLeftBraceTypeRightBraceTypeLeftBracketTypeRightBracketTypeColonTypeCommaTypeDotTypeMinusTypePlusTypeSemicolonTypeSlashTypeStarTypeBangTypeBangEqualTypeEqualTypeEqualEqualTypeGreaterTypeGreaterEqualTypeLessTypeLessEqualTypeIdentifierTypeStringTypeNumberTypeAndTypeBreakTypeCatchTypeClassTypeContinueTypeElseTypeFalseTypeFinallyTypeFunTypeForTypeIfTypeImportTypeNilTypeOrTypePrintTypeReturnTypeSuperTypeThisTypeThrowTypeTrueTypeTryTypeVarTypeWhileTypeEOF"
 
-var _Type_index = [...]uint16{0, 13, 27, 40, 54, 63, 70, 79, 87, 100, 109, 117, 125, 138, 147, 161, 172, 188, 196, 209, 223, 233, 243, 250, 259, 267, 276, 283, 290, 296, 303, 309, 318, 328, 337, 345, 353, 360, 369, 376}
+var _Type_index = [...]uint16{0, 13, 27, 40, 54, 69, 85, 94, 103, 110, 119, 127, 140, 149, 157, 165, 178, 187, 201, 212, 228, 236, 249, 263, 273, 283, 290, 299, 308, 317, 329, 337, 346, 357, 364, 371, 377, 387, 394, 400, 409, 419, 428, 436, 445, 453, 460, 467, 476, 483}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,156 @@
+package vm
+
+import (
+	"fmt"
+	"slices"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+)
+
+// callValue calls the callee beneath argc arguments on the stack.
+func (vm *VM) callValue(callee loxtype.Type, argc int) error {
+	switch callee := callee.(type) {
+	case *boundMethod:
+		vm.stack[vm.sp-argc-1] = callee.receiver
+		return vm.call(callee.method, argc)
+
+	case *loxClass:
+		vm.stack[vm.sp-argc-1] = newInstance(callee)
+		if initializer, ok := callee.methods["init"]; ok {
+			return vm.call(initializer, argc)
+		}
+		if argc != 0 {
+			return vm.runtimeError(fmt.Errorf("%w: expected 0 but got %d", ierrors.ErrArgumentCount, argc))
+		}
+		return nil
+
+	case *loxClosure:
+		return vm.call(callee, argc)
+
+	case *native.Function:
+		if err := callee.CheckArity(argc); err != nil {
+			return vm.runtimeError(err)
+		}
+		result, err := callee.Call(slices.Clone(vm.stack[vm.sp-argc : vm.sp]))
+		if err != nil {
+			return vm.runtimeError(err, ierrors.Frame{Function: callee.String(), Native: true})
+		}
+		if s, ok := result.(loxtype.String); ok {
+			if err := vm.meter.Allocate(len(s)); err != nil {
+				return vm.runtimeError(err)
+			}
+		}
+		vm.sp -= argc + 1
+		if result == nil {
+			result = loxtype.Nil{}
+		}
+		vm.push(result)
+		return nil
+	}
+
+	return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrNotCallable, callee))
+}
+
+// call pushes a frame to run the closure, whose arguments are already on the stack.
+func (vm *VM) call(closure *loxClosure, argc int) error {
+	if argc != closure.function.Arity {
+		return vm.runtimeError(
+			fmt.Errorf("%w: expected %d but got %d", ierrors.ErrArgumentCount, closure.function.Arity, argc))
+	}
+	if err := vm.meter.Interrupted(); err != nil {
+		return vm.runtimeError(err)
+	}
+	// The script's frame isn't a call.
+	if len(vm.frames) > vm.limits.MaxCallDepth() {
+		return vm.runtimeError(ierrors.ErrStackOverflow)
+	}
+
+	vm.frames = append(vm.frames, callFrame{
+		closure: closure,
+		base:    vm.sp - argc - 1,
+	})
+	return nil
+}
+
+// invoke calls the named method of the receiver beneath argc arguments on the stack.
+func (vm *VM) invoke(name string, argc int) error {
+	if ns, ok := vm.peek(argc).(*namespace); ok {
+		value, err := ns.get(name)
+		if err != nil {
+			return vm.runtimeError(err)
+		}
+		vm.stack[vm.sp-argc-1] = value
+		return vm.callValue(value, argc)
+	}
+	if caught, ok := vm.peek(argc).(*loxtype.Error); ok {
+		value, ok := caught.Get(name)
+		if !ok {
+			return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name))
+		}
+		vm.stack[vm.sp-argc-1] = value
+		return vm.callValue(value, argc)
+	}
+
+	instance, ok := vm.peek(argc).(*loxInstance)
+	if !ok {
+		return vm.runtimeError(ierrors.ErrNotAnInstance)
+	}
+
+	// Fields shadow methods, and may well hold something callable.
+	if value, ok := instance.fields[name]; ok {
+		vm.stack[vm.sp-argc-1] = value
+		return vm.callValue(value, argc)
+	}
+	return vm.invokeFromClass(instance.class, name, argc)
+}
+
+func (vm *VM) invokeFromClass(class *loxClass, name string, argc int) error {
+	method, ok := class.methods[name]
+	if !ok {
+		return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name))
+	}
+	return vm.call(method, argc)
+}
+
+// bindMethod replaces the instance on top of the stack with its named method from the class.
+func (vm *VM) bindMethod(class *loxClass, name string) error {
+	method, ok := class.methods[name]
+	if !ok {
+		return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name))
+	}
+	bound := &boundMethod{receiver: vm.peek(0), method: method}
+	vm.pop()
+	vm.push(bound)
+	return nil
+}
+
+// captureUpvalue returns the open upvalue for the stack slot, creating it if no closure has captured the slot yet.
+func (vm *VM) captureUpvalue(slot int) *upvalue {
+	var prev *upvalue
+	uv := vm.openUpvalues
+	for uv != nil && uv.slot > slot {
+		prev = uv
+		uv = uv.next
+	}
+	if uv != nil && uv.slot == slot {
+		return uv
+	}
+
+	created := &upvalue{location: &vm.stack[slot], slot: slot, next: uv}
+	if prev == nil {
+		vm.openUpvalues = created
+	} else {
+		prev.next = created
+	}
+	return created
+}
+
+// closeUpvalues closes every open upvalue at or above the stack slot.
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.slot >= last {
+		vm.openUpvalues.close()
+		vm.openUpvalues = vm.openUpvalues.next
+	}
+}
@@ -0,0 +1,162 @@
+package vm
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+)
+
+// moduleFrame names the top-level code of imported modules in tracebacks.
+const moduleFrame = "<module>"
+
+// module is a file of code the machine has run, whose top-level bindings are its globals.
+// Code looks globals up in its own module first, then among the built-ins every module shares.
+type module struct {
+	// name is the path of the file as errors report it, and path is its absolute path, which identifies it.
+	name    string
+	path    string
+	source  string
+	globals map[string]loxtype.Type
+	// loaded is false while the module's top-level code is still running.
+	loaded bool
+}
+
+func newModule(name, path, source string) *module {
+	return &module{name: name, path: path, source: source, globals: map[string]loxtype.Type{}}
+}
+
+// namespace is the value an import binds, whose properties are the top-level bindings of a module.
+type namespace struct {
+	module *module
+}
+
+var _ loxtype.Type = (*namespace)(nil)
+
+func (n *namespace) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*namespace)
+	return loxtype.Boolean(ok && o.module == n.module)
+}
+
+func (*namespace) IsTruthy() loxtype.Boolean { return true }
+func (n *namespace) String() string          { return "<module " + n.module.name + ">" }
+
+func (n *namespace) get(name string) (loxtype.Type, error) {
+	if value, ok := n.module.globals[name]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name)
+}
+
+// global looks up a global of the module, or a built-in if the module doesn't define one by that name.
+func (vm *VM) global(m *module, name string) (loxtype.Type, bool) {
+	if value, ok := m.globals[name]; ok {
+		return value, true
+	}
+	value, ok := vm.builtins[name]
+	return value, ok
+}
+
+// setGlobal assigns to a global of the module, or else to a built-in, returning false if neither is defined.
+func (vm *VM) setGlobal(m *module, name string, value loxtype.Type) bool {
+	if _, ok := m.globals[name]; ok {
+		m.globals[name] = value
+		return true
+	}
+	if _, ok := vm.builtins[name]; ok {
+		vm.builtins[name] = value
+		return true
+	}
+	return false
+}
+
+// importModule pushes the namespace of the module that an import in importer names. If the module hasn't been
+// imported yet, it pushes a frame to run the module's top-level code instead, which leaves the namespace when it
+// returns.
+func (vm *VM) importModule(importer *module, target string) error {
+	if vm.capabilities&native.CapabilityFS == 0 {
+		return vm.runtimeError(ierrors.ErrImportDenied)
+	}
+	name, path, err := vm.findModule(importer, target)
+	if err != nil {
+		return vm.runtimeError(err)
+	}
+
+	if m, ok := vm.modules[path]; ok {
+		if !m.loaded {
+			return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrImportCycle, vm.importChain(m)))
+		}
+		vm.push(&namespace{module: m})
+		return nil
+	}
+
+	source, err := fs.ReadFile(vm.host.FS, name)
+	if err != nil {
+		return vm.runtimeError(err)
+	}
+	m := newModule(name, path, string(source))
+	fn, err := compile(m.source)
+	if err != nil {
+		return ierrors.WithSource(err, m.name, m.source)
+	}
+
+	closure := newClosure(fn, m)
+	vm.push(closure)
+	if err = vm.call(closure, 0); err != nil {
+		return err
+	}
+	vm.frames[len(vm.frames)-1].loading = m
+	vm.modules[path] = m
+	return nil
+}
+
+// findModule looks for the file an import names relative to the directory of the importing file,
+// then relative to each directory of the search path in turn, in the host's file system.
+func (vm *VM) findModule(importer *module, target string) (string, string, error) {
+	dirs := []string{""}
+	if !filepath.IsAbs(target) {
+		dirs = append([]string{filepath.Dir(importer.name)}, vm.searchPath...)
+	}
+
+	for _, dir := range dirs {
+		name := filepath.Join(dir, target)
+		if info, err := fs.Stat(vm.host.FS, name); err != nil || info.IsDir() {
+			continue
+		}
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return "", "", err
+		}
+		return name, path, nil
+	}
+	return "", "", fmt.Errorf("%w: %s", ierrors.ErrModuleNotFound, target)
+}
+
+// importChain describes the imports that lead from m back around to it.
+func (vm *VM) importChain(m *module) string {
+	var names []string
+	for k := range vm.frames {
+		switch loading := vm.frames[k].loading; loading {
+		case nil:
+		case m:
+			names = names[:0]
+		default:
+			names = append(names, loading.name)
+		}
+	}
+	return strings.Join(append(append([]string{m.name}, names...), m.name), " -> ")
+}
+
+// forgetLoading forgets the modules whose top-level code is running in the frames from the one at index first up,
+// which are being unwound, so that importing them again runs them afresh.
+func (vm *VM) forgetLoading(first int) {
+	for k := first; k < len(vm.frames); k++ {
+		if m := vm.frames[k].loading; m != nil {
+			delete(vm.modules, m.path)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// loxClosure is a function along with the variables it captured from the functions enclosing it
+// and the module whose globals it sees.
+type loxClosure struct {
+	function *bytecode.Function
+	upvalues []*upvalue
+	module   *module
+}
+
+var _ loxtype.Type = (*loxClosure)(nil)
+
+func newClosure(function *bytecode.Function, m *module) *loxClosure {
+	return &loxClosure{
+		function: function,
+		upvalues: make([]*upvalue, function.UpvalueCount),
+		module:   m,
+	}
+}
+
+func (c *loxClosure) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxClosure)
+	return loxtype.Boolean(ok && o == c)
+}
+
+func (*loxClosure) IsTruthy() loxtype.Boolean { return true }
+func (c *loxClosure) String() string          { return c.function.String() }
+
+// upvalue is a variable captured by a closure.
+// While the variable is still on the stack the upvalue points at its slot,
+// and once the variable goes out of scope the upvalue holds onto its value.
+type upvalue struct {
+	location *loxtype.Type
+	closed   loxtype.Type
+	// slot is the index on the stack of an open upvalue.
+	slot int
+	// next links the open upvalues, ordered from the top of the stack down.
+	next *upvalue
+}
+
+func (u *upvalue) close() {
+	u.closed = *u.location
+	u.location = &u.closed
+}
+
+type loxClass struct {
+	name string
+	// methods holds the methods of the class along with those it inherits,
+	// which are copied down from the superclass when the class is declared.
+	methods map[string]*loxClosure
+}
+
+var _ loxtype.Type = (*loxClass)(nil)
+
+func newClass(name string) *loxClass {
+	return &loxClass{
+		name:    name,
+		methods: map[string]*loxClosure{},
+	}
+}
+
+func (c *loxClass) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxClass)
+	return loxtype.Boolean(ok && o == c)
+}
+
+func (*loxClass) IsTruthy() loxtype.Boolean { return true }
+func (c *loxClass) String() string          { return c.name }
+
+type loxInstance struct {
+	class  *loxClass
+	fields map[string]loxtype.Type
+}
+
+var _ loxtype.Type = (*loxInstance)(nil)
+
+func newInstance(class *loxClass) *loxInstance {
+	return &loxInstance{
+		class:  class,
+		fields: map[string]loxtype.Type{},
+	}
+}
+
+func (inst *loxInstance) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*loxInstance)
+	return loxtype.Boolean(ok && o == inst)
+}
+
+func (*loxInstance) IsTruthy() loxtype.Boolean { return true }
+func (inst *loxInstance) String() string       { return inst.class.name + " instance" }
+
+// boundMethod is a method accessed through an instance, which it remembers as "this".
+type boundMethod struct {
+	receiver loxtype.Type
+	method   *loxClosure
+}
+
+var _ loxtype.Type = (*boundMethod)(nil)
+
+func (b *boundMethod) Equals(other loxtype.Type) loxtype.Boolean {
+	o, ok := other.(*boundMethod)
+	return loxtype.Boolean(ok && o == b)
+}
+
+func (*boundMethod) IsTruthy() loxtype.Boolean { return true }
+func (b *boundMethod) String() string          { return b.method.String() }
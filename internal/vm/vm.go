@@ -0,0 +1,736 @@
+// Package vm runs compiled bytecode on a stack-based virtual machine.
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/matt-hoiland/glox/internal/bytecode"
+	"github.com/matt-hoiland/glox/internal/compiler"
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/limits"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/parser"
+	"github.com/matt-hoiland/glox/internal/scanner"
+)
+
+const (
+	// framesMax is the most frames there can ever be: the script's, and one for each call in progress,
+	// however high the host sets the limit.
+	framesMax = limits.DefaultCallDepth + 1
+	// stackSize leaves room for every frame to fill all of its local slots. Temporaries can need more, and the stack
+	// grows to fit them.
+	stackSize = framesMax * 256
+)
+
+// scriptFrame names the top-level code in tracebacks.
+const scriptFrame = "<script>"
+
+type callFrame struct {
+	closure *loxClosure
+	ip      int
+	// base is the index on the stack of the frame's first slot, which holds the callee or receiver.
+	base int
+	// loading is the module whose top-level code the frame runs, if it runs a module being imported.
+	loading *module
+}
+
+// position returns the position of the instruction most recently read in the frame.
+func (f *callFrame) position() bytecode.Position {
+	if f.ip == 0 {
+		return bytecode.Position{}
+	}
+	return f.closure.function.Chunk.Positions[f.ip-1]
+}
+
+func (f *callFrame) readByte() byte {
+	b := f.closure.function.Chunk.Code[f.ip]
+	f.ip++
+	return b
+}
+
+func (f *callFrame) readUint16() uint16 {
+	code := f.closure.function.Chunk.Code
+	f.ip += 2
+	return uint16(code[f.ip-2])<<8 | uint16(code[f.ip-1])
+}
+
+func (f *callFrame) readConstant() loxtype.Type {
+	return f.closure.function.Chunk.Constants[f.readUint16()]
+}
+
+func (f *callFrame) readString() string {
+	return string(f.readConstant().(loxtype.String)) //nolint:forcetypeassert // The compiler only names with strings.
+}
+
+// handler is where a thrown error resumes execution, installed by OpTry.
+type handler struct {
+	// frame is the index of the call that installed the handler.
+	frame int
+	ip    int
+	sp    int
+}
+
+type VM struct {
+	w io.Writer
+	// builtins holds the built-in functions and values, which every module shares.
+	builtins map[string]loxtype.Type
+	// script is the module of the code given to the machine to run, and modules holds every module that has been
+	// imported, or is being imported, by its absolute path.
+	script     *module
+	modules    map[string]*module
+	searchPath []string
+	// trace, if set, receives the stack and the instruction about to run before every instruction.
+	trace io.Writer
+
+	// Open upvalues point into the stack, so they move with it whenever it grows.
+	stack        []loxtype.Type
+	sp           int
+	frames       []callFrame
+	handlers     []handler
+	openUpvalues *upvalue
+
+	capabilities native.Capability
+	host         native.Host
+	limits       limits.Limits
+	// meter tracks the current run against the limits.
+	meter *limits.Meter
+}
+
+type Option func(*VM)
+
+// WithScriptPath names the file that holds the code given to the machine to run,
+// which modules it imports are found relative to. Without it, they're found relative to the working directory.
+func WithScriptPath(path string) Option {
+	return func(vm *VM) {
+		vm.script.name = path
+	}
+}
+
+// WithSearchPath adds directories to look for imported modules in
+// when they aren't found relative to the file that imports them.
+func WithSearchPath(dirs ...string) Option {
+	return func(vm *VM) {
+		vm.searchPath = append(vm.searchPath, dirs...)
+	}
+}
+
+// WithTraceExecution writes the stack and the instruction about to run to w before running every instruction.
+func WithTraceExecution(w io.Writer) Option {
+	return func(vm *VM) {
+		vm.trace = w
+	}
+}
+
+// WithCapabilities grants programs the built-in functions of the capabilities given, beyond those that only work
+// on values. Without it, programs can't reach the host at all.
+func WithCapabilities(granted native.Capability) Option {
+	return func(vm *VM) {
+		vm.capabilities |= granted
+	}
+}
+
+// WithHost sets what the built-in functions of the I/O and file system capabilities and imports reach the host
+// through, such as a file system in memory for tests. The output defaults to the writer that print statements write to.
+func WithHost(host native.Host) Option {
+	return func(vm *VM) {
+		vm.host = host
+	}
+}
+
+// WithMaxSteps stops programs with [ierrors.ErrStepLimit] once they've run n instructions.
+func WithMaxSteps(n int) Option {
+	return func(vm *VM) {
+		vm.limits.Steps = n
+	}
+}
+
+// WithMaxCallDepth stops programs with [ierrors.ErrStackOverflow] when they'd have more than n calls in progress.
+// The virtual machine never allows more than [limits.DefaultCallDepth].
+func WithMaxCallDepth(n int) Option {
+	return func(vm *VM) {
+		vm.limits.CallDepth = min(n, limits.DefaultCallDepth)
+	}
+}
+
+// WithMaxHeap stops programs with [ierrors.ErrHeapLimit] once the Go heap holds more than n bytes.
+func WithMaxHeap(n uint64) Option {
+	return func(vm *VM) {
+		vm.limits.Heap = n
+	}
+}
+
+func New(w io.Writer, opts ...Option) *VM {
+	vm := &VM{
+		w:        w,
+		builtins: map[string]loxtype.Type{},
+		script:   newModule("", "", ""),
+		modules:  map[string]*module{},
+		stack:    make([]loxtype.Type, stackSize),
+		frames:   make([]callFrame, 0, framesMax),
+	}
+
+	for _, opt := range opts {
+		opt(vm)
+	}
+
+	if vm.host.Stdout == nil {
+		vm.host.Stdout = w
+	}
+	if vm.host.FS == nil {
+		vm.host.FS = native.OSFS{}
+	}
+	for _, fn := range native.Functions(vm.capabilities, vm.host) {
+		vm.builtins[fn.Name] = fn
+	}
+	for _, c := range native.Constants() {
+		vm.builtins[c.Name] = c.Value
+	}
+
+	return vm
+}
+
+// Run compiles and runs a script. Globals defined by the script remain defined for the next.
+func (vm *VM) Run(code string) error {
+	return vm.RunContext(context.Background(), code)
+}
+
+// RunContext runs a script, stopping it with the context's error if the context is cancelled before it finishes.
+func (vm *VM) RunContext(ctx context.Context, code string) error {
+	fn, err := compile(code)
+	if err != nil {
+		return err
+	}
+	return vm.InterpretContext(ctx, fn)
+}
+
+func compile(code string) (*bytecode.Function, error) {
+	// Parse even if scanning failed so that syntax errors are reported alongside the scanning errors.
+	tokens, scanErr := scanner.New(code).ScanTokens()
+	stmts, parseErr := parser.New(tokens).Parse()
+	if err := errors.Join(scanErr, parseErr); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(stmts)
+}
+
+// Interpret runs a compiled script.
+func (vm *VM) Interpret(fn *bytecode.Function) error {
+	return vm.InterpretContext(context.Background(), fn)
+}
+
+// InterpretContext runs a compiled script, stopping it with the context's error if the context is cancelled.
+// Scripts that would crash the machine, which only corrupt compiled programs can, fail with [bytecode.ErrMalformed].
+func (vm *VM) InterpretContext(ctx context.Context, fn *bytecode.Function) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// The script is a module too, so that importing it is a cycle.
+	if vm.script.name != "" {
+		path, err := filepath.Abs(vm.script.name)
+		if err != nil {
+			return err
+		}
+		vm.script.path = path
+		vm.modules[path] = vm.script
+	}
+	vm.script.loaded = false
+	defer func() { vm.script.loaded = true }()
+
+	vm.meter = limits.NewMeter(ctx, vm.limits)
+	closure := newClosure(fn, vm.script)
+	vm.push(closure)
+	if err := vm.call(closure, 0); err != nil {
+		vm.reset()
+		return err
+	}
+	return vm.guard(vm.run)
+}
+
+// guard runs f, turning a panic into an error. [bytecode.Decode] checks how code uses the stack, but not the types
+// of the values it finds there, which code that isn't the compiler's can get wrong.
+func (vm *VM) guard(f func() error) error {
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				vm.sp = min(max(vm.sp, 0), len(vm.stack))
+				vm.reset()
+				err = fmt.Errorf("%w: %v", bytecode.ErrMalformed, r)
+			}
+		}()
+		err = f()
+	}()
+	return err
+}
+
+func (vm *VM) reset() {
+	vm.forgetLoading(0)
+	clear(vm.stack[:vm.sp])
+	vm.sp = 0
+	vm.frames = vm.frames[:0]
+	vm.handlers = vm.handlers[:0]
+	vm.openUpvalues = nil
+}
+
+func (vm *VM) push(value loxtype.Type) {
+	if vm.sp == len(vm.stack) {
+		vm.grow()
+	}
+	vm.stack[vm.sp] = value
+	vm.sp++
+}
+
+// grow doubles the room on the stack.
+func (vm *VM) grow() {
+	vm.stack = append(vm.stack, make([]loxtype.Type, len(vm.stack))...)
+	for uv := vm.openUpvalues; uv != nil; uv = uv.next {
+		uv.location = &vm.stack[uv.slot]
+	}
+}
+
+func (vm *VM) pop() loxtype.Type {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+func (vm *VM) peek(distance int) loxtype.Type {
+	return vm.stack[vm.sp-1-distance]
+}
+
+// runtimeError places err at the instruction being run.
+// When calls are in progress, err is annotated with them, innermost first after any frames given.
+func (vm *VM) runtimeError(err error, inner ...ierrors.Frame) error {
+	pos := vm.frames[len(vm.frames)-1].position()
+	e := &ierrors.Error{
+		Line:   pos.Line,
+		Err:    err,
+		Column: pos.Column,
+		Offset: pos.Offset,
+		Length: pos.Length,
+	}
+	if pos.Lexeme != "" {
+		e.Where = " at '" + pos.Lexeme + "'"
+	}
+	if len(vm.frames) == 1 && len(inner) == 0 {
+		return e
+	}
+
+	// Positions within other modules refer to their source.
+	located := error(e)
+	if m := vm.frames[len(vm.frames)-1].closure.module; m != vm.script {
+		located = ierrors.WithSource(e, m.name, m.source)
+	}
+
+	frames := inner
+	for k := len(vm.frames) - 1; k >= 0; k-- {
+		f := &vm.frames[k]
+		frame := ierrors.Frame{Function: f.closure.function.Name, Line: f.position().Line}
+		switch {
+		case frame.Function != "":
+		case f.loading != nil:
+			frame.Function = moduleFrame
+		default:
+			frame.Function = scriptFrame
+		}
+		if f.closure.module != vm.script {
+			frame.File = f.closure.module.name
+		}
+		frames = append(frames, frame)
+	}
+	return &ierrors.Traceback{Err: located, Frames: frames}
+}
+
+// run runs until the script returns, resuming at the innermost handler whenever the program fails with an error it
+// can catch. Errors that go uncaught unwind the whole stack.
+func (vm *VM) run() error {
+	for {
+		err := vm.execute()
+		if err == nil {
+			return nil
+		}
+		if !vm.catch(err) {
+			vm.reset()
+			return err
+		}
+	}
+}
+
+// catch unwinds the stack to the innermost handler and pushes the error object for err, if it can be caught.
+func (vm *VM) catch(err error) bool {
+	if len(vm.handlers) == 0 {
+		return false
+	}
+	caught, ok := ierrors.Catch(err)
+	if !ok {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.closeUpvalues(h.sp)
+	clear(vm.stack[h.sp:vm.sp])
+	vm.sp = h.sp
+	vm.forgetLoading(h.frame + 1)
+	vm.frames = vm.frames[:h.frame+1]
+	vm.frames[h.frame].ip = h.ip
+	vm.push(caught)
+	return true
+}
+
+// execute runs instructions until the script returns or the program fails.
+//
+//nolint:gocyclo,cyclop,funlen,maintidx // The dispatch loop is one big switch.
+func (vm *VM) execute() error {
+	frame := &vm.frames[len(vm.frames)-1]
+
+	for {
+		if vm.trace != nil {
+			vm.traceInstruction(frame)
+		}
+		if err := vm.meter.Step(); err != nil {
+			return vm.runtimeError(err)
+		}
+
+		switch op := bytecode.OpCode(frame.readByte()); op {
+		case bytecode.OpConstant:
+			vm.push(frame.readConstant())
+		case bytecode.OpNil:
+			vm.push(loxtype.Nil{})
+		case bytecode.OpTrue:
+			vm.push(loxtype.Boolean(true))
+		case bytecode.OpFalse:
+			vm.push(loxtype.Boolean(false))
+		case bytecode.OpPop:
+			vm.pop()
+
+		case bytecode.OpGetLocal:
+			vm.push(vm.stack[frame.base+int(frame.readByte())])
+		case bytecode.OpSetLocal:
+			vm.stack[frame.base+int(frame.readByte())] = vm.peek(0)
+		case bytecode.OpGetGlobal:
+			name := frame.readString()
+			value, ok := vm.global(frame.closure.module, name)
+			if !ok {
+				return vm.runtimeError(fmt.Errorf("%w: %s", environment.ErrUndefinedVariable, name))
+			}
+			vm.push(value)
+		case bytecode.OpDefineGlobal:
+			frame.closure.module.globals[frame.readString()] = vm.pop()
+		case bytecode.OpSetGlobal:
+			name := frame.readString()
+			if !vm.setGlobal(frame.closure.module, name, vm.peek(0)) {
+				return vm.runtimeError(fmt.Errorf("%w: %s", environment.ErrUndefinedVariable, name))
+			}
+		case bytecode.OpGetUpvalue:
+			vm.push(*frame.closure.upvalues[frame.readByte()].location)
+		case bytecode.OpSetUpvalue:
+			*frame.closure.upvalues[frame.readByte()].location = vm.peek(0)
+
+		case bytecode.OpGetProperty:
+			if ns, ok := vm.peek(0).(*namespace); ok {
+				value, err := ns.get(frame.readString())
+				if err != nil {
+					return vm.runtimeError(err)
+				}
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			if caught, ok := vm.peek(0).(*loxtype.Error); ok {
+				name := frame.readString()
+				value, ok := caught.Get(name)
+				if !ok {
+					return vm.runtimeError(fmt.Errorf("%w: %s", ierrors.ErrUndefinedProperty, name))
+				}
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			instance, ok := vm.peek(0).(*loxInstance)
+			if !ok {
+				return vm.runtimeError(ierrors.ErrNotAnInstance)
+			}
+			name := frame.readString()
+			if value, ok := instance.fields[name]; ok {
+				vm.pop()
+				vm.push(value)
+				break
+			}
+			if err := vm.bindMethod(instance.class, name); err != nil {
+				return err
+			}
+		case bytecode.OpSetProperty:
+			if _, ok := vm.peek(1).(*namespace); ok {
+				return vm.runtimeError(ierrors.ErrModuleReadOnly)
+			}
+			instance, ok := vm.peek(1).(*loxInstance)
+			if !ok {
+				return vm.runtimeError(ierrors.ErrNotAnInstance)
+			}
+			instance.fields[frame.readString()] = vm.peek(0)
+			value := vm.pop()
+			vm.pop()
+			vm.push(value)
+		case bytecode.OpGetSuper:
+			name := frame.readString()
+			superclass := vm.pop().(*loxClass) //nolint:forcetypeassert // OpInherit has already checked it.
+			if err := vm.bindMethod(superclass, name); err != nil {
+				return err
+			}
+
+		case bytecode.OpList:
+			count := int(frame.readUint16())
+			elements := make([]loxtype.Type, count)
+			copy(elements, vm.stack[vm.sp-count:vm.sp])
+			clear(vm.stack[vm.sp-count : vm.sp])
+			vm.sp -= count
+			vm.push(&loxtype.List{Elements: elements})
+		case bytecode.OpMap:
+			count := int(frame.readUint16())
+			m := loxtype.NewMap()
+			for k := vm.sp - 2*count; k < vm.sp; k += 2 {
+				if err := native.SetIndex(m, vm.stack[k], vm.stack[k+1]); err != nil {
+					return vm.runtimeError(err)
+				}
+			}
+			clear(vm.stack[vm.sp-2*count : vm.sp])
+			vm.sp -= 2 * count
+			vm.push(m)
+		case bytecode.OpGetIndex:
+			value, err := native.GetIndex(vm.peek(1), vm.peek(0))
+			if err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.pop()
+			vm.pop()
+			vm.push(value)
+		case bytecode.OpSetIndex:
+			if err := native.SetIndex(vm.peek(2), vm.peek(1), vm.peek(0)); err != nil {
+				return vm.runtimeError(err)
+			}
+			value := vm.pop()
+			vm.pop()
+			vm.pop()
+			vm.push(value)
+
+		case bytecode.OpEqual:
+			b, a := vm.pop(), vm.pop()
+			vm.push(a.Equals(b))
+		case bytecode.OpGreater:
+			if err := vm.compare("greater", loxtype.Number.Greater); err != nil {
+				return err
+			}
+		case bytecode.OpGreaterEqual:
+			if err := vm.compare("greater-equal", loxtype.Number.GreaterEqual); err != nil {
+				return err
+			}
+		case bytecode.OpLess:
+			if err := vm.compare("less", loxtype.Number.Less); err != nil {
+				return err
+			}
+		case bytecode.OpLessEqual:
+			if err := vm.compare("less-equal", loxtype.Number.LessEqual); err != nil {
+				return err
+			}
+		case bytecode.OpAdd:
+			if err := vm.add(); err != nil {
+				return err
+			}
+		case bytecode.OpSubtract:
+			if err := vm.arithmetic("minus", loxtype.Number.Subtract); err != nil {
+				return err
+			}
+		case bytecode.OpMultiply:
+			if err := vm.arithmetic("star", loxtype.Number.Multiply); err != nil {
+				return err
+			}
+		case bytecode.OpDivide:
+			if err := vm.arithmetic("slash", loxtype.Number.Divide); err != nil {
+				return err
+			}
+		case bytecode.OpNot:
+			vm.push(!vm.pop().IsTruthy())
+		case bytecode.OpNegate:
+			n, ok := vm.peek(0).(loxtype.Number)
+			if !ok {
+				return vm.runtimeError(fmt.Errorf("cannot apply minus operator: %w", ierrors.ErrNonNumericType))
+			}
+			vm.pop()
+			vm.push(n.Negate())
+
+		case bytecode.OpPrint:
+			fmt.Fprintln(vm.w, vm.pop())
+
+		case bytecode.OpJump:
+			offset := frame.readUint16()
+			frame.ip += int(offset)
+		case bytecode.OpJumpIfFalse:
+			offset := frame.readUint16()
+			if !vm.peek(0).IsTruthy() {
+				frame.ip += int(offset)
+			}
+		case bytecode.OpLoop:
+			offset := frame.readUint16()
+			frame.ip -= int(offset)
+			if err := vm.meter.Interrupted(); err != nil {
+				return vm.runtimeError(err)
+			}
+
+		case bytecode.OpTry:
+			offset := frame.readUint16()
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, ip: frame.ip + int(offset), sp: vm.sp})
+		case bytecode.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case bytecode.OpThrow:
+			thrown := ierrors.Thrown(vm.pop(), frame.position().Line)
+			if thrown.Origin != nil {
+				return thrown.Origin
+			}
+			return vm.runtimeError(thrown)
+
+		case bytecode.OpCall:
+			argc := int(frame.readByte())
+			if err := vm.callValue(vm.peek(argc), argc); err != nil {
+				return err
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+		case bytecode.OpInvoke:
+			name := frame.readString()
+			argc := int(frame.readByte())
+			if err := vm.invoke(name, argc); err != nil {
+				return err
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+		case bytecode.OpSuperInvoke:
+			name := frame.readString()
+			argc := int(frame.readByte())
+			superclass := vm.pop().(*loxClass) //nolint:forcetypeassert // OpInherit has already checked it.
+			if err := vm.invokeFromClass(superclass, name, argc); err != nil {
+				return err
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+		case bytecode.OpClosure:
+			fn := frame.readConstant().(*bytecode.Function) //nolint:forcetypeassert // The compiler only closes over functions.
+			closure := newClosure(fn, frame.closure.module)
+			vm.push(closure)
+			for i := range closure.upvalues {
+				isLocal := frame.readByte() == 1
+				index := int(frame.readByte())
+				if isLocal {
+					closure.upvalues[i] = vm.captureUpvalue(frame.base + index)
+				} else {
+					closure.upvalues[i] = frame.closure.upvalues[index]
+				}
+			}
+		case bytecode.OpCloseUpvalue:
+			vm.closeUpvalues(vm.sp - 1)
+			vm.pop()
+		case bytecode.OpReturn:
+			result := vm.pop()
+			vm.closeUpvalues(frame.base)
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			// The compiler removes handlers before returning, but compiled files can't be trusted to.
+			for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame >= len(vm.frames) {
+				vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			}
+			if len(vm.frames) == 0 {
+				vm.reset()
+				return nil
+			}
+			if frame.loading != nil {
+				frame.loading.loaded = true
+				result = &namespace{module: frame.loading}
+			}
+			clear(vm.stack[frame.base:vm.sp])
+			vm.sp = frame.base
+			vm.push(result)
+			frame = &vm.frames[len(vm.frames)-1]
+
+		case bytecode.OpClass:
+			vm.push(newClass(frame.readString()))
+		case bytecode.OpInherit:
+			superclass, ok := vm.peek(1).(*loxClass)
+			if !ok {
+				return vm.runtimeError(ierrors.ErrSuperclassNotClass)
+			}
+			subclass := vm.peek(0).(*loxClass) //nolint:forcetypeassert // The compiler only loads the class.
+			for name, method := range superclass.methods {
+				subclass.methods[name] = method
+			}
+			vm.pop()
+		case bytecode.OpMethod:
+			method := vm.peek(0).(*loxClosure) //nolint:forcetypeassert // The compiler only defines closures.
+			class := vm.peek(1).(*loxClass)    //nolint:forcetypeassert // The compiler only loads the class.
+			class.methods[frame.readString()] = method
+			vm.pop()
+
+		case bytecode.OpImport:
+			if err := vm.importModule(frame.closure.module, frame.readString()); err != nil {
+				return err
+			}
+			frame = &vm.frames[len(vm.frames)-1]
+
+		default:
+			return vm.runtimeError(fmt.Errorf("unknown opcode %s", op))
+		}
+	}
+}
+
+func (vm *VM) traceInstruction(frame *callFrame) {
+	fmt.Fprint(vm.trace, "          ")
+	for _, value := range vm.stack[:vm.sp] {
+		fmt.Fprintf(vm.trace, "[ %s ]", value)
+	}
+	fmt.Fprintln(vm.trace)
+	frame.closure.function.Chunk.DisassembleInstruction(vm.trace, frame.ip)
+}
+
+func (vm *VM) compare(name string, op func(a, b loxtype.Number) loxtype.Boolean) error {
+	a, aok := vm.peek(1).(loxtype.Number)
+	b, bok := vm.peek(0).(loxtype.Number)
+	if !aok || !bok {
+		return vm.runtimeError(fmt.Errorf("%s expression: %w", name, ierrors.ErrNonNumericType))
+	}
+	vm.sp -= 2
+	vm.push(op(a, b))
+	return nil
+}
+
+func (vm *VM) arithmetic(name string, op func(a, b loxtype.Number) loxtype.Number) error {
+	a, aok := vm.peek(1).(loxtype.Number)
+	b, bok := vm.peek(0).(loxtype.Number)
+	if !aok || !bok {
+		return vm.runtimeError(fmt.Errorf("%s expression: %w", name, ierrors.ErrNonNumericType))
+	}
+	vm.sp -= 2
+	vm.push(op(a, b))
+	return nil
+}
+
+func (vm *VM) add() error {
+	switch a := vm.peek(1).(type) {
+	case loxtype.Number:
+		if b, ok := vm.peek(0).(loxtype.Number); ok {
+			vm.sp -= 2
+			vm.push(a.Add(b))
+			return nil
+		}
+	case loxtype.String:
+		if b, ok := vm.peek(0).(loxtype.String); ok {
+			if err := vm.meter.Allocate(len(a) + len(b)); err != nil {
+				return vm.runtimeError(err)
+			}
+			vm.sp -= 2
+			vm.push(a.Add(b))
+			return nil
+		}
+	}
+	return vm.runtimeError(fmt.Errorf("operands to plus expression must be either string or numeric: %w",
+		ierrors.ErrType))
+}
@@ -0,0 +1,401 @@
+package vm_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/internal/native"
+	"github.com/matt-hoiland/glox/internal/vm"
+)
+
+func TestVM_Run(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range loxtest.Cases() {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var bob strings.Builder
+			err := vm.New(&bob,
+				vm.WithCapabilities(native.AllCapabilities),
+				vm.WithHost(native.Host{FS: loxtest.NewMemFS(test.Files)}),
+			).Run(test.Source)
+
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				require.ErrorContains(t, err, test.Message)
+				if test.Diagnostic != "" {
+					assert.Equal(t, test.Diagnostic, ierrors.Renderer{Source: test.Source}.Render(err))
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Output, bob.String())
+		})
+	}
+}
+
+func TestVM_Run_globalsPersist(t *testing.T) {
+	t.Parallel()
+
+	var bob strings.Builder
+	machine := vm.New(&bob)
+	require.NoError(t, machine.Run(`var a = "kept";`))
+	require.Error(t, machine.Run(`print -a;`))
+	require.NoError(t, machine.Run(`print a;`))
+	assert.Equal(t, "kept\n", bob.String())
+}
+
+func TestVM_Run_runtimeErrorsAreRendered(t *testing.T) {
+	t.Parallel()
+
+	source := "fun greet() {\n\tprint greeting;\n}\ngreet();"
+	err := vm.New(io.Discard).Run(source)
+
+	renderer := ierrors.Renderer{Filename: "script.lox", Source: source}
+	assert.Equal(t,
+		"script.lox:2:8: error at 'greeting': undefined variable: greeting\n"+
+			" 2 | \tprint greeting;\n"+
+			"   | \t      ^~~~~~~~\n"+
+			"  at greet (script.lox:2)\n"+
+			"  at <script> (script.lox:4)",
+		renderer.Render(err))
+}
+
+func TestVM_Run_traceback(t *testing.T) {
+	t.Parallel()
+
+	source := loxtest.Dedent(`
+		fun inner(x) {
+			return -x;
+		}
+
+		fun outer(x) {
+			return inner(x) + 1;
+		}
+
+		class Widget {
+			init(x) {
+				this.value = outer(x);
+			}
+		}
+
+		Widget("oops");
+	`)
+	err := vm.New(io.Discard).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrNonNumericType)
+
+	var tb *ierrors.Traceback
+	require.ErrorAs(t, err, &tb)
+	assert.Equal(t, []ierrors.Frame{
+		{Function: "inner", Line: 2},
+		{Function: "outer", Line: 6},
+		{Function: "init", Line: 11},
+		{Function: "<script>", Line: 15},
+	}, tb.Frames)
+}
+
+func TestVM_Run_imports(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		Name          string
+		Files         map[string]string
+		Source        string
+		Output        string
+		ExpectedError error
+	}
+
+	tests := []Test{
+		{
+			Name: "namespace",
+			Files: map[string]string{
+				"lib/counter.lox": `
+					print "loading";
+					var count = 0;
+					fun bump() { count = count + 1; return count; }
+				`,
+			},
+			Source: `
+				import "lib/counter.lox" as counter;
+				import "lib/counter.lox" as again;
+				print counter.bump();
+				print again.count;
+				print counter == again;
+				print counter;
+			`,
+			Output: "loading\n1\n1\ntrue\n<module lib/counter.lox>\n",
+		},
+		{
+			Name: "own_globals",
+			Files: map[string]string{
+				"lib.lox": `var name = "lib"; fun get() { return name; }`,
+			},
+			Source: `
+				var name = "script";
+				import "lib.lox" as lib;
+				print lib.get();
+				print name;
+			`,
+			Output: "lib\nscript\n",
+		},
+		{
+			Name: "local_binding",
+			Files: map[string]string{
+				"lib.lox": `var name = "lib";`,
+			},
+			Source: `
+				fun get() {
+					import "lib.lox" as lib;
+					return lib.name;
+				}
+				print get();
+			`,
+			Output: "lib\n",
+		},
+		{
+			Name: "relative_to_importer",
+			Files: map[string]string{
+				"lib/a.lox": `import "b.lox" as b; var name = b.name;`,
+				"lib/b.lox": `var name = "b";`,
+			},
+			Source: `import "lib/a.lox" as a; print a.name;`,
+			Output: "b\n",
+		},
+		{
+			Name:   "search_path",
+			Files:  map[string]string{"search/shared.lox": `var name = "shared";`},
+			Source: `import "shared.lox" as shared; print shared.name;`,
+			Output: "shared\n",
+		},
+		{
+			Name:          "not_found",
+			Source:        `import "missing.lox" as missing;`,
+			ExpectedError: ierrors.ErrModuleNotFound,
+		},
+		{
+			Name: "cycle",
+			Files: map[string]string{
+				"a.lox": `import "b.lox" as b;`,
+				"b.lox": `import "a.lox" as a;`,
+			},
+			Source:        `import "a.lox" as a;`,
+			ExpectedError: ierrors.ErrImportCycle,
+		},
+		{
+			Name:          "import_script",
+			Files:         map[string]string{"main.lox": `import "main.lox" as main;`},
+			Source:        `import "main.lox" as main;`,
+			ExpectedError: ierrors.ErrImportCycle,
+		},
+		{
+			Name:          "undefined_binding",
+			Files:         map[string]string{"empty.lox": ``},
+			Source:        `import "empty.lox" as empty; empty.clock;`,
+			ExpectedError: ierrors.ErrUndefinedProperty,
+		},
+		{
+			Name:          "read_only_binding",
+			Files:         map[string]string{"util.lox": `var x = 1;`},
+			Source:        `import "util.lox" as util; util.x = 5;`,
+			ExpectedError: ierrors.ErrModuleReadOnly,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var bob strings.Builder
+			err := vm.New(&bob,
+				vm.WithCapabilities(native.CapabilityFS),
+				vm.WithHost(native.Host{FS: loxtest.NewMemFS(test.Files)}),
+				vm.WithScriptPath("main.lox"),
+				vm.WithSearchPath("search"),
+			).Run(test.Source)
+
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Output, bob.String())
+		})
+	}
+}
+
+func TestVM_Run_importDenied(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"lib.lox": ``})
+	err := vm.New(io.Discard, vm.WithHost(native.Host{FS: fsys})).Run(`import "lib.lox" as lib;`)
+	require.ErrorIs(t, err, ierrors.ErrImportDenied)
+}
+
+func TestVM_Run_importedErrorsAreRendered(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"lib/util.lox": "fun negate(x) {\n\treturn -x;\n}\n"})
+	source := "import \"lib/util.lox\" as util;\nutil.negate(\"one\");"
+	err := vm.New(io.Discard,
+		vm.WithCapabilities(native.CapabilityFS),
+		vm.WithHost(native.Host{FS: fsys}),
+		vm.WithScriptPath("main.lox"),
+	).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrNonNumericType)
+
+	renderer := ierrors.Renderer{Filename: "main.lox", Source: source}
+	assert.Equal(t, "lib/util.lox:2:9: error at '-': cannot apply minus operator: non-numeric type-error\n"+
+		" 2 | \treturn -x;\n"+
+		"   | \t       ^\n"+
+		"  at negate (lib/util.lox:2)\n"+
+		"  at <script> (main.lox:2)", renderer.Render(err))
+}
+
+func TestVM_Run_stackOverflow(t *testing.T) {
+	t.Parallel()
+
+	source := "fun f() { f(); }\nf();"
+	err := vm.New(io.Discard, vm.WithMaxCallDepth(10)).Run(source)
+	require.ErrorIs(t, err, ierrors.ErrStackOverflow)
+
+	renderer := ierrors.Renderer{Filename: "script.lox", Source: source}
+	assert.Equal(t,
+		"script.lox:1:13: error at ')': stack overflow\n"+
+			" 1 | fun f() { f(); }\n"+
+			"   |             ^\n"+
+			"  at f (script.lox:1)\n"+
+			"  ... repeated 9 more times\n"+
+			"  at <script> (script.lox:2)",
+		renderer.Render(err))
+}
+
+func TestVM_Run_traceExecution(t *testing.T) {
+	t.Parallel()
+
+	var output, trace strings.Builder
+	err := vm.New(&output, vm.WithTraceExecution(&trace)).Run(`print 1 + 2;`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "3\n", output.String())
+	assert.Equal(t, ""+
+		"          [ <script> ]\n"+
+		"0000    1 OpConstant          0 '1'\n"+
+		"          [ <script> ][ 1 ]\n"+
+		"0003    | OpConstant          1 '2'\n"+
+		"          [ <script> ][ 1 ][ 2 ]\n"+
+		"0006    | OpAdd\n"+
+		"          [ <script> ][ 3 ]\n"+
+		"0007    | OpPrint\n"+
+		"          [ <script> ]\n"+
+		"0008    | OpNil\n"+
+		"          [ <script> ][ nil ]\n"+
+		"0009    | OpReturn\n",
+		trace.String())
+}
+
+func TestVM_RunContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := vm.New(io.Discard).RunContext(ctx, `
+		fun spin() { while (true) {} }
+		try { spin(); } catch (e) { print "caught"; }
+	`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestVM_Run_limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name   string
+		Option vm.Option
+		Source string
+		Err    error
+	}{
+		{
+			Name:   "steps",
+			Option: vm.WithMaxSteps(100),
+			Source: `while (true) {}`,
+			Err:    ierrors.ErrStepLimit,
+		},
+		{
+			Name:   "steps within the limit",
+			Option: vm.WithMaxSteps(100),
+			Source: `for (var i = 0; i < 3; i = i + 1) {}`,
+		},
+		{
+			Name:   "call depth",
+			Option: vm.WithMaxCallDepth(10),
+			Source: `fun f(n) { if (n > 0) f(n - 1); } f(10);`,
+			Err:    ierrors.ErrStackOverflow,
+		},
+		{
+			Name:   "heap",
+			Option: vm.WithMaxHeap(1),
+			Source: `var l = []; while (true) { push(l, "more"); }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+		{
+			Name:   "heap between checks of the steps",
+			Option: vm.WithMaxHeap(64 << 20),
+			Source: `var s = "x"; for (var i = 0; i < 30; i = i + 1) { s = s + s; }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+		{
+			Name:   "heap grown by a native function",
+			Option: vm.WithMaxHeap(64 << 20),
+			Source: `var s = "x"; for (var i = 0; i < 30; i = i + 1) { s = replace(s, "x", "xx"); }`,
+			Err:    ierrors.ErrHeapLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+
+			err := vm.New(io.Discard, test.Option).Run(test.Source)
+			if test.Err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.Err)
+		})
+	}
+}
+
+func TestVM_Run_io(t *testing.T) {
+	t.Parallel()
+
+	fsys := loxtest.NewMemFS(map[string]string{"in.txt": "one\ntwo\n"})
+	var stdout, stderr strings.Builder
+	machine := vm.New(&stdout,
+		vm.WithCapabilities(native.CapabilityIO|native.CapabilityFS),
+		vm.WithHost(native.Host{FS: fsys, Stdin: strings.NewReader("typed\n"), Stderr: &stderr}),
+	)
+
+	err := machine.Run(`
+		print input("> ");
+		print readLine();
+		writeFile("out.txt", join(readLines("in.txt"), "+"));
+		printErr(readFile("out.txt"));
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, "> typed\nnil\n", stdout.String())
+	assert.Equal(t, "one+two\n", stderr.String())
+
+	err = machine.Run(`readFile("missing.txt");`)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
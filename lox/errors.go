@@ -0,0 +1,65 @@
+package lox
+
+import (
+	"github.com/matt-hoiland/glox/internal/environment"
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+type (
+	// Error is a syntax or runtime error at a position in the source.
+	Error = ierrors.Error
+	// ErrorList holds every syntax error found in the source, when there are any.
+	ErrorList = ierrors.List
+	// Traceback is a runtime error with the calls that were running when it happened.
+	Traceback = ierrors.Traceback
+	// Frame is one of the calls of a [Traceback].
+	Frame = ierrors.Frame
+	// Renderer renders errors as diagnostics that quote the source.
+	Renderer = ierrors.Renderer
+	// ThrownError is a value a script threw and never caught, or an error object it rethrew.
+	ThrownError = loxtype.Error
+	// ExitError is how a script stops when it calls exit.
+	ExitError = ierrors.ExitError
+)
+
+// Errors that scripts fail with, to test for with [errors.Is].
+var (
+	ErrType           = ierrors.ErrType
+	ErrNonBooleanType = ierrors.ErrNonBooleanType
+	ErrNonNumericType = ierrors.ErrNonNumericType
+	ErrNonStringType  = ierrors.ErrNonStringType
+	ErrNonListType    = ierrors.ErrNonListType
+	ErrNonMapType     = ierrors.ErrNonMapType
+
+	ErrNotCallable   = ierrors.ErrNotCallable
+	ErrArgumentCount = ierrors.ErrArgumentCount
+	ErrArgumentRange = ierrors.ErrArgumentRange
+
+	ErrNotAnInstance      = ierrors.ErrNotAnInstance
+	ErrUndefinedProperty  = ierrors.ErrUndefinedProperty
+	ErrSuperclassNotClass = ierrors.ErrSuperclassNotClass
+
+	ErrNotIndexable    = ierrors.ErrNotIndexable
+	ErrIndexNotInteger = ierrors.ErrIndexNotInteger
+	ErrIndexOutOfRange = ierrors.ErrIndexOutOfRange
+	ErrUnhashableKey   = ierrors.ErrUnhashableKey
+	ErrUndefinedKey    = ierrors.ErrUndefinedKey
+
+	ErrInvalidCodePoint = ierrors.ErrInvalidCodePoint
+	ErrInvalidNumber    = loxtype.ErrInvalidNumber
+
+	ErrThrown = ierrors.ErrThrown
+
+	ErrStackOverflow = ierrors.ErrStackOverflow
+	ErrStepLimit     = ierrors.ErrStepLimit
+	ErrHeapLimit     = ierrors.ErrHeapLimit
+
+	ErrExit = ierrors.ErrExit
+
+	ErrUndefinedVariable = environment.ErrUndefinedVariable
+	ErrModuleNotFound    = ierrors.ErrModuleNotFound
+	ErrImportCycle       = ierrors.ErrImportCycle
+	ErrModuleReadOnly    = ierrors.ErrModuleReadOnly
+	ErrImportDenied      = ierrors.ErrImportDenied
+)
@@ -0,0 +1,209 @@
+// Package lox embeds the lox interpreter in Go programs.
+//
+// A [Runtime] runs lox source code and keeps its global variables between runs, so the host program can define
+// values and native functions for scripts to use, and read or call what the scripts define in turn.
+package lox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/matt-hoiland/glox/internal/interpreter"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+	"github.com/matt-hoiland/glox/internal/native"
+)
+
+// Runtime runs lox programs on the tree-walking interpreter. It isn't safe for concurrent use.
+type Runtime struct {
+	interpreter *interpreter.Interpreter
+}
+
+type config struct {
+	output io.Writer
+	host   native.Host
+	opts   []interpreter.Option
+}
+
+// Option configures a [Runtime].
+type Option func(*config)
+
+// WithOutput sets where print statements write to. It's [os.Stdout] by default.
+func WithOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.output = w
+	}
+}
+
+// FS is the file system that scripts granted [CapabilityFS] read and write files and import modules through.
+type FS = native.FS
+
+// OSFS is the file system of the operating system.
+type OSFS = native.OSFS
+
+// WithFS sets the file system that scripts read and write files and import modules through. It's [OSFS] by default.
+func WithFS(fsys FS) Option {
+	return func(c *config) {
+		c.host.FS = fsys
+	}
+}
+
+// WithInput sets where scripts read lines from. It's [os.Stdin] by default.
+func WithInput(r io.Reader) Option {
+	return func(c *config) {
+		c.host.Stdin = r
+	}
+}
+
+// WithErrorOutput sets where printErr writes to. It's [os.Stderr] by default.
+func WithErrorOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.host.Stderr = w
+	}
+}
+
+// WithSearchPath sets the directories searched for imported modules
+// that aren't found relative to the working directory.
+func WithSearchPath(dirs ...string) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, interpreter.WithSearchPath(dirs...))
+	}
+}
+
+// Capability is a set of built-in functions that reach beyond scripts into the host program.
+type Capability = native.Capability
+
+// The capabilities that runtimes may grant scripts.
+const (
+	CapabilityTime    = native.CapabilityTime
+	CapabilityIO      = native.CapabilityIO
+	CapabilityFS      = native.CapabilityFS
+	CapabilityProcess = native.CapabilityProcess
+	CapabilityEnv     = native.CapabilityEnv
+	AllCapabilities   = native.AllCapabilities
+)
+
+// WithCapabilities grants scripts the built-in functions of the capabilities given. Without it, scripts can only
+// work on values and print, so untrusted ones can be run safely. Scripts that call exit stop with an [*ExitError].
+func WithCapabilities(granted Capability) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, interpreter.WithCapabilities(granted))
+	}
+}
+
+// WithMaxSteps stops scripts with [ErrStepLimit] once a single run has executed n statements and expressions.
+func WithMaxSteps(n int) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, interpreter.WithMaxSteps(n))
+	}
+}
+
+// WithMaxCallDepth stops scripts with [ErrStackOverflow] when they'd have more than n calls in progress.
+// Without it, scripts may have 256.
+func WithMaxCallDepth(n int) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, interpreter.WithMaxCallDepth(n))
+	}
+}
+
+// WithMaxHeap stops scripts with [ErrHeapLimit] once the Go heap holds more than n bytes.
+// The heap is shared with the host program, so this guards against runaway scripts rather than measuring one.
+func WithMaxHeap(n uint64) Option {
+	return func(c *config) {
+		c.opts = append(c.opts, interpreter.WithMaxHeap(n))
+	}
+}
+
+// New returns a runtime whose globals are only the built-in functions of the capabilities granted.
+func New(opts ...Option) *Runtime {
+	c := &config{output: os.Stdout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.host.Stdout = c.output
+	return &Runtime{
+		interpreter: interpreter.New(c.output, append(c.opts, interpreter.WithHost(c.host))...),
+	}
+}
+
+// Eval runs source in the global scope, as a line of the REPL is run. If the last statement is an expression,
+// Eval returns its value converted by [FromValue], and otherwise nil.
+//
+// Cancelling the context stops the script with the context's error at its next loop iteration or call.
+//
+// Errors are reported as [ErrorList], [*Error], or [*Traceback], with positions in source;
+// a [Renderer] given source can show them as diagnostics.
+func (r *Runtime) Eval(ctx context.Context, source string) (any, error) {
+	value, err := r.interpreter.EvalContext(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return FromValue(value), nil
+}
+
+// Call calls the function or class bound to a global variable, converting its arguments by [ToValue]
+// and its result by [FromValue].
+func (r *Runtime) Call(name string, args ...any) (any, error) {
+	return r.CallContext(context.Background(), name, args...)
+}
+
+// CallContext is [Runtime.Call], stopping the call with the context's error if the context is cancelled.
+func (r *Runtime) CallContext(ctx context.Context, name string, args ...any) (any, error) {
+	callee, err := r.interpreter.Global(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]loxtype.Type, len(args))
+	for k, arg := range args {
+		if values[k], err = ToValue(arg); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", k, err)
+		}
+	}
+	result, err := r.interpreter.Call(ctx, callee, values)
+	if err != nil {
+		return nil, err
+	}
+	return FromValue(result), nil
+}
+
+// SetGlobal defines a global variable, converting its value by [ToValue].
+func (r *Runtime) SetGlobal(name string, value any) error {
+	v, err := ToValue(value)
+	if err != nil {
+		return err
+	}
+	r.interpreter.Define(name, v)
+	return nil
+}
+
+// GetGlobal returns the value of a global variable converted by [FromValue],
+// or [ErrUndefinedVariable] if there's no such variable.
+func (r *Runtime) GetGlobal(name string) (any, error) {
+	value, err := r.interpreter.Global(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromValue(value), nil
+}
+
+// RegisterNative defines a global function implemented in Go. Scripts must call it with exactly arity arguments,
+// which fn receives converted by [FromValue]. Its result is converted by [ToValue], and an error it returns
+// fails the script at the call.
+func (r *Runtime) RegisterNative(name string, arity int, fn func(args []any) (any, error)) {
+	r.interpreter.Define(name, &native.Function{
+		Name:  name,
+		Arity: arity,
+		Impl: func(args []loxtype.Type) (loxtype.Type, error) {
+			values := make([]any, len(args))
+			for k, arg := range args {
+				values[k] = FromValue(arg)
+			}
+			result, err := fn(values)
+			if err != nil {
+				return nil, err
+			}
+			return ToValue(result)
+		},
+	})
+}
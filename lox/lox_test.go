@@ -0,0 +1,257 @@
+package lox_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/internal/loxtest"
+	"github.com/matt-hoiland/glox/lox"
+)
+
+func ExampleRuntime() {
+	r := lox.New()
+	r.RegisterNative("greeting", 1, func(args []any) (any, error) {
+		return fmt.Sprintf("hello, %s", args[0]), nil
+	})
+
+	if _, err := r.Eval(context.Background(), `fun shout(s) { return greeting(s) + "!"; }`); err != nil {
+		panic(err)
+	}
+	result, err := r.Call("shout", "world")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result)
+	// Output: hello, world!
+}
+
+func TestRuntime_Eval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name   string
+		Source string
+		Want   any
+		Output string
+		Err    error
+	}{
+		{Name: "expression", Source: "1 + 2", Want: 3.0},
+		{Name: "last expression", Source: "var a = 2; a * a", Want: 4.0},
+		{Name: "statement", Source: `print "hi";`, Output: "hi\n"},
+		{Name: "list", Source: `[1, "two", nil]`, Want: []any{1.0, "two", nil}},
+		{Name: "map", Source: `var m = {"a": true}; m`, Want: map[any]any{"a": true}},
+		{Name: "syntax error", Source: "1 +", Err: lox.ErrorList{}},
+		{Name: "runtime error", Source: `-"a"`, Err: lox.ErrNonNumericType},
+		{Name: "undefined variable", Source: "missing", Err: lox.ErrUndefinedVariable},
+		{Name: "thrown", Source: `throw "up";`, Err: lox.ErrThrown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			var output bytes.Buffer
+			r := lox.New(lox.WithOutput(&output))
+
+			got, err := r.Eval(context.Background(), test.Source)
+			switch {
+			case test.Err == nil:
+				require.NoError(t, err)
+			case errors.As(test.Err, new(lox.ErrorList)):
+				require.ErrorAs(t, err, new(lox.ErrorList))
+			default:
+				require.ErrorIs(t, err, test.Err)
+			}
+			assert.Equal(t, test.Want, got)
+			assert.Equal(t, test.Output, output.String())
+		})
+	}
+}
+
+func TestRuntime_Eval_keepsGlobals(t *testing.T) {
+	t.Parallel()
+	r := lox.New()
+	ctx := context.Background()
+
+	_, err := r.Eval(ctx, "var count = 1; fun bump() { count = count + 1; }")
+	require.NoError(t, err)
+	_, err = r.Eval(ctx, "bump();")
+	require.NoError(t, err)
+
+	got, err := r.GetGlobal("count")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, got)
+}
+
+func TestRuntime_Eval_cancelled(t *testing.T) {
+	t.Parallel()
+	var output bytes.Buffer
+	r := lox.New(lox.WithOutput(&output))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.Eval(ctx, `print "unreachable";`)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, output.String())
+}
+
+func TestRuntime_Eval_limits(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := lox.New().Eval(ctx, "while (true) {}")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	r := lox.New(lox.WithMaxSteps(1000), lox.WithMaxCallDepth(10))
+	_, err = r.Eval(context.Background(), "while (true) {}")
+	require.ErrorIs(t, err, lox.ErrStepLimit)
+	_, err = r.Eval(context.Background(), "fun f() { f(); }")
+	require.NoError(t, err, "each run gets the whole budget")
+	_, err = r.Call("f")
+	require.ErrorIs(t, err, lox.ErrStackOverflow)
+}
+
+func TestRuntime_capabilities(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	_, err := lox.New().Eval(ctx, "exit();")
+	require.ErrorIs(t, err, lox.ErrUndefinedVariable, "scripts are sandboxed by default")
+
+	_, err = lox.New(lox.WithCapabilities(lox.CapabilityProcess)).Eval(ctx, "exit();")
+	var exitErr *lox.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 0, exitErr.Code)
+
+	_, err = lox.New(lox.WithCapabilities(lox.CapabilityProcess)).Eval(ctx, "exit(3);")
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Code)
+
+	fsys := loxtest.NewMemFS(map[string]string{"util.lox": `var name = "util";`})
+	_, err = lox.New(lox.WithFS(fsys)).Eval(ctx, `import "util.lox" as util;`)
+	require.ErrorIs(t, err, lox.ErrImportDenied, "imports read files too")
+
+	got, err := lox.New(lox.WithCapabilities(lox.CapabilityFS), lox.WithFS(fsys)).
+		Eval(ctx, `import "util.lox" as util; util.name`)
+	require.NoError(t, err)
+	assert.Equal(t, "util", got)
+}
+
+func TestRuntime_io(t *testing.T) {
+	t.Parallel()
+	fsys := loxtest.NewMemFS(map[string]string{"greeting.txt": "hello"})
+	var stderr bytes.Buffer
+	r := lox.New(
+		lox.WithCapabilities(lox.CapabilityIO|lox.CapabilityFS),
+		lox.WithFS(fsys),
+		lox.WithInput(strings.NewReader("world\n")),
+		lox.WithErrorOutput(&stderr),
+	)
+
+	got, err := r.Eval(context.Background(), `printErr("reading"); readFile("greeting.txt") + ", " + readLine()`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", got)
+	assert.Equal(t, "reading\n", stderr.String())
+}
+
+func TestRuntime_Globals(t *testing.T) {
+	t.Parallel()
+	r := lox.New()
+
+	require.NoError(t, r.SetGlobal("config", map[string]any{"name": "glox", "sizes": []int{1, 2}}))
+	got, err := r.Eval(context.Background(), `config["sizes"][1]`)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, got)
+
+	got, err = r.GetGlobal("config")
+	require.NoError(t, err)
+	assert.Equal(t, map[any]any{"name": "glox", "sizes": []any{1.0, 2.0}}, got)
+
+	_, err = r.GetGlobal("missing")
+	require.ErrorIs(t, err, lox.ErrUndefinedVariable)
+
+	require.ErrorIs(t, r.SetGlobal("channel", make(chan int)), lox.ErrUnsupportedType)
+}
+
+func TestRuntime_Call(t *testing.T) {
+	t.Parallel()
+	r := lox.New()
+	_, err := r.Eval(context.Background(), `
+class Point {
+  init(x, y) { this.x = x; this.y = y; }
+}
+fun add(a, b) { return a + b; }
+fun fail() { return -"a"; }
+var notAFunction = 1;
+`)
+	require.NoError(t, err)
+
+	got, err := r.Call("add", 1, 2.5)
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, got)
+
+	got, err = r.Call("Point", 1, 2)
+	require.NoError(t, err)
+	require.NoError(t, r.SetGlobal("p", got), "instances pass back into the runtime unchanged")
+	x, err := r.Eval(context.Background(), "p.x + p.y")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, x)
+
+	_, err = r.Call("add", 1)
+	require.ErrorIs(t, err, lox.ErrArgumentCount)
+
+	_, err = r.Call("notAFunction")
+	require.ErrorIs(t, err, lox.ErrNotCallable)
+
+	_, err = r.Call("missing")
+	require.ErrorIs(t, err, lox.ErrUndefinedVariable)
+
+	_, err = r.Call("fail")
+	require.ErrorIs(t, err, lox.ErrNonNumericType)
+	var tb *lox.Traceback
+	require.ErrorAs(t, err, &tb)
+	assert.Len(t, tb.Frames, 1, "the traceback stops at the host's call")
+}
+
+func TestRuntime_RegisterNative(t *testing.T) {
+	t.Parallel()
+	errBoom := errors.New("boom")
+	r := lox.New()
+	r.RegisterNative("sum", 1, func(args []any) (any, error) {
+		total := 0.0
+		for _, n := range args[0].([]any) {
+			total += n.(float64)
+		}
+		return total, nil
+	})
+	r.RegisterNative("boom", 0, func([]any) (any, error) {
+		return nil, errBoom
+	})
+	ctx := context.Background()
+
+	got, err := r.Eval(ctx, "sum([1, 2, 3])")
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, got)
+
+	got, err = r.Call("sum", []float64{4, 5})
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, got)
+
+	_, err = r.Eval(ctx, "sum(1, 2)")
+	require.ErrorIs(t, err, lox.ErrArgumentCount)
+
+	_, err = r.Eval(ctx, "boom()")
+	require.ErrorIs(t, err, errBoom)
+
+	got, err = r.Eval(ctx, `try { boom(); } catch (e) {} "caught"`)
+	require.NoError(t, err)
+	assert.Equal(t, "caught", got)
+}
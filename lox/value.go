@@ -0,0 +1,157 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	ierrors "github.com/matt-hoiland/glox/internal/errors"
+	"github.com/matt-hoiland/glox/internal/loxtype"
+)
+
+// ErrUnsupportedType is returned when a Go value has no lox equivalent.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// Value is a lox value. Values that have no Go equivalent, such as functions and instances,
+// pass through the conversions unchanged, so they can be handed back to the runtime.
+type Value = loxtype.Type
+
+// The lox types that Go values convert to.
+type (
+	Nil     = loxtype.Nil
+	Boolean = loxtype.Boolean
+	Number  = loxtype.Number
+	String  = loxtype.String
+	List    = loxtype.List
+	Map     = loxtype.Map
+)
+
+// NewMap returns an empty map.
+func NewMap() *Map {
+	return loxtype.NewMap()
+}
+
+// ToValue converts a Go value to a lox value. Booleans, numbers, and strings convert to their lox types,
+// slices and arrays to lists, maps to maps, and nil to nil. Values are returned as they are.
+func ToValue(v any) (Value, error) {
+	if v == nil {
+		return loxtype.Nil{}, nil
+	}
+	if value, ok := v.(Value); ok {
+		return value, nil
+	}
+	return toValue(reflect.ValueOf(v))
+}
+
+func toValue(rv reflect.Value) (Value, error) {
+	switch rv.Kind() {
+	case reflect.Bool:
+		return loxtype.Boolean(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return loxtype.Number(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return loxtype.Number(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return loxtype.Number(rv.Float()), nil
+	case reflect.String:
+		return loxtype.String(rv.String()), nil
+
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return loxtype.Nil{}, nil
+		}
+		if rv.Kind() == reflect.Interface {
+			return ToValue(rv.Interface())
+		}
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return loxtype.Nil{}, nil
+		}
+		list := &loxtype.List{Elements: make([]loxtype.Type, rv.Len())}
+		for k := range rv.Len() {
+			element, err := toValue(rv.Index(k))
+			if err != nil {
+				return nil, err
+			}
+			list.Elements[k] = element
+		}
+		return list, nil
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return loxtype.Nil{}, nil
+		}
+		m := loxtype.NewMap()
+		for iter := rv.MapRange(); iter.Next(); {
+			key, err := toValue(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			if !loxtype.Hashable(key) {
+				return nil, fmt.Errorf("%w: %s", ierrors.ErrUnhashableKey, key)
+			}
+			value, err := toValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m.Set(key, value)
+		}
+		return m, nil
+
+	default:
+	}
+
+	if rv.CanInterface() {
+		if value, ok := rv.Interface().(Value); ok {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, rv.Type())
+}
+
+// FromValue converts a lox value to a Go value: nil to nil, booleans to bool, numbers to float64,
+// strings to string, lists to []any, and maps to map[any]any. Other values are returned as they are.
+func FromValue(v Value) any {
+	return fromValue(v, map[Value]any{})
+}
+
+// fromValue converts v, using the lists and maps already converted in seen for those that contain themselves.
+func fromValue(v Value, seen map[Value]any) any {
+	switch value := v.(type) {
+	case nil, loxtype.Nil:
+		return nil
+	case loxtype.Boolean:
+		return bool(value)
+	case loxtype.Number:
+		return float64(value)
+	case loxtype.String:
+		return string(value)
+
+	case *loxtype.List:
+		if converted, ok := seen[value]; ok {
+			return converted
+		}
+		elements := make([]any, len(value.Elements))
+		seen[value] = elements
+		for k, element := range value.Elements {
+			elements[k] = fromValue(element, seen)
+		}
+		return elements
+
+	case *loxtype.Map:
+		if converted, ok := seen[value]; ok {
+			return converted
+		}
+		m := make(map[any]any, value.Len())
+		seen[value] = m
+		for _, key := range value.Keys() {
+			element, _ := value.Get(key)
+			m[fromValue(key, seen)] = fromValue(element, seen)
+		}
+		return m
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,94 @@
+package lox_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matt-hoiland/glox/lox"
+)
+
+func TestToValue(t *testing.T) {
+	t.Parallel()
+
+	list := &lox.List{Elements: []lox.Value{lox.Number(1)}}
+	tests := []struct {
+		Name  string
+		Value any
+		Want  string
+		Err   error
+	}{
+		{Name: "nil", Value: nil, Want: "nil"},
+		{Name: "bool", Value: true, Want: "true"},
+		{Name: "int", Value: 42, Want: "42"},
+		{Name: "uint8", Value: uint8(7), Want: "7"},
+		{Name: "float", Value: 1.5, Want: "1.5"},
+		{Name: "string", Value: "hi", Want: "hi"},
+		{Name: "value", Value: list, Want: "[1]"},
+		{Name: "slice", Value: []any{1, "a", nil, []string{"b"}}, Want: "[1, a, nil, [b]]"},
+		{Name: "array", Value: [2]bool{true, false}, Want: "[true, false]"},
+		{Name: "nil slice", Value: []int(nil), Want: "nil"},
+		{Name: "map", Value: map[string]int{"a": 1}, Want: "{a: 1}"},
+		{Name: "nested value", Value: []*lox.List{list}, Want: "[[1]]"},
+		{Name: "unhashable key", Value: map[any]int{[1]int{1}: 1}, Err: lox.ErrUnhashableKey},
+		{Name: "unsupported", Value: struct{}{}, Err: lox.ErrUnsupportedType},
+		{Name: "unsupported element", Value: []func(){nil}, Err: lox.ErrUnsupportedType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			got, err := lox.ToValue(test.Value)
+			if test.Err != nil {
+				require.ErrorIs(t, err, test.Err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Want, got.String())
+		})
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	t.Parallel()
+
+	m := lox.NewMap()
+	m.Set(lox.String("b"), lox.Number(2))
+	m.Set(lox.Nil{}, lox.Boolean(true))
+
+	tests := []struct {
+		Name  string
+		Value lox.Value
+		Want  any
+	}{
+		{Name: "nil", Value: lox.Nil{}, Want: nil},
+		{Name: "boolean", Value: lox.Boolean(true), Want: true},
+		{Name: "number", Value: lox.Number(2.5), Want: 2.5},
+		{Name: "string", Value: lox.String("s"), Want: "s"},
+		{Name: "list", Value: &lox.List{Elements: []lox.Value{lox.Number(1), m}}, Want: []any{
+			1.0, map[any]any{"b": 2.0, nil: true},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.Want, lox.FromValue(test.Value))
+		})
+	}
+}
+
+func TestFromValue_cycle(t *testing.T) {
+	t.Parallel()
+	list := &lox.List{}
+	list.Elements = []lox.Value{list}
+
+	got, ok := lox.FromValue(list).([]any)
+	require.True(t, ok)
+	require.Len(t, got, 1)
+	inner, ok := got[0].([]any)
+	require.True(t, ok)
+	require.Len(t, inner, 1)
+	assert.Same(t, &got[0], &inner[0], "the list converts to a slice containing itself")
+}
@@ -20,29 +20,44 @@ func main() {
 		os.Exit(exit.Usage)
 	}
 	outputDir := os.Args[1]
-	defineAST(outputDir, "Expr",
+	defineAST(outputDir, "Expr", "loxtype.Type",
 		"Assign   : Name *token.Token, Value Expr",
 		"Binary   : Left Expr, Operator *token.Token, Right Expr",
-		"Call     : Callee Expr, paren *token.Token, Arguments []Expr",
+		"Call     : Callee Expr, Paren *token.Token, Arguments []Expr",
+		"Get      : Object Expr, Name *token.Token",
 		"Grouping : Expression Expr",
-		"Literal  : Value loxtype.Type",
+		"Index    : Object Expr, Bracket *token.Token, Index Expr",
+		"List     : Bracket *token.Token, Elements []Expr",
+		"Literal  : Value loxtype.Type, Token *token.Token",
 		"Logical  : Left Expr, Operator *token.Token, Right Expr",
+		"Map      : Brace *token.Token, Keys []Expr, Values []Expr",
+		"Set      : Object Expr, Name *token.Token, Value Expr",
+		"SetIndex : Object Expr, Bracket *token.Token, Index Expr, Value Expr",
+		"Super    : Keyword *token.Token, Method *token.Token",
+		"This     : Keyword *token.Token",
 		"Unary    : Operator *token.Token, Right Expr",
 		"Variable : Name *token.Token",
 	)
-	defineAST(outputDir, "Stmt",
+	defineAST(outputDir, "Stmt", "Completion",
 		"Block      : Statements []Stmt",
+		"Break      : Keyword *token.Token",
+		"Class      : Name *token.Token, Superclass *VariableExpr, Methods []*FunctionStmt",
+		"Continue   : Keyword *token.Token",
 		"Expression : Expression Expr",
 		"Function   : Name *token.Token, Params []*token.Token, Body []Stmt",
 		"If         : Condition Expr, ThenBranch Stmt, ElseBranch Stmt",
+		"Import     : Keyword *token.Token, Path *token.Token, Name *token.Token",
 		"Print      : Expression Expr",
 		"Return     : Keyword *token.Token, Value Expr",
+		"Throw      : Keyword *token.Token, Value Expr",
+		"Try        : Keyword *token.Token, Body []Stmt, CatchName *token.Token, CatchBody []Stmt, FinallyBody []Stmt",
 		"Var        : Name *token.Token, Initializer Expr",
-		"While      : Condition Expr, Body Stmt",
+		"While      : Condition Expr, Body Stmt, Increment Expr",
 	)
 }
 
-func defineAST(outputDir, baseName string, productions ...string) {
+// defineAST writes the node types of baseName, whose visitors return result along with an error.
+func defineAST(outputDir, baseName, result string, productions ...string) {
 	w := &bytes.Buffer{}
 
 	fmt.Fprintf(w, "// Code generated by tools/generate-ast. DO NOT EDIT.\n")
@@ -50,20 +65,22 @@ func defineAST(outputDir, baseName string, productions ...string) {
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "import (")
 	fmt.Fprintln(w, `	"github.com/matt-hoiland/glox/internal/environment"`)
-	fmt.Fprintln(w, `	"github.com/matt-hoiland/glox/internal/loxtype"`)
+	if strings.Contains(result+strings.Join(productions, ""), "loxtype.") {
+		fmt.Fprintln(w, `	"github.com/matt-hoiland/glox/internal/loxtype"`)
+	}
 	fmt.Fprintln(w, `	"github.com/matt-hoiland/glox/internal/token"`)
 	fmt.Fprintln(w, ")")
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "type "+baseName+" interface {")
-	fmt.Fprintln(w, "\tAccept(*environment.Environment, "+baseName+"Visitor) (loxtype.Type, error)")
+	fmt.Fprintln(w, "\tAccept(*environment.Environment, "+baseName+"Visitor) ("+result+", error)")
 	fmt.Fprintln(w, "}")
 	fmt.Fprintln(w)
-	defineVisitor(w, baseName, productions...)
+	defineVisitor(w, baseName, result, productions...)
 	fmt.Fprintln(w)
 	for _, production := range productions {
 		typeName := strings.TrimSpace(strings.Split(production, ":")[0])
 		fields := strings.TrimSpace(strings.Split(production, ":")[1])
-		defineType(w, baseName, typeName, fields)
+		defineType(w, baseName, result, typeName, fields)
 	}
 
 	data, err := format.Source(w.Bytes())
@@ -80,7 +97,7 @@ func defineAST(outputDir, baseName string, productions ...string) {
 	}
 }
 
-func defineType(w io.Writer, baseName, typeName, fieldList string) {
+func defineType(w io.Writer, baseName, result, typeName, fieldList string) {
 	fmt.Fprintf(w, "type %s%s struct {\n", typeName, baseName)
 	for field := range strings.SplitSeq(fieldList, ",") {
 		fmt.Fprintf(w, "\t%s\n", strings.TrimSpace(field))
@@ -99,20 +116,20 @@ func defineType(w io.Writer, baseName, typeName, fieldList string) {
 	fmt.Fprintln(w, "}")
 	fmt.Fprintln(w)
 	fmt.Fprintf(w,
-		"func (e *%s%s) Accept(env *environment.Environment, visitor %sVisitor) (loxtype.Type, error) {\n",
-		typeName, baseName, baseName)
+		"func (e *%s%s) Accept(env *environment.Environment, visitor %sVisitor) (%s, error) {\n",
+		typeName, baseName, baseName, result)
 	fmt.Fprintf(w, "\treturn visitor.Visit%s%s(env, e)\n", typeName, baseName)
 	fmt.Fprintln(w, "}")
 	fmt.Fprintln(w)
 }
 
-func defineVisitor(w io.Writer, baseName string, productions ...string) {
+func defineVisitor(w io.Writer, baseName, result string, productions ...string) {
 	fmt.Fprintf(w, "type %sVisitor interface {\n", baseName)
 	for _, production := range productions {
 		typeName := strings.TrimSpace(strings.Split(production, ":")[0])
 		fmt.Fprintf(w,
-			"\tVisit%s%s(*environment.Environment, *%s%s) (loxtype.Type, error)\n",
-			typeName, baseName, typeName, baseName)
+			"\tVisit%s%s(*environment.Environment, *%s%s) (%s, error)\n",
+			typeName, baseName, typeName, baseName, result)
 	}
 	fmt.Fprintln(w, `}`)
 }